@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	demoEmail    = "demo@gopulse.local"
+	demoPassword = "demopass"
+)
+
+var demoSiteURLs = []string{
+	"https://example.com",
+	"https://go.dev",
+	"https://httpbin.org/status/500",
+}
+
+// seedDemoData membuat user demo beserta beberapa site agar dashboard langsung terisi.
+func seedDemoData(ctx context.Context, store db.Store) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(demoPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username:     "demo",
+		Email:        demoEmail,
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		return err
+	}
+	// User demo langsung terverifikasi agar tidak terkena batas akun yang belum terverifikasi
+	if _, err := store.VerifyUserEmail(ctx, user.ID, user.Email); err != nil {
+		return err
+	}
+
+	org, err := store.CreateOrganization(ctx, user.Username, user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range demoSiteURLs {
+		if _, err := store.CreateSite(ctx, db.CreateSiteParams{OrganizationID: org.ID, UserID: user.ID, URL: url}); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Demo mode: log in with %s / %s", demoEmail, demoPassword)
+	return nil
+}
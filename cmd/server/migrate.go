@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | status"
+
+// runMigrate menjalankan subcommand migrate terhadap database dari DB_SOURCE.
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	store, closeStore, err := openStore(ctx, dbSourceFromEnv())
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	migrator, err := storeMigrator(store)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migration(s)", count)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		count, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Printf("Reverted %d migration(s)", count)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+
+	default:
+		return errors.New(migrateUsage)
+	}
+	return nil
+}
+
+// migrateUp menerapkan semua migrasi yang belum dijalankan saat server start.
+func migrateUp(ctx context.Context, store db.Store) error {
+	migrator, err := storeMigrator(store)
+	if err != nil {
+		return err
+	}
+	count, err := migrator.Up(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("Database schema is up to date (%d migration(s) applied)", count)
+	return nil
+}
+
+func storeMigrator(store db.Store) (*db.Migrator, error) {
+	migratable, ok := store.(db.Migratable)
+	if !ok {
+		return nil, errors.New("this database backend does not support migrations")
+	}
+	return migratable.Migrator()
+}
@@ -0,0 +1,16 @@
+// Package migration menyimpan file migrasi SQL agar dapat di-embed ke dalam binary.
+//
+// Setiap migrasi terdiri dari pasangan file <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql.
+package migration
+
+import "embed"
+
+// Postgres berisi migrasi untuk backend PostgreSQL.
+//
+//go:embed postgres/*.sql
+var Postgres embed.FS
+
+// SQLite berisi migrasi untuk backend SQLite.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "health_checks";
+DROP TABLE IF EXISTS "sites";
+DROP TABLE IF EXISTS "users";
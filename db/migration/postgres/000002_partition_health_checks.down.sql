@@ -0,0 +1,25 @@
+-- Mengembalikan health_checks menjadi tabel biasa (tanpa partisi) beserta seluruh datanya.
+ALTER TABLE "health_checks" RENAME TO "health_checks_partitioned";
+ALTER TABLE "health_checks_partitioned" ALTER COLUMN "id" DROP DEFAULT;
+ALTER SEQUENCE "health_checks_id_seq" OWNED BY NONE;
+
+CREATE TABLE "health_checks" (
+  "id" bigint PRIMARY KEY DEFAULT nextval('health_checks_id_seq'),
+  "site_id" bigint NOT NULL,
+  "status_code" int,
+  "response_time_ms" int,
+  "is_up" boolean NOT NULL,
+  "checked_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER SEQUENCE "health_checks_id_seq" OWNED BY "health_checks"."id";
+
+INSERT INTO "health_checks" ("id", "site_id", "status_code", "response_time_ms", "is_up", "checked_at")
+SELECT "id", "site_id", "status_code", "response_time_ms", "is_up", "checked_at" FROM "health_checks_partitioned";
+
+-- Menghapus tabel induk sekaligus menghapus semua partisinya
+DROP TABLE "health_checks_partitioned";
+
+ALTER TABLE "health_checks" ADD FOREIGN KEY ("site_id") REFERENCES "sites" ("id");
+
+CREATE INDEX ON "health_checks" ("site_id");
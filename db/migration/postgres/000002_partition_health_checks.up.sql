@@ -0,0 +1,43 @@
+-- Mengubah health_checks menjadi tabel yang dipartisi per bulan berdasarkan checked_at.
+-- Data lama dipindahkan ke partisi yang sesuai, lalu tabel lama dihapus.
+ALTER TABLE "health_checks" RENAME TO "health_checks_legacy";
+ALTER TABLE "health_checks_legacy" ALTER COLUMN "id" DROP DEFAULT;
+ALTER SEQUENCE "health_checks_id_seq" OWNED BY NONE;
+
+CREATE TABLE "health_checks" (
+  "id" bigint NOT NULL DEFAULT nextval('health_checks_id_seq'),
+  "site_id" bigint NOT NULL,
+  "status_code" int,
+  "response_time_ms" int,
+  "is_up" boolean NOT NULL,
+  "checked_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("id", "checked_at")
+) PARTITION BY RANGE ("checked_at");
+
+ALTER SEQUENCE "health_checks_id_seq" OWNED BY "health_checks"."id";
+
+-- Buat partisi bulanan (UTC) dari data tertua sampai 3 bulan ke depan.
+DO $$
+DECLARE
+  m timestamptz := date_trunc('month', COALESCE((SELECT min("checked_at") FROM "health_checks_legacy"), now()) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+  last_month timestamptz := (date_trunc('month', now() AT TIME ZONE 'UTC') + interval '3 months') AT TIME ZONE 'UTC';
+BEGIN
+  WHILE m <= last_month LOOP
+    EXECUTE format(
+      'CREATE TABLE IF NOT EXISTS %I PARTITION OF "health_checks" FOR VALUES FROM (%L) TO (%L)',
+      'health_checks_y' || to_char(m AT TIME ZONE 'UTC', 'YYYY') || 'm' || to_char(m AT TIME ZONE 'UTC', 'MM'),
+      m,
+      ((m AT TIME ZONE 'UTC') + interval '1 month') AT TIME ZONE 'UTC'
+    );
+    m := ((m AT TIME ZONE 'UTC') + interval '1 month') AT TIME ZONE 'UTC';
+  END LOOP;
+END $$;
+
+INSERT INTO "health_checks" ("id", "site_id", "status_code", "response_time_ms", "is_up", "checked_at")
+SELECT "id", "site_id", "status_code", "response_time_ms", "is_up", "checked_at" FROM "health_checks_legacy";
+
+DROP TABLE "health_checks_legacy";
+
+ALTER TABLE "health_checks" ADD FOREIGN KEY ("site_id") REFERENCES "sites" ("id");
+
+CREATE INDEX ON "health_checks" ("site_id", "checked_at");
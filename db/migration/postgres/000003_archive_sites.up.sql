@@ -0,0 +1,3 @@
+ALTER TABLE "sites" ADD COLUMN "archived_at" timestamptz;
+
+CREATE INDEX ON "sites" ("archived_at") WHERE "archived_at" IS NOT NULL;
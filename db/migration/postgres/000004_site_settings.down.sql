@@ -0,0 +1,6 @@
+ALTER TABLE "sites"
+  DROP COLUMN "name",
+  DROP COLUMN "description",
+  DROP COLUMN "check_interval_seconds",
+  DROP COLUMN "timeout_seconds",
+  DROP COLUMN "paused_at";
@@ -0,0 +1,6 @@
+ALTER TABLE "sites"
+  ADD COLUMN "name" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "description" varchar NOT NULL DEFAULT '',
+  ADD COLUMN "check_interval_seconds" int NOT NULL DEFAULT 60,
+  ADD COLUMN "timeout_seconds" int NOT NULL DEFAULT 10,
+  ADD COLUMN "paused_at" timestamptz;
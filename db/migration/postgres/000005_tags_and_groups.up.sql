@@ -0,0 +1,24 @@
+CREATE TABLE "site_tags" (
+  "site_id" bigint NOT NULL REFERENCES "sites" ("id") ON DELETE CASCADE,
+  "tag" varchar NOT NULL,
+  PRIMARY KEY ("site_id", "tag")
+);
+
+CREATE INDEX ON "site_tags" ("tag");
+
+CREATE TABLE "site_groups" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "site_groups" ("user_id");
+
+CREATE TABLE "site_group_members" (
+  "group_id" bigint NOT NULL REFERENCES "site_groups" ("id") ON DELETE CASCADE,
+  "site_id" bigint NOT NULL REFERENCES "sites" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("group_id", "site_id")
+);
+
+CREATE INDEX ON "site_group_members" ("site_id");
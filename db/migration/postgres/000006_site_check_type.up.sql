@@ -0,0 +1,3 @@
+ALTER TABLE "sites" ADD COLUMN "check_type" varchar NOT NULL DEFAULT 'HTTP';
+
+CREATE INDEX ON "sites" ("user_id", "check_type");
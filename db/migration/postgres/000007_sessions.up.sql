@@ -0,0 +1,23 @@
+CREATE TABLE "sessions" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- SHA-256 dari refresh token yang berlaku saat ini
+  "refresh_token_hash" varchar UNIQUE NOT NULL,
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "ip_address" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "last_used_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "revoked_at" timestamptz
+);
+
+CREATE INDEX ON "sessions" ("user_id");
+
+-- Refresh token yang sudah dirotasi; jika dipakai lagi, session pemiliknya dicabut
+CREATE TABLE "used_refresh_tokens" (
+  "token_hash" varchar PRIMARY KEY,
+  "session_id" bigint NOT NULL REFERENCES "sessions" ("id") ON DELETE CASCADE,
+  "used_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "used_refresh_tokens" ("session_id");
@@ -0,0 +1,16 @@
+CREATE TABLE "api_keys" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "name" varchar NOT NULL,
+  -- Awal key yang ditampilkan di daftar agar user dapat mengenali key tanpa menyimpan key utuh
+  "prefix" varchar NOT NULL,
+  -- SHA-256 dari key; key utuh hanya ditampilkan sekali saat dibuat
+  "key_hash" varchar UNIQUE NOT NULL,
+  "scopes" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz,
+  "last_used_at" timestamptz,
+  "revoked_at" timestamptz
+);
+
+CREATE INDEX ON "api_keys" ("user_id");
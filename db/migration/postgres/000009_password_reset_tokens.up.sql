@@ -0,0 +1,11 @@
+CREATE TABLE "password_reset_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- SHA-256 dari token yang dikirim lewat email
+  "token_hash" varchar UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz
+);
+
+CREATE INDEX ON "password_reset_tokens" ("user_id");
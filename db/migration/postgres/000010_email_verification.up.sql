@@ -0,0 +1,4 @@
+ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz;
+
+-- Akun yang sudah ada sebelum verifikasi email diperkenalkan dianggap sudah terverifikasi
+UPDATE "users" SET "email_verified_at" = "created_at";
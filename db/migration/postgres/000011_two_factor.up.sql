@@ -0,0 +1,19 @@
+CREATE TABLE "user_totp" (
+  "user_id" bigint PRIMARY KEY REFERENCES "users" ("id") ON DELETE CASCADE,
+  "secret" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  -- confirmed_at kosong selama enrolment belum dikonfirmasi dengan kode pertama
+  "confirmed_at" timestamptz,
+  -- Periode TOTP terakhir yang dipakai; kode dari periode yang sama atau lebih lama ditolak
+  "last_used_step" bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- SHA-256 dari kode yang sudah dinormalisasi
+  "code_hash" varchar NOT NULL,
+  "used_at" timestamptz
+);
+
+CREATE INDEX ON "recovery_codes" ("user_id");
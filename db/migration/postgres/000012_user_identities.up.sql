@@ -0,0 +1,12 @@
+-- Akun di identity provider OIDC yang ditautkan ke user Go-Pulse
+CREATE TABLE "user_identities" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "issuer" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "email" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("issuer", "subject")
+);
+
+CREATE INDEX ON "user_identities" ("user_id");
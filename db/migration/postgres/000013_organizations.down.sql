@@ -0,0 +1,6 @@
+ALTER TABLE "site_groups" DROP COLUMN "organization_id";
+ALTER TABLE "sites" DROP COLUMN "organization_id";
+DROP TABLE IF EXISTS "team_members";
+DROP TABLE IF EXISTS "teams";
+DROP TABLE IF EXISTS "organization_members";
+DROP TABLE IF EXISTS "organizations";
@@ -0,0 +1,47 @@
+CREATE TABLE "organizations" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "organization_members" (
+  "organization_id" bigint NOT NULL REFERENCES "organizations" ("id") ON DELETE CASCADE,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- owner, admin, editor atau viewer
+  "role" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("organization_id", "user_id")
+);
+
+CREATE INDEX ON "organization_members" ("user_id");
+
+CREATE TABLE "teams" (
+  "id" bigserial PRIMARY KEY,
+  "organization_id" bigint NOT NULL REFERENCES "organizations" ("id") ON DELETE CASCADE,
+  "name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("organization_id", "name")
+);
+
+CREATE TABLE "team_members" (
+  "team_id" bigint NOT NULL REFERENCES "teams" ("id") ON DELETE CASCADE,
+  "user_id" bigint NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("team_id", "user_id")
+);
+
+-- Setiap user yang sudah ada mendapat organisasi pribadi dengan id yang sama dengan id user-nya
+INSERT INTO "organizations" ("id", "name", "created_at") SELECT "id", "username", "created_at" FROM "users";
+SELECT setval(pg_get_serial_sequence('organizations', 'id'), COALESCE((SELECT max("id") FROM "organizations"), 0) + 1, false);
+INSERT INTO "organization_members" ("organization_id", "user_id", "role", "created_at")
+  SELECT "id", "id", 'owner', "created_at" FROM "users";
+
+-- user_id pada sites dan site_groups tetap disimpan sebagai pembuatnya
+ALTER TABLE "sites" ADD COLUMN "organization_id" bigint REFERENCES "organizations" ("id");
+UPDATE "sites" SET "organization_id" = "user_id";
+ALTER TABLE "sites" ALTER COLUMN "organization_id" SET NOT NULL;
+CREATE INDEX ON "sites" ("organization_id");
+
+ALTER TABLE "site_groups" ADD COLUMN "organization_id" bigint REFERENCES "organizations" ("id");
+UPDATE "site_groups" SET "organization_id" = "user_id";
+ALTER TABLE "site_groups" ALTER COLUMN "organization_id" SET NOT NULL;
+CREATE INDEX ON "site_groups" ("organization_id");
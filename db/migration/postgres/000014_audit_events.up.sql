@@ -0,0 +1,23 @@
+-- Tidak ada foreign key agar event tetap tersimpan setelah user, organisasi atau target-nya dihapus
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  -- Organisasi pemilik target; NULL untuk event akun (login, session, API key, dll.)
+  "organization_id" bigint,
+  -- Akun yang terkena event akun
+  "user_id" bigint,
+  -- User yang melakukan aksi; NULL jika tidak diketahui, misalnya login gagal dengan email yang tidak terdaftar
+  "actor_id" bigint,
+  "api_key_id" bigint,
+  "action" varchar NOT NULL,
+  "target_type" varchar NOT NULL DEFAULT '',
+  "target_id" bigint,
+  "ip_address" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  -- Field yang berubah: {"field": {"before": ..., "after": ...}}
+  "changes" jsonb NOT NULL DEFAULT '{}',
+  "details" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_events" ("organization_id", "id");
+CREATE INDEX ON "audit_events" ("user_id", "id");
@@ -0,0 +1,7 @@
+-- Hitungan login gagal berturut-turut per email, termasuk email yang tidak terdaftar
+CREATE TABLE "login_throttles" (
+  "email" varchar PRIMARY KEY,
+  "failures" integer NOT NULL DEFAULT 0,
+  "last_failure_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_until" timestamptz
+);
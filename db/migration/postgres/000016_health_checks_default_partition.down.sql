@@ -0,0 +1,2 @@
+-- Baris di partisi DEFAULT ikut terhapus; buat partisi bulanannya lebih dulu jika data tersebut perlu disimpan
+DROP TABLE IF EXISTS "health_checks_default";
@@ -0,0 +1,3 @@
+-- Menampung health check yang tidak masuk partisi bulanan mana pun (misalnya jika maintenance partisi
+-- terlambat), agar insert tidak gagal. Barisnya dipindahkan saat partisi bulan tersebut dibuat.
+CREATE TABLE IF NOT EXISTS "health_checks_default" PARTITION OF "health_checks" DEFAULT;
@@ -0,0 +1,28 @@
+-- Skema awal untuk backend SQLite. Waktu disimpan sebagai teks RFC 3339 (UTC, presisi nanodetik)
+-- dengan panjang tetap sehingga dapat dibandingkan dan diurutkan secara leksikografis.
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "username" TEXT NOT NULL,
+  "email" TEXT UNIQUE NOT NULL,
+  "password_hash" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "sites" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id"),
+  "url" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "health_checks" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "site_id" INTEGER NOT NULL REFERENCES "sites" ("id"),
+  "status_code" INTEGER,
+  "response_time_ms" INTEGER,
+  "is_up" INTEGER NOT NULL,
+  "checked_at" TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS "sites_user_id_idx" ON "sites" ("user_id");
+CREATE INDEX IF NOT EXISTS "health_checks_site_id_checked_at_idx" ON "health_checks" ("site_id", "checked_at");
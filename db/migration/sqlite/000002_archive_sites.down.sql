@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "sites_archived_at_idx";
+ALTER TABLE "sites" DROP COLUMN "archived_at";
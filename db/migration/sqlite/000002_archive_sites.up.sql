@@ -0,0 +1,3 @@
+ALTER TABLE "sites" ADD COLUMN "archived_at" TEXT;
+
+CREATE INDEX "sites_archived_at_idx" ON "sites" ("archived_at") WHERE "archived_at" IS NOT NULL;
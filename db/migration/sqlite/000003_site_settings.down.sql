@@ -0,0 +1,5 @@
+ALTER TABLE "sites" DROP COLUMN "paused_at";
+ALTER TABLE "sites" DROP COLUMN "timeout_seconds";
+ALTER TABLE "sites" DROP COLUMN "check_interval_seconds";
+ALTER TABLE "sites" DROP COLUMN "description";
+ALTER TABLE "sites" DROP COLUMN "name";
@@ -0,0 +1,5 @@
+ALTER TABLE "sites" ADD COLUMN "name" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "sites" ADD COLUMN "description" TEXT NOT NULL DEFAULT '';
+ALTER TABLE "sites" ADD COLUMN "check_interval_seconds" INTEGER NOT NULL DEFAULT 60;
+ALTER TABLE "sites" ADD COLUMN "timeout_seconds" INTEGER NOT NULL DEFAULT 10;
+ALTER TABLE "sites" ADD COLUMN "paused_at" TEXT;
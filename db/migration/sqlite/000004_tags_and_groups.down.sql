@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "site_group_members";
+DROP TABLE IF EXISTS "site_groups";
+DROP TABLE IF EXISTS "site_tags";
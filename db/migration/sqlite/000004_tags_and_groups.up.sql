@@ -0,0 +1,24 @@
+CREATE TABLE "site_tags" (
+  "site_id" INTEGER NOT NULL REFERENCES "sites" ("id") ON DELETE CASCADE,
+  "tag" TEXT NOT NULL,
+  PRIMARY KEY ("site_id", "tag")
+);
+
+CREATE INDEX "site_tags_tag_idx" ON "site_tags" ("tag");
+
+CREATE TABLE "site_groups" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "name" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL
+);
+
+CREATE INDEX "site_groups_user_id_idx" ON "site_groups" ("user_id");
+
+CREATE TABLE "site_group_members" (
+  "group_id" INTEGER NOT NULL REFERENCES "site_groups" ("id") ON DELETE CASCADE,
+  "site_id" INTEGER NOT NULL REFERENCES "sites" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("group_id", "site_id")
+);
+
+CREATE INDEX "site_group_members_site_id_idx" ON "site_group_members" ("site_id");
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "sites_user_id_check_type_idx";
+ALTER TABLE "sites" DROP COLUMN "check_type";
@@ -0,0 +1,3 @@
+ALTER TABLE "sites" ADD COLUMN "check_type" TEXT NOT NULL DEFAULT 'HTTP';
+
+CREATE INDEX "sites_user_id_check_type_idx" ON "sites" ("user_id", "check_type");
@@ -0,0 +1,23 @@
+CREATE TABLE "sessions" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- SHA-256 dari refresh token yang berlaku saat ini
+  "refresh_token_hash" TEXT UNIQUE NOT NULL,
+  "user_agent" TEXT NOT NULL DEFAULT '',
+  "ip_address" TEXT NOT NULL DEFAULT '',
+  "created_at" TEXT NOT NULL,
+  "last_used_at" TEXT NOT NULL,
+  "expires_at" TEXT NOT NULL,
+  "revoked_at" TEXT
+);
+
+CREATE INDEX "sessions_user_id_idx" ON "sessions" ("user_id");
+
+-- Refresh token yang sudah dirotasi; jika dipakai lagi, session pemiliknya dicabut
+CREATE TABLE "used_refresh_tokens" (
+  "token_hash" TEXT PRIMARY KEY,
+  "session_id" INTEGER NOT NULL REFERENCES "sessions" ("id") ON DELETE CASCADE,
+  "used_at" TEXT NOT NULL
+);
+
+CREATE INDEX "used_refresh_tokens_session_id_idx" ON "used_refresh_tokens" ("session_id");
@@ -0,0 +1,17 @@
+CREATE TABLE "api_keys" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "name" TEXT NOT NULL,
+  -- Awal key yang ditampilkan di daftar agar user dapat mengenali key tanpa menyimpan key utuh
+  "prefix" TEXT NOT NULL,
+  -- SHA-256 dari key; key utuh hanya ditampilkan sekali saat dibuat
+  "key_hash" TEXT UNIQUE NOT NULL,
+  -- Array JSON berisi scope
+  "scopes" TEXT NOT NULL DEFAULT '[]',
+  "created_at" TEXT NOT NULL,
+  "expires_at" TEXT,
+  "last_used_at" TEXT,
+  "revoked_at" TEXT
+);
+
+CREATE INDEX "api_keys_user_id_idx" ON "api_keys" ("user_id");
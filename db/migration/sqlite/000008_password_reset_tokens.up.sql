@@ -0,0 +1,11 @@
+CREATE TABLE "password_reset_tokens" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- SHA-256 dari token yang dikirim lewat email
+  "token_hash" TEXT UNIQUE NOT NULL,
+  "created_at" TEXT NOT NULL,
+  "expires_at" TEXT NOT NULL,
+  "used_at" TEXT
+);
+
+CREATE INDEX "password_reset_tokens_user_id_idx" ON "password_reset_tokens" ("user_id");
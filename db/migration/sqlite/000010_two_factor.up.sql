@@ -0,0 +1,19 @@
+CREATE TABLE "user_totp" (
+  "user_id" INTEGER PRIMARY KEY REFERENCES "users" ("id") ON DELETE CASCADE,
+  "secret" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  -- confirmed_at kosong selama enrolment belum dikonfirmasi dengan kode pertama
+  "confirmed_at" TEXT,
+  -- Periode TOTP terakhir yang dipakai; kode dari periode yang sama atau lebih lama ditolak
+  "last_used_step" INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE "recovery_codes" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- SHA-256 dari kode yang sudah dinormalisasi
+  "code_hash" TEXT NOT NULL,
+  "used_at" TEXT
+);
+
+CREATE INDEX "recovery_codes_user_id_idx" ON "recovery_codes" ("user_id");
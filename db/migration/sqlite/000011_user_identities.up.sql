@@ -0,0 +1,12 @@
+-- Akun di identity provider OIDC yang ditautkan ke user Go-Pulse
+CREATE TABLE "user_identities" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "issuer" TEXT NOT NULL,
+  "subject" TEXT NOT NULL,
+  "email" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  UNIQUE ("issuer", "subject")
+);
+
+CREATE INDEX "user_identities_user_id_idx" ON "user_identities" ("user_id");
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS "site_groups_organization_id_idx";
+ALTER TABLE "site_groups" DROP COLUMN "organization_id";
+DROP INDEX IF EXISTS "sites_organization_id_idx";
+ALTER TABLE "sites" DROP COLUMN "organization_id";
+DROP TABLE IF EXISTS "team_members";
+DROP TABLE IF EXISTS "teams";
+DROP TABLE IF EXISTS "organization_members";
+DROP TABLE IF EXISTS "organizations";
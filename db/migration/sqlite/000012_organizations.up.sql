@@ -0,0 +1,45 @@
+CREATE TABLE "organizations" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "name" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL
+);
+
+CREATE TABLE "organization_members" (
+  "organization_id" INTEGER NOT NULL REFERENCES "organizations" ("id") ON DELETE CASCADE,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  -- owner, admin, editor atau viewer
+  "role" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  PRIMARY KEY ("organization_id", "user_id")
+);
+
+CREATE INDEX "organization_members_user_id_idx" ON "organization_members" ("user_id");
+
+CREATE TABLE "teams" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  "organization_id" INTEGER NOT NULL REFERENCES "organizations" ("id") ON DELETE CASCADE,
+  "name" TEXT NOT NULL,
+  "created_at" TEXT NOT NULL,
+  UNIQUE ("organization_id", "name")
+);
+
+CREATE TABLE "team_members" (
+  "team_id" INTEGER NOT NULL REFERENCES "teams" ("id") ON DELETE CASCADE,
+  "user_id" INTEGER NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  PRIMARY KEY ("team_id", "user_id")
+);
+
+-- Setiap user yang sudah ada mendapat organisasi pribadi dengan id yang sama dengan id user-nya
+INSERT INTO "organizations" ("id", "name", "created_at") SELECT "id", "username", "created_at" FROM "users";
+INSERT INTO "organization_members" ("organization_id", "user_id", "role", "created_at")
+  SELECT "id", "id", 'owner', "created_at" FROM "users";
+
+-- user_id pada sites dan site_groups tetap disimpan sebagai pembuatnya. SQLite tidak dapat menghapus kolom
+-- yang memiliki foreign key, jadi keterkaitan ke organizations dijaga oleh aplikasi.
+ALTER TABLE "sites" ADD COLUMN "organization_id" INTEGER;
+UPDATE "sites" SET "organization_id" = "user_id";
+CREATE INDEX "sites_organization_id_idx" ON "sites" ("organization_id");
+
+ALTER TABLE "site_groups" ADD COLUMN "organization_id" INTEGER;
+UPDATE "site_groups" SET "organization_id" = "user_id";
+CREATE INDEX "site_groups_organization_id_idx" ON "site_groups" ("organization_id");
@@ -0,0 +1,23 @@
+-- Tidak ada foreign key agar event tetap tersimpan setelah user, organisasi atau target-nya dihapus
+CREATE TABLE "audit_events" (
+  "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+  -- Organisasi pemilik target; NULL untuk event akun (login, session, API key, dll.)
+  "organization_id" INTEGER,
+  -- Akun yang terkena event akun
+  "user_id" INTEGER,
+  -- User yang melakukan aksi; NULL jika tidak diketahui, misalnya login gagal dengan email yang tidak terdaftar
+  "actor_id" INTEGER,
+  "api_key_id" INTEGER,
+  "action" TEXT NOT NULL,
+  "target_type" TEXT NOT NULL DEFAULT '',
+  "target_id" INTEGER,
+  "ip_address" TEXT NOT NULL DEFAULT '',
+  "user_agent" TEXT NOT NULL DEFAULT '',
+  -- Objek JSON berisi field yang berubah: {"field": {"before": ..., "after": ...}}
+  "changes" TEXT NOT NULL DEFAULT '{}',
+  "details" TEXT NOT NULL DEFAULT '{}',
+  "created_at" TEXT NOT NULL
+);
+
+CREATE INDEX "audit_events_organization_id_idx" ON "audit_events" ("organization_id", "id");
+CREATE INDEX "audit_events_user_id_idx" ON "audit_events" ("user_id", "id");
@@ -0,0 +1,7 @@
+-- Hitungan login gagal berturut-turut per email, termasuk email yang tidak terdaftar
+CREATE TABLE "login_throttles" (
+  "email" TEXT PRIMARY KEY,
+  "failures" INTEGER NOT NULL DEFAULT 0,
+  "last_failure_at" TEXT NOT NULL,
+  "locked_until" TEXT
+);
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// Awalan semua API key; authMiddleware memakainya untuk membedakan API key dari JWT
+const apiKeyPrefix = "gp_"
+
+// Jumlah karakter awal key yang disimpan dan ditampilkan untuk mengenali key
+const apiKeyDisplayLength = 11
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required,max=100"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=sites:read sites:write incidents:write"`
+	// ExpiresInDays kosong berarti key tidak pernah kedaluwarsa
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+type createAPIKeyResponse struct {
+	db.APIKey
+	// Key hanya dikembalikan sekali saat dibuat
+	Key string `json:"key"`
+}
+
+func (server *Server) createAPIKey(ctx *gin.Context) {
+	var req createAPIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	secret, _, err := newOpaqueToken()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	key := apiKeyPrefix + secret
+
+	// Scope disimpan tanpa duplikat dengan urutan yang sama seperti db.APIScopes
+	scopes := []string{}
+	for _, scope := range db.APIScopes {
+		if slices.Contains(req.Scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	arg := db.CreateAPIKeyParams{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  key[:apiKeyDisplayLength],
+		KeyHash: hashToken(key),
+		Scopes:  scopes,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		arg.ExpiresAt = &expiresAt
+	}
+
+	apiKey, err := server.store.CreateAPIKey(ctx, arg)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		Action:     auditAPIKeyCreate,
+		TargetType: auditTargetAPIKey,
+		TargetID:   apiKey.ID,
+		Changes:    db.AuditDiff(nil, apiKey),
+	})
+
+	ctx.JSON(http.StatusOK, createAPIKeyResponse{APIKey: apiKey, Key: key})
+}
+
+func (server *Server) listAPIKeys(ctx *gin.Context) {
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	keys, err := server.store.ListAPIKeys(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+func (server *Server) revokeAPIKey(ctx *gin.Context) {
+	keyID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid API key ID"))
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	if err := server.store.RevokeAPIKey(ctx, keyID, userID); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("API key not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		Action:     auditAPIKeyRevoke,
+		TargetType: auditTargetAPIKey,
+		TargetID:   keyID,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "API key revoked successfully"})
+}
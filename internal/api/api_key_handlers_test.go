@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// createAPIKey membuat API key lewat API dengan scope yang diberikan.
+func (ts *testServer) createAPIKey(t *testing.T, token string, scopes ...string) createAPIKeyResponse {
+	t.Helper()
+	rec := ts.request(t, http.MethodPost, "/api/keys", token, gin.H{"name": "ci", "scopes": scopes})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create API key: status %d: %s", rec.Code, rec.Body)
+	}
+	var resp createAPIKeyResponse
+	decodeBody(t, rec, &resp)
+	return resp
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+	readKey := ts.createAPIKey(t, token, db.ScopeSitesRead)
+	writeKey := ts.createAPIKey(t, token, db.ScopeSitesWrite)
+
+	rec := ts.request(t, http.MethodPost, "/api/sites", readKey.Key, gin.H{"url": "https://example.com"})
+	requireError(t, rec, http.StatusForbidden, codeForbidden)
+	rec = ts.request(t, http.MethodGet, "/api/sites", readKey.Key, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list sites with sites:read key: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = ts.request(t, http.MethodPost, "/api/sites", writeKey.Key, gin.H{"url": "https://example.com"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create site with sites:write key: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.request(t, http.MethodGet, "/api/sites", writeKey.Key, nil)
+	requireError(t, rec, http.StatusForbidden, codeForbidden)
+}
+
+func TestExpiredAndRevokedAPIKeysAreRejected(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	revoked := ts.createAPIKey(t, token, db.ScopeSitesRead)
+	rec := ts.request(t, http.MethodDelete, "/api/keys/"+strconv.FormatInt(revoked.ID, 10), token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revoke API key: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.request(t, http.MethodGet, "/api/sites", revoked.Key, nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+
+	// Key yang kedaluwarsa dibuat langsung di store karena API hanya menerima masa berlaku di masa depan
+	expiredKey := apiKeyPrefix + "expired-key-secret"
+	expiresAt := time.Now().Add(-time.Minute)
+	_, err := ts.store.CreateAPIKey(context.Background(), db.CreateAPIKeyParams{
+		UserID:    user.ID,
+		Name:      "expired",
+		Prefix:    expiredKey[:apiKeyDisplayLength],
+		KeyHash:   hashToken(expiredKey),
+		Scopes:    []string{db.ScopeSitesRead},
+		ExpiresAt: &expiresAt,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = ts.request(t, http.MethodGet, "/api/sites", expiredKey, nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+
+	rec = ts.request(t, http.MethodGet, "/api/sites", apiKeyPrefix+"unknown", nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+}
+
+func TestAPIKeyIsReturnedOnlyOnCreate(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	created := ts.createAPIKey(t, token, db.ScopeSitesRead)
+	if !strings.HasPrefix(created.Key, apiKeyPrefix) || created.Prefix != created.Key[:apiKeyDisplayLength] {
+		t.Fatalf("created key %q has prefix %q", created.Key, created.Prefix)
+	}
+
+	rec := ts.request(t, http.MethodGet, "/api/keys", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list API keys: status %d: %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, created.Key) || strings.Contains(body, hashToken(created.Key)) || strings.Contains(body, "key_hash") {
+		t.Fatalf("list API keys exposes the key or its hash: %s", body)
+	}
+	var keys []map[string]any
+	decodeBody(t, rec, &keys)
+	if len(keys) != 1 || keys[0]["prefix"] != created.Prefix {
+		t.Fatalf("keys = %v, want one key with prefix %q", keys, created.Prefix)
+	}
+	if _, ok := keys[0]["key"]; ok {
+		t.Errorf("list API keys returns the key field: %v", keys[0])
+	}
+}
+
+func TestAPIKeyIsRejectedOnSessionRoutes(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+	key := ts.createAPIKey(t, token, db.APIScopes...)
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/api/keys"},
+		{http.MethodPost, "/api/keys"},
+		{http.MethodDelete, "/api/keys/" + strconv.FormatInt(key.ID, 10)},
+		{http.MethodGet, "/api/sessions"},
+		{http.MethodDelete, "/api/sessions"},
+		{http.MethodGet, "/api/organizations"},
+		{http.MethodPost, "/api/auth/2fa/totp"},
+	}
+	for _, route := range routes {
+		rec := ts.request(t, route.method, route.path, key.Key, gin.H{"name": "other", "scopes": []string{db.ScopeSitesRead}})
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s with API key: status %d, want 403: %s", route.method, route.path, rec.Code, rec.Body)
+		}
+	}
+
+	// Key tidak ikut tercabut oleh percobaan di atas
+	rec := ts.request(t, http.MethodGet, "/api/sites", key.Key, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list sites with API key: status %d: %s", rec.Code, rec.Body)
+	}
+}
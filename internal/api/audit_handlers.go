@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// Aksi yang dicatat di audit log
+const (
+	auditUserRegister       = "user.register"
+	auditUserVerifyEmail    = "user.verify_email"
+	auditUserRequestReset   = "user.request_password_reset"
+	auditUserResetPassword  = "user.reset_password"
+	auditLogin              = "auth.login"
+	auditLoginFailed        = "auth.login_failed"
+	auditLoginLocked        = "auth.login_locked"
+	auditLogout             = "auth.logout"
+	auditSessionRevoke      = "session.revoke"
+	auditSessionRevokeOther = "session.revoke_others"
+	auditTwoFactorEnroll    = "two_factor.enroll"
+	auditTwoFactorEnable    = "two_factor.enable"
+	auditTwoFactorDisable   = "two_factor.disable"
+	auditAPIKeyCreate       = "api_key.create"
+	auditAPIKeyRevoke       = "api_key.revoke"
+
+	auditSiteCreate  = "site.create"
+	auditSiteUpdate  = "site.update"
+	auditSiteDelete  = "site.delete"
+	auditSiteRestore = "site.restore"
+	auditSitePause   = "site.pause"
+	auditSiteResume  = "site.resume"
+	auditGroupCreate = "group.create"
+	auditGroupUpdate = "group.update"
+	auditGroupDelete = "group.delete"
+	auditConfigApply = "config.apply"
+
+	auditOrganizationCreate = "organization.create"
+	auditOrganizationUpdate = "organization.update"
+	auditOrganizationDelete = "organization.delete"
+	auditMemberAdd          = "member.add"
+	auditMemberUpdate       = "member.update"
+	auditMemberRemove       = "member.remove"
+	auditTeamCreate         = "team.create"
+	auditTeamUpdate         = "team.update"
+	auditTeamDelete         = "team.delete"
+)
+
+// Jenis target event audit
+const (
+	auditTargetUser         = "user"
+	auditTargetSession      = "session"
+	auditTargetAPIKey       = "api_key"
+	auditTargetSite         = "site"
+	auditTargetGroup        = "group"
+	auditTargetOrganization = "organization"
+	auditTargetMember       = "member"
+	auditTargetTeam         = "team"
+)
+
+// recordAudit menyimpan event audit beserta IP dan user agent request. Actor, API key dan organisasi diambil dari
+// context jika belum diisi. Kegagalan hanya dicatat di log agar aksi yang sudah berhasil tidak dilaporkan gagal.
+func (server *Server) recordAudit(ctx *gin.Context, event db.CreateAuditEventParams) {
+	if event.ActorID == 0 {
+		event.ActorID = ctx.GetInt64(authorizationPayloadKey)
+	}
+	if value, ok := ctx.Get(authorizationAPIKeyKey); ok && event.APIKeyID == 0 {
+		event.APIKeyID = value.(db.APIKey).ID
+	}
+	if event.OrganizationID == 0 && event.UserID == 0 {
+		event.OrganizationID = ctx.GetInt64(authorizationOrganizationKey)
+	}
+	event.IPAddress = ctx.ClientIP()
+	event.UserAgent = ctx.Request.UserAgent()
+
+	if _, err := server.store.CreateAuditEvent(ctx, event); err != nil {
+		log.Printf("Failed to record audit event %s: %v", event.Action, err)
+	}
+}
+
+// siteBefore mengembalikan keadaan site milik organisasi sebelum diubah, untuk dibandingkan di audit log. nil
+// dikembalikan jika site tidak dapat dibaca; method store yang mengubah site akan melaporkan error-nya.
+func (server *Server) siteBefore(ctx *gin.Context, siteID, orgID int64) *db.Site {
+	site, err := server.store.GetSite(ctx, siteID)
+	if err != nil || site.OrganizationID != orgID {
+		return nil
+	}
+	return &site
+}
+
+// recordSiteAudit mencatat perubahan satu site milik organisasi aktif.
+func (server *Server) recordSiteAudit(ctx *gin.Context, action string, siteID int64, before, after *db.Site) {
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     action,
+		TargetType: auditTargetSite,
+		TargetID:   siteID,
+		Changes:    db.AuditDiff(before, after),
+	})
+}
+
+type listAuditEventsRequest struct {
+	Action     string    `form:"action" binding:"max=100"`
+	ActorID    int64     `form:"actor_id" binding:"omitempty,min=1"`
+	TargetType string    `form:"target_type" binding:"max=100"`
+	TargetID   int64     `form:"target_id" binding:"omitempty,min=1"`
+	Since      time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until      time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int       `form:"limit" binding:"omitempty,min=1,max=200"`
+	Cursor     string    `form:"cursor"`
+}
+
+// listAuditEvents mengembalikan audit log organisasi aktif, dari yang terbaru.
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	server.listAuditEventsFor(ctx, db.ListAuditEventsParams{OrganizationID: ctx.GetInt64(authorizationOrganizationKey)})
+}
+
+// listAccountAuditEvents mengembalikan event akun milik user (login, session, API key, 2FA, dll.), dari yang terbaru.
+func (server *Server) listAccountAuditEvents(ctx *gin.Context) {
+	server.listAuditEventsFor(ctx, db.ListAuditEventsParams{UserID: ctx.GetInt64(authorizationPayloadKey)})
+}
+
+func (server *Server) listAuditEventsFor(ctx *gin.Context, arg db.ListAuditEventsParams) {
+	var req listAuditEventsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	arg.Action = req.Action
+	arg.ActorID = req.ActorID
+	arg.TargetType = req.TargetType
+	arg.TargetID = req.TargetID
+	arg.Limit = req.Limit
+	arg.Cursor = req.Cursor
+	if !req.Since.IsZero() {
+		arg.Since = &req.Since
+	}
+	if !req.Until.IsZero() {
+		arg.Until = &req.Until
+	}
+
+	result, err := server.store.ListAuditEvents(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrInvalidCursor) {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+const testUserAgent = "pulse-test/1.0"
+
+// listAudit mengambil satu halaman audit log lewat path yang diberikan.
+func (ts *testServer) listAudit(t *testing.T, token, path string, headers ...string) db.ListAuditEventsResult {
+	t.Helper()
+	rec := ts.request(t, http.MethodGet, path, token, nil, headers...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", path, rec.Code, rec.Body)
+	}
+	var result db.ListAuditEventsResult
+	decodeBody(t, rec, &result)
+	return result
+}
+
+// addMember menambahkan user ke organisasi dengan role yang diberikan.
+func (ts *testServer) addMember(t *testing.T, orgID, userID int64, role string) {
+	t.Helper()
+	_, err := ts.store.AddOrganizationMember(context.Background(), db.AddOrganizationMemberParams{
+		OrganizationID: orgID, UserID: userID, Role: role,
+	})
+	if err != nil {
+		t.Fatalf("AddOrganizationMember: %v", err)
+	}
+}
+
+func TestSiteChangesAreAudited(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+	userAgent := []string{"User-Agent", testUserAgent}
+
+	rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "https://example.com", "name": "Example"}, userAgent...)
+	var site db.Site
+	decodeBody(t, rec, &site)
+	path := fmt.Sprintf("/api/sites/%d", site.ID)
+	rec = ts.request(t, http.MethodPatch, path, token, gin.H{"name": "Renamed"}, userAgent...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update site: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.request(t, http.MethodDelete, path, token, nil, userAgent...)
+	if rec.Code != http.StatusOK && rec.Code != http.StatusNoContent {
+		t.Fatalf("delete site: status %d: %s", rec.Code, rec.Body)
+	}
+
+	events := ts.listAudit(t, token, fmt.Sprintf("/api/audit?target_type=site&target_id=%d", site.ID)).Events
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(events), events)
+	}
+	for i, action := range []string{auditSiteDelete, auditSiteUpdate, auditSiteCreate} {
+		e := events[i]
+		if e.Action != action || e.ActorID != user.ID || e.OrganizationID != ts.defaultOrganization(t, user.ID).ID {
+			t.Errorf("event %d = %+v, want %s by user %d", i, e, action, user.ID)
+		}
+		// httptest mengirim request dari 192.0.2.1
+		if e.IPAddress != "192.0.2.1" || e.UserAgent != testUserAgent {
+			t.Errorf("%s recorded IP %q and user agent %q", action, e.IPAddress, e.UserAgent)
+		}
+	}
+
+	deleted, updated, created := events[0].Changes, events[1].Changes, events[2].Changes
+	if c := created["url"]; c.Before != nil || c.After != "https://example.com" {
+		t.Errorf("create url change = %+v", c)
+	}
+	if c := updated["name"]; c.Before != "Example" || c.After != "Renamed" || len(updated) != 1 {
+		t.Errorf("update changes = %+v, want only name", updated)
+	}
+	if c := deleted["archived_at"]; c.Before != nil || c.After == nil {
+		t.Errorf("delete archived_at change = %+v", c)
+	}
+}
+
+func TestFailedLoginIsAudited(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	userAgent := []string{"User-Agent", testUserAgent}
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": user.Email, "password": "wrong-password"}, userAgent...)
+	requireError(t, rec, http.StatusUnauthorized, codeInvalidCredentials)
+	rec = ts.request(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": "nobody@example.com", "password": "wrong-password"}, userAgent...)
+	requireError(t, rec, http.StatusUnauthorized, codeInvalidCredentials)
+
+	// Login gagal dengan email terdaftar muncul di audit akun user tersebut
+	token := ts.login(t, user.Email)
+	events := ts.listAudit(t, token, "/api/audit/account?action="+auditLoginFailed).Events
+	if len(events) != 1 {
+		t.Fatalf("got %d failed login events, want 1: %+v", len(events), events)
+	}
+	e := events[0]
+	if e.UserID != user.ID || e.TargetType != auditTargetUser || e.TargetID != user.ID || e.Details["reason"] != "invalid password" {
+		t.Errorf("failed login event = %+v", e)
+	}
+	if e.IPAddress != "192.0.2.1" || e.UserAgent != testUserAgent {
+		t.Errorf("failed login recorded IP %q and user agent %q", e.IPAddress, e.UserAgent)
+	}
+
+	// Email yang tidak terdaftar tetap dicatat, tanpa user maupun organisasi
+	result, err := ts.store.ListAuditEvents(context.Background(), db.ListAuditEventsParams{Action: auditLoginFailed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 1 || result.Events[0].Details["email"] != "nobody@example.com" || result.Events[0].UserAgent != testUserAgent {
+		t.Errorf("unknown email events = %+v", result.Events)
+	}
+}
+
+func TestListAuditEventsFilters(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.createUser(t, "alice", true)
+	bob := ts.createUser(t, "bob", true)
+	org := ts.defaultOrganization(t, alice.ID)
+	ts.addMember(t, org.ID, bob.ID, db.RoleAdmin)
+	aliceToken, bobToken := ts.login(t, alice.Email), ts.login(t, bob.Email)
+
+	createSite := func(token, url string) db.Site {
+		rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": url}, orgHeader(org.ID)...)
+		var site db.Site
+		decodeBody(t, rec, &site)
+		return site
+	}
+	first := createSite(aliceToken, "https://first.example.com")
+	second := createSite(aliceToken, "https://second.example.com")
+	third := createSite(bobToken, "https://third.example.com")
+	rec := ts.request(t, http.MethodPatch, fmt.Sprintf("/api/sites/%d", first.ID), aliceToken, gin.H{"name": "First"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update site: status %d: %s", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{"action=" + auditSiteUpdate, []int64{first.ID}},
+		{"action=" + auditSiteCreate, []int64{third.ID, second.ID, first.ID}},
+		{fmt.Sprintf("actor_id=%d", bob.ID), []int64{third.ID}},
+		{fmt.Sprintf("target_type=site&target_id=%d", first.ID), []int64{first.ID, first.ID}},
+		{fmt.Sprintf("action=%s&actor_id=%d", auditSiteCreate, alice.ID), []int64{second.ID, first.ID}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.query, func(t *testing.T) {
+			events := ts.listAudit(t, aliceToken, "/api/audit?"+tc.query).Events
+			var got []int64
+			for _, e := range events {
+				got = append(got, e.TargetID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("target IDs = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestListAuditEventsPagination(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+	for i := range 5 {
+		rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": fmt.Sprintf("https://site%d.example.com", i)})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("create site: status %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	var sizes []int
+	var lastID int64
+	cursor := ""
+	for page := 0; page < 5; page++ {
+		result := ts.listAudit(t, token, "/api/audit?action="+auditSiteCreate+"&limit=2&cursor="+cursor)
+		sizes = append(sizes, len(result.Events))
+		// Event diurutkan dari yang terbaru tanpa ada yang terulang antar halaman
+		for _, e := range result.Events {
+			if lastID != 0 && e.ID >= lastID {
+				t.Errorf("event %d listed after event %d", e.ID, lastID)
+			}
+			lastID = e.ID
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		cursor = result.NextCursor
+	}
+	if fmt.Sprint(sizes) != "[2 2 1]" {
+		t.Errorf("page sizes = %v, want [2 2 1]", sizes)
+	}
+
+	rec := ts.request(t, http.MethodGet, "/api/audit?cursor=not-a-cursor", token, nil)
+	requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+	rec = ts.request(t, http.MethodGet, "/api/audit?limit=500", token, nil)
+	requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+}
+
+func TestAuditLogRequiresAdmin(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUser(t, "alice", true)
+	org := ts.defaultOrganization(t, owner.ID)
+
+	for _, role := range []string{db.RoleViewer, db.RoleEditor, db.RoleAdmin} {
+		t.Run(role, func(t *testing.T) {
+			member := ts.createUser(t, role, true)
+			ts.addMember(t, org.ID, member.ID, role)
+			token := ts.login(t, member.Email)
+
+			rec := ts.request(t, http.MethodGet, "/api/audit", token, nil, orgHeader(org.ID)...)
+			if role == db.RoleAdmin {
+				if rec.Code != http.StatusOK {
+					t.Fatalf("admin list audit: status %d: %s", rec.Code, rec.Body)
+				}
+			} else {
+				requireError(t, rec, http.StatusForbidden, codeForbidden)
+			}
+
+			// Audit akun sendiri tetap dapat dibaca oleh semua role
+			rec = ts.request(t, http.MethodGet, "/api/audit/account", token, nil)
+			if rec.Code != http.StatusOK {
+				t.Errorf("list account audit: status %d: %s", rec.Code, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/config"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// Batas ukuran dokumen konfigurasi yang diterima
+const maxConfigBytes = 1 << 20
+
+type exportConfigRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=yaml json"`
+}
+
+func (server *Server) exportConfig(ctx *gin.Context) {
+	var req exportConfigRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = config.FormatYAML
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	doc, err := config.Export(ctx, server.store, orgID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	data, err := config.Marshal(doc, req.Format)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	contentType := "application/yaml"
+	if req.Format == config.FormatJSON {
+		contentType = "application/json"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="pulse-config.%s"`, req.Format))
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+type applyConfigRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=yaml json"`
+	DryRun bool   `form:"dry_run"`
+}
+
+type applyConfigResponse struct {
+	DryRun bool        `json:"dry_run"`
+	Plan   config.Plan `json:"plan"`
+}
+
+// applyConfig menerapkan dokumen konfigurasi. Format diambil dari parameter format atau Content-Type
+// (default YAML); dengan dry_run=true hanya rencana perubahan yang dikembalikan.
+func (server *Server) applyConfig(ctx *gin.Context) {
+	var req applyConfigRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = config.FormatYAML
+		if strings.Contains(ctx.ContentType(), "json") {
+			req.Format = config.FormatJSON
+		}
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxConfigBytes))
+	if err != nil {
+		respondError(ctx, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	doc, err := config.Parse(body, req.Format)
+	var plan config.Plan
+	if err == nil {
+		if req.DryRun {
+			plan, err = config.BuildPlan(ctx, server.store, orgID, doc)
+		} else if err = server.checkConfigSiteLimit(ctx, userID, orgID, doc); err == nil {
+			plan, err = config.Apply(ctx, server.store, orgID, userID, doc)
+		}
+	}
+	if err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if errors.Is(err, errSiteLimitReached) {
+			respondError(ctx, http.StatusForbidden, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !req.DryRun {
+		server.recordAudit(ctx, db.CreateAuditEventParams{
+			Action:  auditConfigApply,
+			Details: map[string]any{"summary": plan.Summary, "changes": plan.Changes},
+		})
+	}
+
+	ctx.JSON(http.StatusOK, applyConfigResponse{DryRun: req.DryRun, Plan: plan})
+}
+
+// checkConfigSiteLimit menolak dokumen yang akan membuat akun yang belum terverifikasi melebihi batas site.
+func (server *Server) checkConfigSiteLimit(ctx *gin.Context, userID, orgID int64, doc config.Document) error {
+	remaining, err := server.remainingSites(ctx, userID)
+	if err != nil || remaining < 0 {
+		return err
+	}
+	plan, err := config.BuildPlan(ctx, server.store, orgID, doc)
+	if err != nil {
+		return err
+	}
+	added := 0
+	for _, change := range plan.Changes {
+		if change.Kind != config.KindSite {
+			continue
+		}
+		switch change.Action {
+		case config.ActionCreate:
+			added++
+		case config.ActionDelete:
+			added--
+		}
+	}
+	if added > remaining {
+		return errSiteLimitReached
+	}
+	return nil
+}
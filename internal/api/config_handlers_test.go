@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/config"
+)
+
+// applyConfig mengirim dokumen konfigurasi YAML ke /api/config/apply dengan query tambahan, mis. "dry_run=true".
+func (ts *testServer) applyConfig(t *testing.T, token, document, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/config/apply?"+query, strings.NewReader(document))
+	req.Header.Set("Content-Type", "application/yaml")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	ts.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestApplyConfigValidationErrors(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	rec := ts.applyConfig(t, token, `version: 1
+sites:
+  - url: https://example.com
+  - url: ftp://example.com
+    timeout_seconds: 90
+`, "")
+	body := requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+	want := map[string]bool{"sites[1].url": true, "sites[1].timeout_seconds": true}
+	if len(body.Details) != len(want) {
+		t.Fatalf("details = %v, want fields %v", body.Details, want)
+	}
+	for _, d := range body.Details {
+		if !want[d.Field] || d.Message == "" {
+			t.Errorf("unexpected detail %+v", d)
+		}
+	}
+
+	// Dokumen yang tidak dapat dibaca dilaporkan tanpa nama field
+	rec = ts.applyConfig(t, token, "version: 1\nunknown: true\n", "")
+	body = requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+	if len(body.Details) != 1 || body.Details[0].Field != "" {
+		t.Errorf("details = %v, want one problem without a field", body.Details)
+	}
+}
+
+func TestExportThenApplyConfigIsEmptyPlan(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+	for _, url := range []string{"https://a.example.com", "https://b.example.com"} {
+		if rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": url, "tags": []string{"prod"}}); rec.Code != http.StatusOK {
+			t.Fatalf("create site: status %d: %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec := ts.request(t, http.MethodGet, "/api/config/export", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.applyConfig(t, token, rec.Body.String(), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("apply: status %d: %s", rec.Code, rec.Body)
+	}
+	var resp applyConfigResponse
+	decodeBody(t, rec, &resp)
+	if len(resp.Plan.Changes) != 0 || resp.Plan.Summary.Unchanged != 2 {
+		t.Errorf("plan = %+v, want no changes", resp.Plan)
+	}
+}
+
+func TestApplyConfigDryRunDoesNotChangeStore(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+	org := ts.defaultOrganization(t, user.ID)
+	if rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "https://old.example.com"}); rec.Code != http.StatusOK {
+		t.Fatalf("create site: status %d: %s", rec.Code, rec.Body)
+	}
+
+	document := "version: 1\nsites:\n  - url: https://new.example.com\n"
+	rec := ts.applyConfig(t, token, document, "dry_run=true")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("dry run: status %d: %s", rec.Code, rec.Body)
+	}
+	var resp applyConfigResponse
+	decodeBody(t, rec, &resp)
+	if !resp.DryRun || resp.Plan.Summary != (config.PlanSummary{Create: 1, Delete: 1}) {
+		t.Fatalf("dry run response = %+v", resp)
+	}
+	sites, err := ts.store.GetSitesByOrganizationID(context.Background(), org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 1 || sites[0].URL != "https://old.example.com" {
+		t.Fatalf("sites after dry run = %+v, want only the old site", sites)
+	}
+
+	rec = ts.applyConfig(t, token, document, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("apply: status %d: %s", rec.Code, rec.Body)
+	}
+	if sites, _ = ts.store.GetSitesByOrganizationID(context.Background(), org.ID); len(sites) != 1 || sites[0].URL != "https://new.example.com" {
+		t.Errorf("sites after apply = %+v, want only the new site", sites)
+	}
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/tajri15/go-pulse-monitoring/internal/config"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// Kode error di respons API. Kode bersifat stabil sehingga client dapat bergantung padanya; pesan dapat berubah.
+const (
+	codeBadRequest       = "bad_request"
+	codeValidationFailed = "validation_failed"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeNotFound         = "not_found"
+	codeConflict         = "conflict"
+	codePayloadTooLarge  = "payload_too_large"
+	codeRateLimited      = "rate_limited"
+	codeInternal         = "internal_error"
+	codeBadGateway       = "bad_gateway"
+
+	codeInvalidCredentials   = "invalid_credentials"
+	codeLoginLocked          = "login_locked"
+	codeInvalidTwoFactorCode = "invalid_two_factor_code"
+	codeNoOrganization       = "no_organization"
+	codeLastOwner            = "last_owner"
+	codeOwnerRoleRequired    = "owner_role_required"
+	codeAlreadyMember        = "already_member"
+	codeTeamNameTaken        = "team_name_taken"
+	codeSSONotConfigured     = "sso_not_configured"
+)
+
+// errorBody adalah isi field "error" di setiap respons error, misalnya:
+//
+//	{"error": {"code": "validation_failed", "message": "request validation failed",
+//	           "details": [{"field": "email", "message": "must be a valid email address"}]}}
+type errorBody struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []fieldError `json:"details,omitempty"`
+}
+
+// fieldError menjelaskan satu field input yang tidak valid.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// apiError adalah error handler dengan kode yang lebih spesifik dari kode bawaan status HTTP-nya.
+type apiError struct {
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+func newAPIError(code, message string) error {
+	return &apiError{code: code, message: message}
+}
+
+func init() {
+	// Nama field di detail validasi mengikuti nama di JSON atau query string, bukan nama field Go
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form", "uri"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+	}
+}
+
+// respondError mengirim err dalam format error standar. Lihat errorResponse.
+func respondError(ctx *gin.Context, status int, err error) {
+	ctx.JSON(errorResponse(ctx, status, err))
+}
+
+// abortWithError seperti respondError, tetapi juga menghentikan handler berikutnya.
+func abortWithError(ctx *gin.Context, status int, err error) {
+	ctx.AbortWithStatusJSON(errorResponse(ctx, status, err))
+}
+
+// errorResponse membuat status dan body respons untuk err. Error domain dari store (db.ErrRecordNotFound,
+// db.ErrConflict, db.ErrValidation) menentukan status jika handler memakai status 500, error validasi binding
+// dan dokumen konfigurasi menghasilkan detail per field, dan pesan error 5xx yang tidak dikenal hanya dicatat
+// di log agar detail database tidak terlihat oleh client.
+func errorResponse(ctx *gin.Context, status int, err error) (int, gin.H) {
+	if domainStatus, ok := statusForDomainError(err); ok && status == http.StatusInternalServerError {
+		status = domainStatus
+	}
+	body := errorBody{Code: codeForStatus(status), Message: err.Error()}
+
+	var apiErr *apiError
+	var domainErr *db.Error
+	var configErr *config.ValidationError
+	var validationErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var numErr *strconv.NumError
+	var timeErr *time.ParseError
+	switch {
+	case errors.As(err, &apiErr):
+		body.Code = apiErr.code
+	case status >= http.StatusInternalServerError:
+		log.Printf("%s %s: %v", ctx.Request.Method, ctx.FullPath(), err)
+		body.Message = strings.ToLower(http.StatusText(status))
+	case errors.As(err, &validationErrs):
+		body.Code, body.Message = codeValidationFailed, "request validation failed"
+		for _, fe := range validationErrs {
+			body.Details = append(body.Details, fieldError{Field: validationField(fe), Message: validationMessage(fe)})
+		}
+	case errors.As(err, &configErr):
+		body.Code, body.Message = codeValidationFailed, "invalid configuration"
+		for _, p := range configErr.Problems {
+			body.Details = append(body.Details, fieldError{Field: p.Field, Message: p.Message})
+		}
+	case errors.As(err, &domainErr):
+		if errors.Is(err, db.ErrValidation) {
+			body.Code = codeValidationFailed
+			if domainErr.Field != "" {
+				body.Details = []fieldError{{Field: domainErr.Field, Message: domainErr.Message}}
+			}
+		}
+	case errors.Is(err, db.ErrRecordNotFound):
+		body.Message = "resource not found"
+	case errors.As(err, &typeErr):
+		body.Code, body.Message = codeValidationFailed, "request validation failed"
+		body.Details = []fieldError{{Field: typeErr.Field, Message: "must be " + jsonTypeName(typeErr.Type)}}
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		body.Message = "request body must be valid JSON"
+	case errors.As(err, &numErr):
+		body.Message = fmt.Sprintf("invalid number %q", numErr.Num)
+	case errors.As(err, &timeErr):
+		body.Message = fmt.Sprintf("invalid time %q, expected RFC 3339 format", timeErr.Value)
+	}
+
+	return status, gin.H{"error": body}
+}
+
+// statusForDomainError mengembalikan status HTTP untuk jenis error domain dari store.
+func statusForDomainError(err error) (int, bool) {
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		return http.StatusNotFound, true
+	case errors.Is(err, db.ErrConflict):
+		return http.StatusConflict, true
+	case errors.Is(err, db.ErrValidation):
+		return http.StatusBadRequest, true
+	}
+	return 0, false
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return codeBadRequest
+	case http.StatusUnauthorized:
+		return codeUnauthorized
+	case http.StatusForbidden:
+		return codeForbidden
+	case http.StatusNotFound:
+		return codeNotFound
+	case http.StatusConflict:
+		return codeConflict
+	case http.StatusRequestEntityTooLarge:
+		return codePayloadTooLarge
+	case http.StatusTooManyRequests:
+		return codeRateLimited
+	case http.StatusBadGateway:
+		return codeBadGateway
+	}
+	if status >= http.StatusInternalServerError {
+		return codeInternal
+	}
+	return codeBadRequest
+}
+
+// validationField mengembalikan path field tanpa nama struct request, misalnya "sites[0].url".
+func validationField(fe validator.FieldError) string {
+	_, field, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return field
+}
+
+// validationMessage menerjemahkan tag validator yang dipakai request di package ini menjadi pesan singkat.
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(fe.Param(), " ", ", ")
+	case "min", "max":
+		limit := "at least"
+		if fe.Tag() == "max" {
+			limit = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", limit, fe.Param())
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return fmt.Sprintf("must contain %s %s items", limit, fe.Param())
+		}
+		return fmt.Sprintf("must be %s %s", limit, fe.Param())
+	}
+	return fmt.Sprintf("failed %q validation", fe.Tag())
+}
+
+// jsonTypeName mengembalikan nama tipe JSON untuk tipe Go, untuk pesan error tipe yang salah.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	}
+	return "a number"
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	// Jumlah baris yang ditulis sebelum response di-flush ke client
+	exportFlushEvery = 500
+)
+
+type exportChecksRequest struct {
+	From   time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To     time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Format string    `form:"format" binding:"omitempty,oneof=csv ndjson"`
+}
+
+// exportSiteChecks mengekspor riwayat health check satu site milik organisasi.
+func (server *Server) exportSiteChecks(ctx *gin.Context) {
+	siteID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid site ID"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	site, err := server.store.GetSite(ctx, siteID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("site not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if site.OrganizationID != orgID {
+		respondError(ctx, http.StatusNotFound, errors.New("site not found"))
+		return
+	}
+
+	server.streamChecks(ctx, orgID, siteID, fmt.Sprintf("site-%d-checks", siteID))
+}
+
+// exportAllChecks mengekspor riwayat health check seluruh site milik organisasi.
+func (server *Server) exportAllChecks(ctx *gin.Context) {
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	server.streamChecks(ctx, orgID, 0, "checks")
+}
+
+// streamChecks menulis hasil query langsung ke response tanpa menampungnya di memori.
+func (server *Server) streamChecks(ctx *gin.Context, orgID, siteID int64, filename string) {
+	var req exportChecksRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// Default: 24 jam terakhir dalam format CSV
+	if req.To.IsZero() {
+		req.To = time.Now()
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-24 * time.Hour)
+	}
+	if !req.From.Before(req.To) {
+		respondError(ctx, http.StatusBadRequest, errors.New("from must be before to"))
+		return
+	}
+	if req.Format == "" {
+		req.Format = exportFormatCSV
+	}
+
+	arg := db.StreamHealthChecksParams{
+		OrganizationID: orgID,
+		SiteID:         siteID,
+		From:           req.From,
+		To:             req.To,
+	}
+
+	w := ctx.Writer
+	var contentType string
+	var writeHeader func() error
+	var writeRow func(db.HealthCheck) error
+	var flush func()
+	switch req.Format {
+	case exportFormatNDJSON:
+		contentType = "application/x-ndjson"
+		encoder := json.NewEncoder(w)
+		writeHeader = func() error { return nil }
+		writeRow = func(hc db.HealthCheck) error { return encoder.Encode(hc) }
+		flush = w.Flush
+	default:
+		contentType = "text/csv"
+		csvWriter := csv.NewWriter(w)
+		writeHeader = func() error {
+			return csvWriter.Write([]string{"id", "site_id", "status_code", "response_time_ms", "is_up", "checked_at"})
+		}
+		writeRow = func(hc db.HealthCheck) error {
+			return csvWriter.Write([]string{
+				strconv.FormatInt(hc.ID, 10),
+				strconv.FormatInt(hc.SiteID, 10),
+				strconv.Itoa(hc.StatusCode),
+				strconv.Itoa(hc.ResponseTimeMs),
+				strconv.FormatBool(hc.IsUp),
+				hc.CheckedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		flush = func() {
+			csvWriter.Flush()
+			w.Flush()
+		}
+	}
+
+	// Header response baru dikirim saat baris pertama terbaca (atau query selesai tanpa baris), sehingga
+	// error saat membuka query masih bisa dikirim sebagai respons error biasa
+	started := false
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, req.Format))
+		w.WriteHeader(http.StatusOK)
+		return writeHeader()
+	}
+
+	count := 0
+	err := server.store.StreamHealthChecks(ctx, arg, func(hc db.HealthCheck) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := writeRow(hc); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushEvery == 0 {
+			flush()
+		}
+		return nil
+	})
+	if !started {
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if err := start(); err != nil {
+			return
+		}
+	}
+	flush()
+
+	// Header sudah terkirim, jadi error di tengah stream hanya bisa dicatat
+	if err != nil {
+		log.Printf("Error exporting checks for organization ID %d: %v", orgID, err)
+	}
+}
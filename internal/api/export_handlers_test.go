@@ -0,0 +1,200 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// exportFixture membuat user dengan satu site dan dua health check satu jam yang lalu.
+func exportFixture(t *testing.T, ts *testServer) (token string, site db.Site, checks []db.HealthCheck) {
+	t.Helper()
+	user := ts.createUser(t, "alice", true)
+	token = ts.login(t, user.Email)
+	rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "https://example.com"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create site: status %d: %s", rec.Code, rec.Body)
+	}
+	decodeBody(t, rec, &site)
+
+	checkedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	checks = []db.HealthCheck{
+		{SiteID: site.ID, StatusCode: 200, ResponseTimeMs: 120, IsUp: true, CheckedAt: checkedAt},
+		{SiteID: site.ID, StatusCode: 503, ResponseTimeMs: 80, IsUp: false, CheckedAt: checkedAt.Add(time.Minute)},
+	}
+	if _, err := ts.store.CreateHealthChecks(context.Background(), checks); err != nil {
+		t.Fatal(err)
+	}
+	return token, site, checks
+}
+
+func TestExportChecksCSV(t *testing.T) {
+	ts := newTestServer(t)
+	token, site, checks := exportFixture(t, ts)
+
+	for _, path := range []string{fmt.Sprintf("/api/sites/%d/checks/export", site.ID), "/api/checks/export?format=csv"} {
+		rec := ts.request(t, http.MethodGet, path, token, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", path, rec.Code, rec.Body)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "text/csv" {
+			t.Errorf("%s: Content-Type = %q", path, ct)
+		}
+		if cd := rec.Header().Get("Content-Disposition"); !strings.Contains(cd, `.csv"`) {
+			t.Errorf("%s: Content-Disposition = %q", path, cd)
+		}
+
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: invalid CSV: %v", path, err)
+		}
+		want := [][]string{
+			{"id", "site_id", "status_code", "response_time_ms", "is_up", "checked_at"},
+			{"1", fmt.Sprint(site.ID), "200", "120", "true", checks[0].CheckedAt.UTC().Format(time.RFC3339)},
+			{"2", fmt.Sprint(site.ID), "503", "80", "false", checks[1].CheckedAt.UTC().Format(time.RFC3339)},
+		}
+		if fmt.Sprint(records) != fmt.Sprint(want) {
+			t.Errorf("%s: records = %v, want %v", path, records, want)
+		}
+	}
+}
+
+func TestExportChecksNDJSON(t *testing.T) {
+	ts := newTestServer(t)
+	token, site, checks := exportFixture(t, ts)
+
+	rec := ts.request(t, http.MethodGet, fmt.Sprintf("/api/sites/%d/checks/export?format=ndjson", site.ID), token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	var got []db.HealthCheck
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var hc db.HealthCheck
+		if err := json.Unmarshal(scanner.Bytes(), &hc); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		got = append(got, hc)
+	}
+	if len(got) != len(checks) {
+		t.Fatalf("got %d lines, want %d", len(got), len(checks))
+	}
+	for i, hc := range got {
+		if hc.StatusCode != checks[i].StatusCode || hc.IsUp != checks[i].IsUp || !hc.CheckedAt.Equal(checks[i].CheckedAt) {
+			t.Errorf("line %d = %+v, want %+v", i, hc, checks[i])
+		}
+	}
+}
+
+func TestExportChecksRange(t *testing.T) {
+	ts := newTestServer(t)
+	token, site, _ := exportFixture(t, ts)
+	path := fmt.Sprintf("/api/sites/%d/checks/export", site.ID)
+	now := time.Now().UTC()
+
+	rec := ts.request(t, http.MethodGet, path+"?from="+now.Format(time.RFC3339)+"&to="+now.Add(-time.Hour).Format(time.RFC3339), token, nil)
+	requireError(t, rec, http.StatusBadRequest, codeBadRequest)
+	rec = ts.request(t, http.MethodGet, path+"?from=yesterday", token, nil)
+	requireError(t, rec, http.StatusBadRequest, codeBadRequest)
+	rec = ts.request(t, http.MethodGet, path+"?format=xml", token, nil)
+	requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+
+	// Hanya check kedua yang berada di dalam rentang
+	from := now.Add(-time.Hour).Add(30 * time.Second).Format(time.RFC3339)
+	rec = ts.request(t, http.MethodGet, path+"?from="+from, token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1][2] != "503" {
+		t.Errorf("records = %v, want the header and the 503 check", records)
+	}
+}
+
+func TestExportEmptyRangeWritesHeader(t *testing.T) {
+	ts := newTestServer(t)
+	token, site, _ := exportFixture(t, ts)
+	from := time.Now().Add(-10 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	to := time.Now().Add(-9 * 24 * time.Hour).UTC().Format(time.RFC3339)
+
+	rec := ts.request(t, http.MethodGet, fmt.Sprintf("/api/sites/%d/checks/export?from=%s&to=%s", site.ID, from, to), token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("csv: status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("csv: Content-Type = %q", ct)
+	}
+	if body := rec.Body.String(); body != "id,site_id,status_code,response_time_ms,is_up,checked_at\n" {
+		t.Errorf("csv body = %q, want only the header row", body)
+	}
+
+	rec = ts.request(t, http.MethodGet, fmt.Sprintf("/api/checks/export?format=ndjson&from=%s&to=%s", from, to), token, nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/x-ndjson" || rec.Body.Len() != 0 {
+		t.Errorf("ndjson: status %d, Content-Type %q, body %q", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+}
+
+func TestExportSiteInOtherOrganization(t *testing.T) {
+	ts := newTestServer(t)
+	_, site, _ := exportFixture(t, ts)
+	bob := ts.createUser(t, "bob", true)
+	token := ts.login(t, bob.Email)
+
+	rec := ts.request(t, http.MethodGet, fmt.Sprintf("/api/sites/%d/checks/export", site.ID), token, nil)
+	requireError(t, rec, http.StatusNotFound, codeNotFound)
+	rec = ts.request(t, http.MethodGet, "/api/sites/999/checks/export", token, nil)
+	requireError(t, rec, http.StatusNotFound, codeNotFound)
+
+	// Ekspor semua check hanya berisi check milik organisasi bob
+	rec = ts.request(t, http.MethodGet, "/api/checks/export", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if records, err := csv.NewReader(rec.Body).ReadAll(); err != nil || len(records) != 1 {
+		t.Errorf("records = %v (%v), want only the header", records, err)
+	}
+}
+
+var errTestStream = errors.New("stream failed")
+
+// failingStreamStore gagal saat membuka query ekspor.
+type failingStreamStore struct {
+	db.Store
+}
+
+func (failingStreamStore) StreamHealthChecks(ctx context.Context, arg db.StreamHealthChecksParams, fn func(db.HealthCheck) error) error {
+	return errTestStream
+}
+
+func TestExportQueryErrorIsJSON(t *testing.T) {
+	ts := newTestServer(t)
+	token, _, _ := exportFixture(t, ts)
+	server := NewServer(failingStreamStore{ts.store}, ts.hub, ts.mailer)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/checks/export", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	requireError(t, rec, http.StatusInternalServerError, codeInternal)
+	if cd := rec.Header().Get("Content-Disposition"); cd != "" {
+		t.Errorf("Content-Disposition = %q, want none on error", cd)
+	}
+}
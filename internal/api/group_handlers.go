@@ -0,0 +1,313 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// Urutan keparahan status; nilai lebih besar berarti lebih buruk. Status grup adalah status terburuk dari anggotanya.
+var statusSeverity = map[string]int{
+	db.SiteStatusPaused:  0,
+	db.SiteStatusUp:      1,
+	db.SiteStatusUnknown: 2,
+	db.SiteStatusDown:    3,
+}
+
+type siteWithStatus struct {
+	db.Site
+	Status string `json:"status"`
+}
+
+type groupUptime struct {
+	TotalChecks   int64   `json:"total_checks"`
+	UpChecks      int64   `json:"up_checks"`
+	UptimePercent float64 `json:"uptime_percent"`
+}
+
+type groupResponse struct {
+	db.SiteGroup
+	Status string           `json:"status"`
+	Uptime groupUptime      `json:"uptime"`
+	Sites  []siteWithStatus `json:"sites,omitempty"`
+}
+
+// buildGroupResponses menghitung status terburuk dan uptime gabungan setiap grup dalam rentang window.
+func (server *Server) buildGroupResponses(ctx context.Context, orgID int64, groups []db.SiteGroup, window time.Duration, withSites bool) ([]groupResponse, error) {
+	sites, err := server.store.GetSitesByOrganizationID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	sitesByID := make(map[int64]db.Site, len(sites))
+	for _, site := range sites {
+		sitesByID[site.ID] = site
+	}
+
+	siteIDs := []int64{}
+	for _, g := range groups {
+		siteIDs = append(siteIDs, g.SiteIDs...)
+	}
+	latest, err := server.store.GetLatestHealthChecks(ctx, siteIDs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	uptimes, err := server.store.GetSitesUptime(ctx, siteIDs, now.Add(-window), now)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]groupResponse, 0, len(groups))
+	for _, g := range groups {
+		rsp := groupResponse{SiteGroup: g, Status: db.SiteStatusUnknown}
+		allPaused := len(g.SiteIDs) > 0
+		worst := db.SiteStatusPaused
+		for _, siteID := range g.SiteIDs {
+			site, ok := sitesByID[siteID]
+			if !ok {
+				continue
+			}
+			var lastIsUp *bool
+			if hc, ok := latest[siteID]; ok {
+				lastIsUp = &hc.IsUp
+			}
+			status := db.SiteStatusOf(site, lastIsUp)
+			if status != db.SiteStatusPaused {
+				allPaused = false
+			}
+			if statusSeverity[status] > statusSeverity[worst] {
+				worst = status
+			}
+
+			uptime := uptimes[siteID]
+			rsp.Uptime.TotalChecks += uptime.TotalChecks
+			rsp.Uptime.UpChecks += uptime.UpChecks
+
+			if withSites {
+				rsp.Sites = append(rsp.Sites, siteWithStatus{Site: site, Status: status})
+			}
+		}
+		if allPaused || worst != db.SiteStatusPaused {
+			rsp.Status = worst
+		}
+		if rsp.Uptime.TotalChecks > 0 {
+			rsp.Uptime.UptimePercent = float64(rsp.Uptime.UpChecks) / float64(rsp.Uptime.TotalChecks) * 100
+		}
+		responses = append(responses, rsp)
+	}
+	return responses, nil
+}
+
+// validateGroupSites memastikan semua site yang akan dimasukkan ke grup adalah site aktif milik organisasi.
+func (server *Server) validateGroupSites(ctx context.Context, orgID int64, siteIDs []int64) error {
+	sites, err := server.store.GetSitesByOrganizationID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	owned := make(map[int64]bool, len(sites))
+	for _, site := range sites {
+		owned[site.ID] = true
+	}
+	for _, id := range siteIDs {
+		if !owned[id] {
+			return fmt.Errorf("site %d not found", id)
+		}
+	}
+	return nil
+}
+
+type createGroupRequest struct {
+	Name    string  `json:"name" binding:"required,max=100"`
+	SiteIDs []int64 `json:"site_ids"`
+}
+
+func (server *Server) createGroup(ctx *gin.Context) {
+	var req createGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	if err := server.validateGroupSites(ctx, orgID, req.SiteIDs); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	arg := db.CreateSiteGroupParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+		Name:           req.Name,
+		SiteIDs:        req.SiteIDs,
+	}
+
+	group, err := server.store.CreateSiteGroup(ctx, arg)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditGroupCreate,
+		TargetType: auditTargetGroup,
+		TargetID:   group.ID,
+		Changes:    db.AuditDiff(nil, group),
+	})
+
+	ctx.JSON(http.StatusOK, group)
+}
+
+func (server *Server) listGroups(ctx *gin.Context) {
+	window, ok := uptimeRanges[ctx.DefaultQuery("range", "24h")]
+	if !ok {
+		respondError(ctx, http.StatusBadRequest, errors.New("range must be one of 24h, 7d or 30d"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	groups, err := server.store.ListSiteGroups(ctx, orgID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp, err := server.buildGroupResponses(ctx, orgID, groups, window, false)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) getGroup(ctx *gin.Context) {
+	groupID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid group ID"))
+		return
+	}
+
+	window, ok := uptimeRanges[ctx.DefaultQuery("range", "24h")]
+	if !ok {
+		respondError(ctx, http.StatusBadRequest, errors.New("range must be one of 24h, 7d or 30d"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	group, err := server.store.GetSiteGroup(ctx, groupID, orgID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("group not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp, err := server.buildGroupResponses(ctx, orgID, []db.SiteGroup{group}, window, true)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp[0])
+}
+
+type updateGroupRequest struct {
+	Name    *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	SiteIDs *[]int64 `json:"site_ids"`
+}
+
+func (server *Server) updateGroup(ctx *gin.Context) {
+	groupID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid group ID"))
+		return
+	}
+
+	var req updateGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	if req.SiteIDs != nil {
+		if err := server.validateGroupSites(ctx, orgID, *req.SiteIDs); err != nil {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	arg := db.UpdateSiteGroupParams{
+		Name:    req.Name,
+		SiteIDs: req.SiteIDs,
+	}
+
+	before, _ := server.store.GetSiteGroup(ctx, groupID, orgID)
+
+	group, err := server.store.UpdateSiteGroup(ctx, groupID, orgID, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("group not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditGroupUpdate,
+		TargetType: auditTargetGroup,
+		TargetID:   groupID,
+		Changes:    db.AuditDiff(before, group),
+	})
+
+	ctx.JSON(http.StatusOK, group)
+}
+
+func (server *Server) deleteGroup(ctx *gin.Context) {
+	groupID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid group ID"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	before, _ := server.store.GetSiteGroup(ctx, groupID, orgID)
+
+	if err := server.store.DeleteSiteGroup(ctx, groupID, orgID); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("group not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditGroupDelete,
+		TargetType: auditTargetGroup,
+		TargetID:   groupID,
+		Changes:    db.AuditDiff(before, nil),
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "group deleted successfully"})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+func TestGroupStatusAndUptime(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	var siteIDs []int64
+	for i := range 2 {
+		rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": fmt.Sprintf("https://example.com/%d", i)})
+		var site db.Site
+		decodeBody(t, rec, &site)
+		siteIDs = append(siteIDs, site.ID)
+	}
+
+	// Site pertama selalu up, site kedua turun pada pengecekan terakhir
+	now := time.Now()
+	_, err := ts.store.CreateHealthChecks(context.Background(), []db.HealthCheck{
+		{SiteID: siteIDs[0], StatusCode: 200, IsUp: true, CheckedAt: now.Add(-2 * time.Minute)},
+		{SiteID: siteIDs[0], StatusCode: 200, IsUp: true, CheckedAt: now.Add(-time.Minute)},
+		{SiteID: siteIDs[1], StatusCode: 200, IsUp: true, CheckedAt: now.Add(-2 * time.Minute)},
+		{SiteID: siteIDs[1], StatusCode: 500, IsUp: false, CheckedAt: now.Add(-time.Minute)},
+		// Di luar rentang 24 jam
+		{SiteID: siteIDs[1], StatusCode: 500, IsUp: false, CheckedAt: now.Add(-48 * time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("CreateHealthChecks: %v", err)
+	}
+
+	rec := ts.request(t, http.MethodPost, "/api/groups", token, gin.H{"name": "checkout", "site_ids": siteIDs})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create group: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = ts.request(t, http.MethodGet, "/api/groups", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list groups: status %d: %s", rec.Code, rec.Body)
+	}
+	var groups []groupResponse
+	decodeBody(t, rec, &groups)
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(groups))
+	}
+	g := groups[0]
+	if g.Status != db.SiteStatusDown {
+		t.Errorf("group status = %q, want worst-of %q", g.Status, db.SiteStatusDown)
+	}
+	if g.Uptime.TotalChecks != 4 || g.Uptime.UpChecks != 3 || g.Uptime.UptimePercent != 75 {
+		t.Errorf("group uptime = %+v, want 3 of 4 checks up", g.Uptime)
+	}
+}
+
+func TestCreateGroupRejectsForeignSites(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.createUser(t, "alice", true)
+	bob := ts.createUser(t, "bob", true)
+
+	rec := ts.request(t, http.MethodPost, "/api/sites", ts.login(t, alice.Email), gin.H{"url": "https://example.com"})
+	var site db.Site
+	decodeBody(t, rec, &site)
+
+	rec = ts.request(t, http.MethodPost, "/api/groups", ts.login(t, bob.Email), gin.H{"name": "stolen", "site_ids": []int64{site.ID}})
+	requireError(t, rec, http.StatusBadRequest, codeBadRequest)
+}
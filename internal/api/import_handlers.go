@@ -0,0 +1,193 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/importer"
+)
+
+// Status hasil impor per baris
+const (
+	importStatusCreated = "created"
+	importStatusSkipped = "skipped"
+	importStatusFailed  = "failed"
+)
+
+var errDuplicateImport = errors.New("url is already monitored or appears earlier in the import")
+
+// importSitesRequest dikirim sebagai multipart (dengan field file) atau JSON (dengan source_url).
+type importSitesRequest struct {
+	SourceURL string `form:"source_url" json:"source_url" binding:"omitempty,url"`
+	Format    string `form:"format" json:"format" binding:"omitempty,oneof=csv sitemap"`
+}
+
+// importRowResult adalah hasil satu baris. URL hanya diisi jika baris lolos validasi, agar isi sumber
+// yang tidak dikenali tidak dikembalikan apa adanya.
+type importRowResult struct {
+	Line   int    `json:"line"`
+	URL    string `json:"url,omitempty"`
+	Status string `json:"status"`
+	SiteID int64  `json:"site_id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type importSitesResponse struct {
+	Created int               `json:"created"`
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Results []importRowResult `json:"results"`
+}
+
+// importSites membuat banyak site sekaligus dari CSV atau sitemap.xml. Setiap baris divalidasi dengan aturan
+// yang sama seperti createSite; URL yang sudah dipantau user (atau muncul lebih dulu di file) dilewati.
+func (server *Server) importSites(ctx *gin.Context) {
+	// Sedikit kelonggaran di atas MaxBytes untuk overhead multipart
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, importer.MaxBytes+64<<10)
+
+	var req importSitesRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	data, err := server.readImportSource(ctx, req)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = importer.DetectFormat(data)
+	}
+	rows, err := importer.Parse(data, req.Format)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	sites, err := server.store.GetSitesByOrganizationID(ctx, orgID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	seen := make(map[string]bool, len(sites))
+	for _, site := range sites {
+		seen[site.URL] = true
+	}
+	remaining, err := server.remainingSites(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := importSitesResponse{Results: make([]importRowResult, 0, len(rows))}
+	for _, row := range rows {
+		site, url, err := server.importRow(ctx, userID, orgID, row, seen, &remaining)
+		result := importRowResult{Line: row.Line, URL: url}
+		switch {
+		case errors.Is(err, errDuplicateImport):
+			result.Status = importStatusSkipped
+			result.Error = err.Error()
+			rsp.Skipped++
+		case err != nil:
+			result.Status = importStatusFailed
+			result.Error = err.Error()
+			rsp.Failed++
+		default:
+			result.Status = importStatusCreated
+			result.SiteID = site.ID
+			rsp.Created++
+			server.recordAudit(ctx, db.CreateAuditEventParams{
+				Action:     auditSiteCreate,
+				TargetType: auditTargetSite,
+				TargetID:   site.ID,
+				Changes:    db.AuditDiff(nil, site),
+				Details:    map[string]any{"source": "import"},
+			})
+		}
+		rsp.Results = append(rsp.Results, result)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// readImportSource membaca file yang diunggah atau mengunduh source_url; tepat satu dari keduanya harus diisi.
+func (server *Server) readImportSource(ctx *gin.Context, req importSitesRequest) ([]byte, error) {
+	file, err := ctx.FormFile("file")
+	if err != nil && !errors.Is(err, http.ErrMissingFile) && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+	if (file == nil) == (req.SourceURL == "") {
+		return nil, errors.New("provide either an uploaded file or source_url")
+	}
+	if req.SourceURL != "" {
+		return importer.Fetch(ctx, req.SourceURL)
+	}
+
+	if file.Size > importer.MaxBytes {
+		return nil, fmt.Errorf("file is larger than %d bytes", importer.MaxBytes)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// importRow memvalidasi dan membuat site dari satu baris impor, lalu mengembalikan URL-nya jika valid.
+// remaining adalah sisa site yang boleh dibuat (-1 berarti tidak dibatasi) dan dikurangi setiap kali
+// site berhasil dibuat.
+func (server *Server) importRow(ctx *gin.Context, userID, orgID int64, row importer.Row, seen map[string]bool, remaining *int) (db.Site, string, error) {
+	if row.Err != nil {
+		return db.Site{}, "", row.Err
+	}
+	req := createSiteRequest{
+		URL:                  row.URL,
+		Name:                 row.Name,
+		CheckIntervalSeconds: row.CheckIntervalSeconds,
+		Tags:                 row.Tags,
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		return db.Site{}, "", err
+	}
+	tags, err := db.NormalizeTags(req.Tags)
+	if err != nil {
+		return db.Site{}, "", err
+	}
+	if seen[req.URL] {
+		return db.Site{}, req.URL, errDuplicateImport
+	}
+	if *remaining == 0 {
+		return db.Site{}, req.URL, errSiteLimitReached
+	}
+
+	site, err := createSiteWithTags(ctx, server.store, db.CreateSiteParams{
+		OrganizationID:       orgID,
+		UserID:               userID,
+		URL:                  req.URL,
+		Name:                 req.Name,
+		CheckIntervalSeconds: req.CheckIntervalSeconds,
+	}, tags)
+	if err != nil {
+		return db.Site{}, req.URL, err
+	}
+	seen[req.URL] = true
+	if *remaining > 0 {
+		*remaining--
+	}
+	return site, req.URL, nil
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// uploadImport mengirim file impor sebagai multipart ke /api/sites/import.
+func (ts *testServer) uploadImport(t *testing.T, token, content string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", "sites.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(content))
+	w.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/sites/import", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	ts.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestImportSitesReportsRowsWithoutEchoingInvalidSource(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	rec := ts.uploadImport(t, token, "url,name\nhttps://example.com,Example\ninternal secret value,leaked\nhttps://example.com,Again\n")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("import: status %d: %s", rec.Code, rec.Body)
+	}
+	var rsp importSitesResponse
+	decodeBody(t, rec, &rsp)
+	if rsp.Created != 1 || rsp.Failed != 1 || rsp.Skipped != 1 {
+		t.Fatalf("import counts = %+v", rsp)
+	}
+	if rsp.Results[0].URL != "https://example.com" || rsp.Results[2].URL != "https://example.com" {
+		t.Errorf("valid rows do not report their url: %+v", rsp.Results)
+	}
+	if rsp.Results[1].URL != "" || strings.Contains(rec.Body.String(), "internal secret") {
+		t.Errorf("invalid row echoes source text: %s", rec.Body)
+	}
+}
+
+func TestImportSitesRejectsInternalSourceURL(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("import fetched a loopback address")
+	}))
+	defer srv.Close()
+
+	rec := ts.request(t, http.MethodPost, "/api/sites/import", token, gin.H{"source_url": srv.URL})
+	requireError(t, rec, http.StatusBadRequest, codeBadRequest)
+}
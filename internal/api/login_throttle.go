@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Penguncian login per akun: setelah loginLockThreshold kegagalan berturut-turut, login dikunci selama
+// loginLockBase, dan lama penguncian berlipat dua untuk setiap kegagalan berikutnya sampai loginLockMax.
+const (
+	loginLockThreshold = 5
+	loginLockBase      = time.Minute
+	loginLockMax       = time.Hour
+	// Hitungan login gagal dimulai ulang setelah sekian lama tanpa kegagalan
+	loginFailureWindow = 24 * time.Hour
+)
+
+var (
+	// Pesan yang sama untuk email tidak terdaftar dan password salah
+	errInvalidCredentials = newAPIError(codeInvalidCredentials, "invalid email or password")
+	errLoginLocked        = newAPIError(codeLoginLocked, "too many failed login attempts, please try again later")
+)
+
+// dummyPasswordHash dibandingkan dengan password jika email tidak terdaftar, agar waktu respons sama dengan
+// login yang gagal karena password salah.
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("go-pulse-dummy-password"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+	return hash
+})
+
+// loginThrottleKey menormalkan email agar variasi huruf besar dan spasi dihitung sebagai akun yang sama.
+func loginThrottleKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// loginLockDuration mengembalikan lama penguncian setelah sejumlah kegagalan berturut-turut, atau 0 jika
+// batasnya belum tercapai.
+func loginLockDuration(failures int) time.Duration {
+	if failures < loginLockThreshold {
+		return 0
+	}
+	shift := failures - loginLockThreshold
+	if shift >= 6 {
+		return loginLockMax
+	}
+	return min(loginLockBase<<shift, loginLockMax)
+}
+
+// checkLoginLock mengirim 429 dan mengembalikan true jika login untuk email sedang dikunci.
+func (server *Server) checkLoginLock(ctx *gin.Context, email string) bool {
+	throttle, err := server.store.GetLoginThrottle(ctx, loginThrottleKey(email))
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return false
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return true
+	}
+	now := time.Now()
+	if !throttle.Locked(now) {
+		return false
+	}
+	setRetryAfter(ctx, throttle.LockedUntil.Sub(now))
+	respondError(ctx, http.StatusTooManyRequests, errLoginLocked)
+	return true
+}
+
+// recordLoginFailure menambah hitungan login gagal untuk email dan mengunci login jika batasnya tercapai.
+// Pemilik akun diberi tahu lewat email saat penguncian dimulai; user nil jika email tidak terdaftar.
+func (server *Server) recordLoginFailure(ctx *gin.Context, email string, user *db.User) {
+	throttle, err := server.store.RecordLoginFailure(ctx, db.RecordLoginFailureParams{
+		Email:       loginThrottleKey(email),
+		ResetBefore: time.Now().Add(-loginFailureWindow),
+	})
+	if err != nil {
+		log.Printf("Failed to record login failure: %v", err)
+		return
+	}
+
+	lockFor := loginLockDuration(throttle.Failures)
+	if lockFor == 0 {
+		return
+	}
+	until := time.Now().Add(lockFor)
+	if err := server.store.LockLogin(ctx, throttle.Email, until); err != nil {
+		log.Printf("Failed to lock login: %v", err)
+		return
+	}
+
+	event := db.CreateAuditEventParams{
+		Action:     auditLoginLocked,
+		TargetType: auditTargetUser,
+		Details:    map[string]any{"email": email, "failures": throttle.Failures, "locked_until": until},
+	}
+	if user != nil {
+		event.UserID, event.TargetID = user.ID, user.ID
+	}
+	server.recordAudit(ctx, event)
+
+	if user != nil && throttle.Failures == loginLockThreshold {
+		server.sendLoginLockedEmail(*user, ctx.ClientIP(), until)
+	}
+}
+
+// sendLoginLockedEmail memberi tahu pemilik akun bahwa login dikunci karena terlalu banyak percobaan gagal.
+func (server *Server) sendLoginLockedEmail(user db.User, ipAddress string, until time.Time) {
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Sign-in to your Go-Pulse account was temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe blocked sign-in to your Go-Pulse account after %d failed attempts, "+
+			"the latest from IP address %s. You can sign in again after %s.\n\n"+
+			"If this was not you, someone may be trying to guess your password. "+
+			"Consider resetting your password from the sign-in page and enabling two-factor authentication:\n\n%s\n",
+			user.Username, loginLockThreshold, ipAddress, until.UTC().Format(time.RFC1123), appBaseURL()+"/login"),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Login lockout: failed to send email to user %d: %v", user.ID, err)
+		}
+	}()
+}
+
+// resetLoginFailures menghapus hitungan login gagal setelah user berhasil login atau mengganti password.
+func (server *Server) resetLoginFailures(ctx context.Context, email string) {
+	if err := server.store.ResetLoginFailures(ctx, loginThrottleKey(email)); err != nil {
+		log.Printf("Failed to reset login failures: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/metrics"
+)
+
+func TestMetricsEndpoint(t *testing.T) {
+	metrics.ObserveCheck(1, "https://example.com", true, http.StatusOK, 120*time.Millisecond)
+
+	tests := []struct {
+		name       string
+		envToken   string
+		authHeader string
+		status     int
+	}{
+		{name: "token not configured", status: http.StatusOK},
+		{name: "missing token", envToken: "metrics-secret", status: http.StatusUnauthorized},
+		{name: "wrong token", envToken: "metrics-secret", authHeader: "Bearer wrong", status: http.StatusUnauthorized},
+		{name: "wrong scheme", envToken: "metrics-secret", authHeader: "Basic metrics-secret", status: http.StatusUnauthorized},
+		{name: "correct token", envToken: "metrics-secret", authHeader: "Bearer metrics-secret", status: http.StatusOK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("METRICS_TOKEN", tc.envToken)
+			ts := newTestServer(t)
+
+			var headers []string
+			if tc.authHeader != "" {
+				headers = []string{authorizationHeaderKey, tc.authHeader}
+			}
+			rec := ts.request(t, http.MethodGet, "/metrics", "", nil, headers...)
+			if tc.status != http.StatusOK {
+				requireError(t, rec, tc.status, codeUnauthorized)
+				return
+			}
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body)
+			}
+			body := rec.Body.String()
+			for _, name := range []string{
+				"gopulse_site_up",
+				"gopulse_site_status_code",
+				"gopulse_site_response_time_seconds",
+				"gopulse_site_checks_total",
+				"gopulse_check_cycle_duration_seconds",
+				"gopulse_worker_queue_depth",
+				"gopulse_db_write_errors_total",
+				"go_goroutines",
+			} {
+				if !strings.Contains(body, "\n"+name) {
+					t.Errorf("metrics output is missing %s", name)
+				}
+			}
+		})
+	}
+}
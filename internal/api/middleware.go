@@ -1,71 +1,236 @@
 package api
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
 )
 
 const (
 	authorizationHeaderKey = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	// authorizationSessionKey menyimpan ID session dari access token
+	authorizationSessionKey = "authorization_session"
+	// authorizationAPIKeyKey menyimpan db.APIKey jika request diautentikasi dengan API key
+	authorizationAPIKeyKey = "authorization_api_key"
+	// authorizationOrganizationKey menyimpan ID organisasi yang diakses request, diisi oleh requireRole
+	authorizationOrganizationKey = "authorization_organization"
+	// authorizationRoleKey menyimpan role user di organisasi tersebut
+	authorizationRoleKey = "authorization_role"
 )
 
-func authMiddleware() gin.HandlerFunc {
+// organizationHeaderKey memilih organisasi yang diakses; jika kosong dipakai organisasi pertama user
+const organizationHeaderKey = "X-Organization-ID"
+
+var errNoOrganization = newAPIError(codeNoOrganization, "you are not a member of any organization")
+
+// authMiddleware memvalidasi access token (JWT) atau API key. Untuk JWT, session-nya harus belum dicabut;
+// untuk API key, scope-nya diperiksa per rute oleh requireScope.
+func (server *Server) authMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
 			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
 			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		authType := strings.ToLower(fields[0])
 		if authType != authorizationTypeBearer {
 			err := errors.New("unsupported authorization type")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		accessToken := fields[1]
-		secretKey := os.Getenv("JWT_SECRET")
-
-		token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return []byte(secretKey), nil
-		})
+		if strings.HasPrefix(accessToken, apiKeyPrefix) {
+			server.authenticateAPIKey(ctx, accessToken)
+			return
+		}
 
+		session, err := server.verifyAccessToken(ctx, accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, http.StatusUnauthorized, err)
 			return
 		}
+		// Simpan userID di context agar bisa diakses oleh handler selanjutnya
+		ctx.Set(authorizationPayloadKey, session.UserID)
+		ctx.Set(authorizationSessionKey, session.ID)
+		ctx.Next()
+	}
+}
+
+// verifyAccessToken memvalidasi access token (JWT) dan mengembalikan session-nya. Token dari session
+// yang sudah dicabut (logout) langsung ditolak walaupun belum kedaluwarsa.
+func (server *Server) verifyAccessToken(ctx context.Context, accessToken string) (db.Session, error) {
+	secretKey := os.Getenv("JWT_SECRET")
+
+	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(secretKey), nil
+	})
+	if err != nil {
+		return db.Session{}, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return db.Session{}, errors.New("invalid token")
+	}
+	// Mengambil User ID dari 'sub' claim; token tanpa sid (mis. token verifikasi email) bukan access token
+	userIDFloat, ok := claims["sub"].(float64)
+	sessionIDFloat, hasSession := claims["sid"].(float64)
+	if !ok || !hasSession {
+		return db.Session{}, errors.New("invalid token claims")
+	}
+	session, err := server.store.GetSession(ctx, int64(sessionIDFloat))
+	if err != nil || session.UserID != int64(userIDFloat) || !session.Active(time.Now()) {
+		return db.Session{}, errors.New("session has been revoked or expired")
+	}
+	return session, nil
+}
+
+// authenticateAPIKey memvalidasi API key dan menyimpan pemilik serta key-nya di context.
+func (server *Server) authenticateAPIKey(ctx *gin.Context, key string) {
+	apiKey, err := server.store.GetAPIKeyByHash(ctx, hashToken(key))
+	if err != nil || !apiKey.Active(time.Now()) {
+		abortWithError(ctx, http.StatusUnauthorized, errors.New("invalid, revoked or expired API key"))
+		return
+	}
+	if err := server.store.TouchAPIKey(ctx, apiKey.ID); err != nil {
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Set(authorizationPayloadKey, apiKey.UserID)
+	ctx.Set(authorizationAPIKeyKey, apiKey)
+	ctx.Next()
+}
+
+// requireScope menolak request dengan API key yang tidak memiliki scope tersebut.
+// Request dengan JWT selalu diizinkan karena mewakili user itu sendiri.
+func requireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if value, ok := ctx.Get(authorizationAPIKeyKey); ok && !value.(db.APIKey).HasScope(scope) {
+			abortWithError(ctx, http.StatusForbidden, fmt.Errorf("API key is missing the %s scope", scope))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// requireSession hanya mengizinkan request dengan JWT, mis. untuk mengelola session dan API key.
+func requireSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, ok := ctx.Get(authorizationAPIKeyKey); ok {
+			abortWithError(ctx, http.StatusForbidden, errors.New("this endpoint cannot be used with an API key"))
+			return
+		}
+		ctx.Next()
+	}
+}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Mengambil User ID dari 'sub' claim
-			userIDFloat, ok := claims["sub"].(float64)
-			if !ok {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("invalid token claims")))
+// requireRole menentukan organisasi yang diakses request (header X-Organization-ID) dan menolak request
+// jika role user di organisasi tersebut lebih rendah dari role yang dibutuhkan.
+func (server *Server) requireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID := ctx.GetInt64(authorizationPayloadKey)
+
+		var orgID int64
+		if header := ctx.GetHeader(organizationHeaderKey); header != "" {
+			id, err := strconv.ParseInt(header, 10, 64)
+			if err != nil {
+				abortWithError(ctx, http.StatusBadRequest, fmt.Errorf("invalid %s header", organizationHeaderKey))
 				return
 			}
-			// Simpan userID di context agar bisa diakses oleh handler selanjutnya
-			ctx.Set(authorizationPayloadKey, int64(userIDFloat))
-			ctx.Next()
+			orgID = id
 		} else {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errors.New("invalid token")))
+			orgs, err := server.store.ListUserOrganizations(ctx, userID)
+			if err != nil {
+				abortWithError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+			if len(orgs) == 0 {
+				abortWithError(ctx, http.StatusForbidden, errNoOrganization)
+				return
+			}
+			orgID = orgs[0].ID
+		}
+
+		server.authorizeRole(ctx, orgID, role)
+	}
+}
+
+// requireOrganizationRole sama dengan requireRole, tetapi organisasinya diambil dari parameter :id pada path.
+func (server *Server) requireOrganizationRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		orgID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+		if err != nil {
+			abortWithError(ctx, http.StatusBadRequest, errors.New("invalid organization ID"))
+			return
+		}
+		server.authorizeRole(ctx, orgID, role)
+	}
+}
+
+// authorizeRole memeriksa keanggotaan user di organisasi lalu menyimpan organisasi dan role-nya di context.
+// Organisasi yang bukan milik user dilaporkan sebagai tidak ditemukan.
+func (server *Server) authorizeRole(ctx *gin.Context, orgID int64, role string) {
+	member, err := server.store.GetOrganizationMember(ctx, orgID, ctx.GetInt64(authorizationPayloadKey))
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			abortWithError(ctx, http.StatusNotFound, errors.New("organization not found"))
+			return
+		}
+		abortWithError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !db.RoleAtLeast(member.Role, role) {
+		abortWithError(ctx, http.StatusForbidden, fmt.Errorf("this action requires the %s role or higher", role))
+		return
+	}
+
+	ctx.Set(authorizationOrganizationKey, orgID)
+	ctx.Set(authorizationRoleKey, member.Role)
+	ctx.Next()
+}
+
+// metricsAuthMiddleware melindungi endpoint /metrics dengan bearer token statis dari METRICS_TOKEN.
+// Jika METRICS_TOKEN kosong, endpoint dapat diakses tanpa token.
+func metricsAuthMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		expectedToken := os.Getenv("METRICS_TOKEN")
+		if expectedToken == "" {
+			ctx.Next()
+			return
+		}
+
+		fields := strings.Fields(ctx.GetHeader(authorizationHeaderKey))
+		if len(fields) != 2 || strings.ToLower(fields[0]) != authorizationTypeBearer ||
+			subtle.ConstantTimeCompare([]byte(fields[1]), []byte(expectedToken)) != 1 {
+			abortWithError(ctx, http.StatusUnauthorized, errors.New("invalid metrics token"))
+			return
 		}
+		ctx.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/oidc"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cookie yang menyimpan state, nonce dan PKCE verifier selama user login di identity provider
+const oidcCookieName = "gp_oidc"
+
+var (
+	errSSONotConfigured    = newAPIError(codeSSONotConfigured, "single sign-on is not configured")
+	errSSOEmailNotVerified = errors.New("the identity provider did not return a verified email address")
+	errSSOAccountNotLinked = errors.New("an account with this email already exists, sign in with your password " +
+		"and verify your email address before using single sign-on")
+)
+
+// EnableOIDC mengaktifkan login SSO lewat identity provider OIDC.
+func (server *Server) EnableOIDC(provider *oidc.Provider) {
+	server.oidc = provider
+}
+
+// oidcLogin mengarahkan browser ke halaman login identity provider (authorization code flow dengan PKCE).
+func (server *Server) oidcLogin(ctx *gin.Context) {
+	if server.oidc == nil {
+		respondError(ctx, http.StatusNotFound, errSSONotConfigured)
+		return
+	}
+
+	state, _, err := newOpaqueToken()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	nonce, _, err := newOpaqueToken()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	authURL, err := server.oidc.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		respondError(ctx, http.StatusBadGateway, err)
+		return
+	}
+
+	// Verifier tidak boleh ikut terkirim lewat URL, jadi disimpan di cookie bertanda tangan yang hanya dibaca server
+	cookie, err := generatePurposeToken(oidcLoginPurpose, 0, jwt.MapClaims{
+		"state":    state,
+		"nonce":    nonce,
+		"verifier": verifier,
+	}, oidcLoginTTL)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcCookieName, cookie, int(oidcLoginTTL.Seconds()), "/api/auth/oidc", "", ctx.Request.TLS != nil, true)
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// oidcCallback menerima authorization code dari identity provider, mencari atau membuat user, lalu
+// mengarahkan browser kembali ke frontend dengan token yang sama seperti loginUser di fragment URL.
+func (server *Server) oidcCallback(ctx *gin.Context) {
+	if server.oidc == nil {
+		respondError(ctx, http.StatusNotFound, errSSONotConfigured)
+		return
+	}
+
+	cookie, cookieErr := ctx.Cookie(oidcCookieName)
+	ctx.SetSameSite(http.SameSiteLaxMode)
+	ctx.SetCookie(oidcCookieName, "", -1, "/api/auth/oidc", "", ctx.Request.TLS != nil, true)
+
+	if idpErr := ctx.Query("error"); idpErr != "" {
+		server.redirectSSOResult(ctx, url.Values{"error": {idpErr}})
+		return
+	}
+	if cookieErr != nil {
+		server.redirectSSOResult(ctx, url.Values{"error": {"login session expired, please try again"}})
+		return
+	}
+	_, loginState, err := parsePurposeToken(cookie, oidcLoginPurpose)
+	state, _ := loginState["state"].(string)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(ctx.Query("state"))) != 1 {
+		server.redirectSSOResult(ctx, url.Values{"error": {"invalid login state, please try again"}})
+		return
+	}
+	nonce, _ := loginState["nonce"].(string)
+	verifier, _ := loginState["verifier"].(string)
+
+	claims, err := server.oidc.Exchange(ctx, ctx.Query("code"), verifier, nonce)
+	if err != nil {
+		log.Printf("OIDC login failed: %v", err)
+		server.redirectSSOResult(ctx, url.Values{"error": {"could not verify the identity provider response"}})
+		return
+	}
+
+	user, err := server.userForOIDCClaims(ctx, claims)
+	if err != nil {
+		if !errors.Is(err, errSSOEmailNotVerified) && !errors.Is(err, errSSOAccountNotLinked) {
+			log.Printf("OIDC login failed for subject %q: %v", claims.Subject, err)
+			err = errors.New("could not sign in with single sign-on")
+		}
+		server.redirectSSOResult(ctx, url.Values{"error": {err.Error()}})
+		return
+	}
+
+	// 2FA tetap berlaku untuk login lewat SSO
+	enrollment, err := server.store.GetTOTP(ctx, user.ID)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		log.Printf("OIDC login failed for user %d: %v", user.ID, err)
+		server.redirectSSOResult(ctx, url.Values{"error": {"could not sign in with single sign-on"}})
+		return
+	}
+	if err == nil && enrollment.Enabled() {
+		challenge, err := newTwoFactorChallenge(user.ID)
+		if err != nil {
+			server.redirectSSOResult(ctx, url.Values{"error": {"could not sign in with single sign-on"}})
+			return
+		}
+		server.redirectSSOResult(ctx, url.Values{
+			"two_factor_required":  {"true"},
+			"challenge_token":      {challenge.ChallengeToken},
+			"challenge_expires_at": {challenge.ChallengeExpiresAt.Format(time.RFC3339)},
+		})
+		return
+	}
+
+	rsp, err := server.startSession(ctx, user)
+	if err != nil {
+		log.Printf("OIDC login failed for user %d: %v", user.ID, err)
+		server.redirectSSOResult(ctx, url.Values{"error": {"could not sign in with single sign-on"}})
+		return
+	}
+	server.redirectSSOResult(ctx, url.Values{
+		"token":                    {rsp.Token},
+		"refresh_token":            {rsp.RefreshToken},
+		"access_token_expires_at":  {rsp.AccessTokenExpiresAt.Format(time.RFC3339)},
+		"refresh_token_expires_at": {rsp.RefreshTokenExpiresAt.Format(time.RFC3339)},
+	})
+}
+
+// userForOIDCClaims mencari user yang ditautkan ke akun identity provider. Jika belum ada, akun ditautkan ke
+// user dengan email yang sama, atau user baru dibuat; keduanya hanya jika email-nya sudah diverifikasi provider.
+// User lokal hanya ditautkan jika email-nya juga sudah diverifikasi di Go-Pulse, karena siapa pun dapat
+// mendaftar dengan email orang lain lalu menunggu pemiliknya login lewat SSO.
+func (server *Server) userForOIDCClaims(ctx *gin.Context, claims oidc.Claims) (db.User, error) {
+	var user db.User
+	created := false
+	err := server.store.ExecTx(ctx, func(store db.Store) error {
+		var err error
+		user, err = store.GetUserByIdentity(ctx, claims.Issuer, claims.Subject)
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			return err
+		}
+		if claims.Email == "" || !claims.EmailVerified {
+			return errSSOEmailNotVerified
+		}
+
+		user, err = store.GetUserByEmail(ctx, claims.Email)
+		if errors.Is(err, db.ErrRecordNotFound) {
+			// User baru tidak memiliki password yang diketahui; password dapat diatur lewat reset password
+			password, _, err := newOpaqueToken()
+			if err != nil {
+				return err
+			}
+			hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+			if err != nil {
+				return err
+			}
+			user, err = createUser(ctx, store, db.CreateUserParams{
+				Username:     ssoUsername(claims),
+				Email:        claims.Email,
+				PasswordHash: string(hashedPassword),
+			})
+			if err != nil {
+				return err
+			}
+			// Email sudah diverifikasi identity provider
+			if user, err = store.VerifyUserEmail(ctx, user.ID, user.Email); err != nil {
+				return err
+			}
+			created = true
+		} else if err != nil {
+			return err
+		} else if !user.Verified() {
+			return errSSOAccountNotLinked
+		}
+
+		_, err = store.CreateUserIdentity(ctx, db.CreateUserIdentityParams{
+			UserID:  user.ID,
+			Issuer:  claims.Issuer,
+			Subject: claims.Subject,
+			Email:   claims.Email,
+		})
+		return err
+	})
+	if err == nil && created {
+		server.recordAudit(ctx, db.CreateAuditEventParams{
+			UserID:     user.ID,
+			ActorID:    user.ID,
+			Action:     auditUserRegister,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+			Changes:    db.AuditDiff(nil, user),
+			Details:    map[string]any{"issuer": claims.Issuer},
+		})
+	}
+	return user, err
+}
+
+// ssoUsername membuat username alfanumerik dari preferred_username atau bagian lokal email.
+func ssoUsername(claims oidc.Claims) string {
+	source := claims.PreferredUsername
+	if source == "" {
+		source, _, _ = strings.Cut(claims.Email, "@")
+	}
+	username := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+		return -1
+	}, source)
+	if username == "" {
+		return "user"
+	}
+	return username[:min(len(username), 50)]
+}
+
+// redirectSSOResult mengarahkan browser ke halaman frontend /sso/callback. Hasil login dikirim di fragment
+// agar token tidak ikut terkirim ke server atau tercatat di log.
+func (server *Server) redirectSSOResult(ctx *gin.Context, values url.Values) {
+	ctx.Redirect(http.StatusFound, appBaseURL()+"/sso/callback#"+values.Encode())
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tajri15/go-pulse-monitoring/internal/oidc"
+	"github.com/tajri15/go-pulse-monitoring/internal/oidc/oidctest"
+)
+
+// newOIDCTestServer membuat testServer dengan SSO yang diarahkan ke mock issuer.
+func newOIDCTestServer(t *testing.T) (*testServer, *oidctest.Issuer) {
+	t.Helper()
+	t.Setenv("APP_BASE_URL", "http://app.example.com")
+	ts := newTestServer(t)
+	issuer := oidctest.NewIssuer(t, "go-pulse", "secret")
+	ts.EnableOIDC(oidc.New(oidc.Config{
+		IssuerURL:    issuer.URL,
+		ClientID:     issuer.ClientID,
+		ClientSecret: issuer.ClientSecret,
+		RedirectURL:  "http://localhost:8080/api/auth/oidc/callback",
+	}))
+	return ts, issuer
+}
+
+// ssoLogin menjalankan login SSO lengkap dan mengembalikan isi fragment dari redirect ke /sso/callback.
+// tamper dapat mengubah URL callback sebelum dikirim, mis. untuk mengganti state.
+func (ts *testServer) ssoLogin(t *testing.T, issuer *oidctest.Issuer, tamper func(*url.URL)) url.Values {
+	t.Helper()
+	rec := ts.request(t, http.MethodGet, "/api/auth/oidc/login", "", nil)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("oidc login: status %d: %s", rec.Code, rec.Body)
+	}
+	var cookie string
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == oidcCookieName {
+			cookie = c.Name + "=" + c.Value
+		}
+	}
+	if cookie == "" {
+		t.Fatal("oidc login did not set the login state cookie")
+	}
+	if strings.Contains(rec.Header().Get("Location"), "verifier") {
+		t.Error("PKCE verifier leaked into the authorization URL")
+	}
+
+	callback, err := issuer.Authorize(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if tamper != nil {
+		tamper(callback)
+	}
+	rec = ts.request(t, http.MethodGet, callback.RequestURI(), "", nil, "Cookie", cookie)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("oidc callback: status %d: %s", rec.Code, rec.Body)
+	}
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.Host != "app.example.com" || location.Path != "/sso/callback" || location.RawQuery != "" {
+		t.Fatalf("callback redirected to %s", location)
+	}
+	result, err := url.ParseQuery(location.Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestSSOLoginCreatesVerifiedUser(t *testing.T) {
+	ts, issuer := newOIDCTestServer(t)
+	issuer.SetClaims(jwt.MapClaims{"sub": "idp-1", "email": "carol@example.com", "email_verified": true, "preferred_username": "carol.c"})
+
+	result := ts.ssoLogin(t, issuer, nil)
+	if result.Get("error") != "" || result.Get("token") == "" || result.Get("refresh_token") == "" {
+		t.Fatalf("sso result = %v, want tokens", result)
+	}
+	if rec := ts.request(t, http.MethodGet, "/api/sites", result.Get("token"), nil); rec.Code != http.StatusOK {
+		t.Errorf("token from SSO rejected: status %d: %s", rec.Code, rec.Body)
+	}
+
+	user, err := ts.store.GetUserByEmail(context.Background(), "carol@example.com")
+	if err != nil {
+		t.Fatalf("GetUserByEmail: %v", err)
+	}
+	if !user.Verified() || user.Username != "carolc" {
+		t.Errorf("created user = %+v, want verified user carolc", user)
+	}
+
+	// Login berikutnya memakai identitas yang sudah ditautkan walaupun email di provider berubah
+	issuer.SetClaims(jwt.MapClaims{"sub": "idp-1", "email": "carol@new.example.com", "email_verified": true})
+	result = ts.ssoLogin(t, issuer, nil)
+	if result.Get("token") == "" {
+		t.Fatalf("second sso result = %v, want tokens", result)
+	}
+	if _, err := ts.store.GetUserByEmail(context.Background(), "carol@new.example.com"); err == nil {
+		t.Error("second login created a new user instead of using the linked identity")
+	}
+}
+
+func TestSSOLoginLinksOnlyVerifiedLocalAccounts(t *testing.T) {
+	ts, issuer := newOIDCTestServer(t)
+	unverified := ts.createUser(t, "alice", false)
+	verified := ts.createUser(t, "bob", true)
+
+	issuer.SetClaims(jwt.MapClaims{"sub": "idp-alice", "email": unverified.Email, "email_verified": true})
+	result := ts.ssoLogin(t, issuer, nil)
+	if result.Get("token") != "" || result.Get("error") != errSSOAccountNotLinked.Error() {
+		t.Fatalf("sso result for unverified local account = %v, want %q", result, errSSOAccountNotLinked)
+	}
+	if _, err := ts.store.GetUserByIdentity(context.Background(), issuer.URL, "idp-alice"); err == nil {
+		t.Error("identity was linked to an unverified local account")
+	}
+
+	issuer.SetClaims(jwt.MapClaims{"sub": "idp-bob", "email": verified.Email, "email_verified": true})
+	result = ts.ssoLogin(t, issuer, nil)
+	if result.Get("token") == "" {
+		t.Fatalf("sso result for verified local account = %v, want tokens", result)
+	}
+	linked, err := ts.store.GetUserByIdentity(context.Background(), issuer.URL, "idp-bob")
+	if err != nil || linked.ID != verified.ID {
+		t.Errorf("identity linked to user %d (%v), want %d", linked.ID, err, verified.ID)
+	}
+}
+
+func TestSSOLoginRejectsUnverifiedProviderEmail(t *testing.T) {
+	ts, issuer := newOIDCTestServer(t)
+	issuer.SetClaims(jwt.MapClaims{"sub": "idp-1", "email": "carol@example.com", "email_verified": false})
+
+	result := ts.ssoLogin(t, issuer, nil)
+	if result.Get("error") != errSSOEmailNotVerified.Error() {
+		t.Fatalf("sso result = %v, want %q", result, errSSOEmailNotVerified)
+	}
+}
+
+func TestSSOCallbackRejectsWrongState(t *testing.T) {
+	ts, issuer := newOIDCTestServer(t)
+	issuer.SetClaims(jwt.MapClaims{"sub": "idp-1", "email": "carol@example.com", "email_verified": true})
+
+	result := ts.ssoLogin(t, issuer, func(callback *url.URL) {
+		q := callback.Query()
+		q.Set("state", "forged")
+		callback.RawQuery = q.Encode()
+	})
+	if result.Get("token") != "" || !strings.Contains(result.Get("error"), "invalid login state") {
+		t.Fatalf("sso result = %v, want invalid state error", result)
+	}
+}
+
+func TestSSOLoginRequiresTwoFactor(t *testing.T) {
+	ts, issuer := newOIDCTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	ts.enableTwoFactor(t, ts.login(t, user.Email))
+	issuer.SetClaims(jwt.MapClaims{"sub": "idp-alice", "email": user.Email, "email_verified": true})
+
+	result := ts.ssoLogin(t, issuer, nil)
+	if result.Get("token") != "" || result.Get("two_factor_required") != "true" || result.Get("challenge_token") == "" {
+		t.Fatalf("sso result = %v, want a two-factor challenge", result)
+	}
+}
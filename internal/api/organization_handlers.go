@@ -0,0 +1,347 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+var (
+	errLastOwner          = newAPIError(codeLastOwner, "an organization must keep at least one owner")
+	errOwnerRoleRequired  = newAPIError(codeOwnerRoleRequired, "only owners can grant the owner role or change another owner")
+	errMemberAlreadyAdded = newAPIError(codeAlreadyMember, "user is already a member of the organization")
+)
+
+// createUser membuat user beserta organisasi pribadinya, dengan user sebagai owner, dalam satu transaksi.
+func createUser(ctx context.Context, store db.Store, arg db.CreateUserParams) (db.User, error) {
+	var user db.User
+	err := store.ExecTx(ctx, func(tx db.Store) error {
+		var err error
+		user, err = tx.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		_, err = tx.CreateOrganization(ctx, user.Username, user.ID)
+		return err
+	})
+	return user, err
+}
+
+type organizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// listOrganizations mengembalikan organisasi tempat user menjadi anggota beserta role-nya. Organisasi pertama
+// dipakai jika request tidak menyertakan header X-Organization-ID.
+func (server *Server) listOrganizations(ctx *gin.Context) {
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	orgs, err := server.store.ListUserOrganizations(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orgs)
+}
+
+func (server *Server) createOrganization(ctx *gin.Context) {
+	var req organizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	org, err := server.store.CreateOrganization(ctx, req.Name, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		OrganizationID: org.ID,
+		Action:         auditOrganizationCreate,
+		TargetType:     auditTargetOrganization,
+		TargetID:       org.ID,
+		Changes:        db.AuditDiff(nil, org),
+	})
+
+	ctx.JSON(http.StatusOK, db.UserOrganization{Organization: org, Role: db.RoleOwner})
+}
+
+func (server *Server) getOrganization(ctx *gin.Context) {
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	org, err := server.store.GetOrganization(ctx, orgID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, db.UserOrganization{Organization: org, Role: ctx.GetString(authorizationRoleKey)})
+}
+
+func (server *Server) updateOrganization(ctx *gin.Context) {
+	var req organizationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	before, _ := server.store.GetOrganization(ctx, orgID)
+
+	org, err := server.store.UpdateOrganization(ctx, orgID, req.Name)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditOrganizationUpdate,
+		TargetType: auditTargetOrganization,
+		TargetID:   orgID,
+		Changes:    db.AuditDiff(before, org),
+	})
+
+	ctx.JSON(http.StatusOK, db.UserOrganization{Organization: org, Role: ctx.GetString(authorizationRoleKey)})
+}
+
+// deleteOrganization menghapus organisasi yang sudah tidak memiliki site; site harus dihapus dan di-purge lebih dulu.
+func (server *Server) deleteOrganization(ctx *gin.Context) {
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	before, _ := server.store.GetOrganization(ctx, orgID)
+
+	if err := server.store.DeleteOrganization(ctx, orgID); err != nil {
+		if errors.Is(err, db.ErrOrganizationHasSites) {
+			respondError(ctx, http.StatusConflict, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditOrganizationDelete,
+		TargetType: auditTargetOrganization,
+		TargetID:   orgID,
+		Changes:    db.AuditDiff(before, nil),
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "organization deleted successfully"})
+}
+
+func (server *Server) listOrganizationMembers(ctx *gin.Context) {
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	members, err := server.store.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+type addOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner admin editor viewer"`
+}
+
+// addOrganizationMember menambahkan user yang sudah terdaftar ke organisasi.
+func (server *Server) addOrganizationMember(ctx *gin.Context) {
+	var req addOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+	if req.Role == db.RoleOwner && ctx.GetString(authorizationRoleKey) != db.RoleOwner {
+		respondError(ctx, http.StatusForbidden, errOwnerRoleRequired)
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("no user is registered with this email"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if _, err := server.store.GetOrganizationMember(ctx, orgID, user.ID); err == nil {
+		respondError(ctx, http.StatusConflict, errMemberAlreadyAdded)
+		return
+	} else if !errors.Is(err, db.ErrRecordNotFound) {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	member, err := server.store.AddOrganizationMember(ctx, db.AddOrganizationMemberParams{
+		OrganizationID: orgID,
+		UserID:         user.ID,
+		Role:           req.Role,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditMemberAdd,
+		TargetType: auditTargetMember,
+		TargetID:   member.UserID,
+		Changes:    db.AuditDiff(nil, member),
+	})
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+type updateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required,oneof=owner admin editor viewer"`
+}
+
+func (server *Server) updateOrganizationMember(ctx *gin.Context) {
+	memberID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid user ID"))
+		return
+	}
+
+	var req updateOrganizationMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+	role := ctx.GetString(authorizationRoleKey)
+
+	before, _ := server.store.GetOrganizationMember(ctx, orgID, memberID)
+
+	var member db.OrganizationMember
+	err = server.store.ExecTx(ctx, func(store db.Store) error {
+		if err := checkMemberChange(ctx, store, orgID, memberID, role, req.Role); err != nil {
+			return err
+		}
+		var err error
+		member, err = store.UpdateOrganizationMemberRole(ctx, orgID, memberID, req.Role)
+		return err
+	})
+	if err != nil {
+		server.memberChangeError(ctx, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditMemberUpdate,
+		TargetType: auditTargetMember,
+		TargetID:   memberID,
+		Changes:    db.AuditDiff(before, member),
+	})
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// removeOrganizationMember mengeluarkan anggota dari organisasi. Setiap anggota boleh keluar sendiri;
+// mengeluarkan anggota lain membutuhkan role admin.
+func (server *Server) removeOrganizationMember(ctx *gin.Context) {
+	memberID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid user ID"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+	role := ctx.GetString(authorizationRoleKey)
+	if memberID != ctx.GetInt64(authorizationPayloadKey) && !db.RoleAtLeast(role, db.RoleAdmin) {
+		respondError(ctx, http.StatusForbidden, errors.New("this action requires the admin role or higher"))
+		return
+	}
+
+	before, _ := server.store.GetOrganizationMember(ctx, orgID, memberID)
+
+	err = server.store.ExecTx(ctx, func(store db.Store) error {
+		if err := checkMemberChange(ctx, store, orgID, memberID, role, ""); err != nil {
+			return err
+		}
+		return store.RemoveOrganizationMember(ctx, orgID, memberID)
+	})
+	if err != nil {
+		server.memberChangeError(ctx, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditMemberRemove,
+		TargetType: auditTargetMember,
+		TargetID:   memberID,
+		Changes:    db.AuditDiff(before, nil),
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "member removed successfully"})
+}
+
+// checkMemberChange memeriksa apakah anggota dengan actorRole boleh mengubah role anggota lain menjadi newRole
+// (kosong berarti dikeluarkan): hanya owner yang dapat memberikan role owner atau mengubah owner lain, dan
+// organisasi harus tetap memiliki setidaknya satu owner.
+func checkMemberChange(ctx context.Context, store db.Store, orgID, memberID int64, actorRole, newRole string) error {
+	target, err := store.GetOrganizationMember(ctx, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if (newRole == db.RoleOwner || target.Role == db.RoleOwner) && actorRole != db.RoleOwner {
+		return errOwnerRoleRequired
+	}
+	if target.Role != db.RoleOwner || newRole == db.RoleOwner {
+		return nil
+	}
+
+	members, err := store.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	owners := 0
+	for _, m := range members {
+		if m.Role == db.RoleOwner {
+			owners++
+		}
+	}
+	if owners <= 1 {
+		return errLastOwner
+	}
+	return nil
+}
+
+func (server *Server) memberChangeError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		respondError(ctx, http.StatusNotFound, errors.New("member not found"))
+	case errors.Is(err, errOwnerRoleRequired):
+		respondError(ctx, http.StatusForbidden, err)
+	case errors.Is(err, errLastOwner):
+		respondError(ctx, http.StatusConflict, err)
+	default:
+		respondError(ctx, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/mail"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// forgotPassword mengirim link reset password ke email user. Respons selalu sama, baik email terdaftar
+// maupun tidak, dan email dikirim di background agar waktu respons juga tidak membocorkannya. Selama
+// passwordResetCooldown hanya satu email yang dikirim ke alamat yang sama; permintaan berikutnya tetap
+// mendapat respons yang sama agar cooldown tidak membocorkan apakah email terdaftar.
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if ok, _ := server.passwordResetEmails.allow(strings.ToLower(req.Email), time.Now()); ok {
+		go server.sendPasswordResetEmail(ctx.Copy(), req.Email)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "if the email is registered, a password reset link has been sent"})
+}
+
+// sendPasswordResetEmail membuat token reset untuk user dengan email tersebut dan mengirimkan link-nya.
+// reqCtx adalah salinan context request (gin.Context.Copy) untuk audit log.
+func (server *Server) sendPasswordResetEmail(reqCtx *gin.Context, email string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user, err := server.store.GetUserByEmail(ctx, email)
+	if err != nil {
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			log.Printf("Password reset: failed to look up user: %v", err)
+		}
+		return
+	}
+
+	token, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		log.Printf("Password reset: failed to generate token: %v", err)
+		return
+	}
+	resetToken, err := server.store.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(passwordResetTokenTTL),
+	})
+	if err != nil {
+		log.Printf("Password reset: failed to store token for user %d: %v", user.ID, err)
+		return
+	}
+	server.recordAudit(reqCtx, db.CreateAuditEventParams{
+		UserID:     user.ID,
+		Action:     auditUserRequestReset,
+		TargetType: auditTargetUser,
+		TargetID:   user.ID,
+	})
+
+	link := appBaseURL() + "/reset-password?token=" + url.QueryEscape(token)
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Go-Pulse password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone requested a password reset for your Go-Pulse account. "+
+			"Open the link below to choose a new password:\n\n%s\n\n"+
+			"The link expires at %s and can only be used once. If you did not request this, you can ignore this email.\n",
+			user.Username, link, resetToken.ExpiresAt.UTC().Format(time.RFC1123)),
+	}
+	if err := server.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Password reset: failed to send email to user %d: %v", user.ID, err)
+	}
+}
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// resetPassword mengganti password dengan token dari email. Semua session user dicabut sehingga
+// perangkat lain harus login ulang dengan password baru.
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	userID, err := server.store.ResetPassword(ctx, db.ResetPasswordParams{
+		TokenHash:    hashToken(req.Token),
+		PasswordHash: string(hashedPassword),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusBadRequest, errors.New("invalid or expired password reset token"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// ResetPassword mencabut semua session user, koneksi WebSocket-nya ikut ditutup
+	server.hub.DisconnectUserSessions(userID, 0)
+
+	// Password baru membuka kembali akun yang dikunci karena login gagal
+	if user, err := server.store.GetUser(ctx, userID); err == nil {
+		server.resetLoginFailures(ctx, user.Email)
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		ActorID:    userID,
+		Action:     auditUserResetPassword,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "password has been reset, please log in again"})
+}
+
+// appBaseURL adalah alamat frontend yang dipakai untuk link di email, dari APP_BASE_URL.
+func appBaseURL() string {
+	if v := os.Getenv("APP_BASE_URL"); v != "" {
+		return strings.TrimRight(v, "/")
+	}
+	return "http://localhost:5173"
+}
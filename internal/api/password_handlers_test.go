@@ -0,0 +1,96 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var resetLinkPattern = regexp.MustCompile(`/reset-password\?token=(\S+)`)
+
+// waitForMail menunggu satu email dari server, karena email dikirim di background.
+func (ts *testServer) waitForMail(t *testing.T) string {
+	t.Helper()
+	select {
+	case msg := <-ts.mailer.sent:
+		return msg.Body
+	case <-time.After(2 * time.Second):
+		t.Fatal("no email was sent")
+		return ""
+	}
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	ts := newTestServer(t)
+	srv := httptest.NewServer(ts.router)
+	defer srv.Close()
+	user := ts.createUser(t, "alice", true)
+	session := ts.loginSession(t, user.Email)
+	if _, _, err := dialWs(t, srv, session.Token); err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	waitForClients(t, ts, 1)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/password/forgot", "", gin.H{"email": user.Email})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("forgot: status %d: %s", rec.Code, rec.Body)
+	}
+	match := resetLinkPattern.FindStringSubmatch(ts.waitForMail(t))
+	if match == nil {
+		t.Fatal("email does not contain a reset link")
+	}
+	token, _ := url.QueryUnescape(match[1])
+
+	rec = ts.request(t, http.MethodPost, "/api/auth/password/reset", "", gin.H{"token": token, "new_password": "newsecret"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reset: status %d: %s", rec.Code, rec.Body)
+	}
+
+	// Semua session lama dicabut, termasuk koneksi WebSocket-nya
+	rec = ts.request(t, http.MethodGet, "/api/sites", session.Token, nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+	waitForClients(t, ts, 0)
+
+	rec = ts.request(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": user.Email, "password": "newsecret"})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login with new password: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.request(t, http.MethodPost, "/api/auth/password/reset", "", gin.H{"token": token, "new_password": "another"})
+	requireError(t, rec, http.StatusBadRequest, codeBadRequest)
+}
+
+func TestForgotPasswordSendsOneEmailPerCooldown(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+
+	for range 2 {
+		rec := ts.request(t, http.MethodPost, "/api/auth/password/forgot", "", gin.H{"email": user.Email})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("forgot: status %d: %s", rec.Code, rec.Body)
+		}
+	}
+	ts.waitForMail(t)
+	select {
+	case <-ts.mailer.sent:
+		t.Error("a second reset email was sent within the cooldown")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestForgotPasswordIsRateLimitedByIP(t *testing.T) {
+	ts := newTestServer(t)
+
+	for i := range forgotPasswordRateLimit {
+		email := gin.H{"email": "nobody" + string(rune('a'+i)) + "@example.com"}
+		if rec := ts.request(t, http.MethodPost, "/api/auth/password/forgot", "", email); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+	}
+	rec := ts.request(t, http.MethodPost, "/api/auth/password/forgot", "", gin.H{"email": "nobody@example.com"})
+	requireError(t, rec, http.StatusTooManyRequests, codeRateLimited)
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Batas request per IP untuk endpoint autentikasi publik
+const (
+	loginRateLimit     = 10
+	loginRateWindow    = time.Minute
+	registerRateLimit  = 10
+	registerRateWindow = time.Hour
+	// Permintaan reset password per IP; setiap alamat email juga hanya dikirimi satu link per cooldown
+	forgotPasswordRateLimit  = 5
+	forgotPasswordRateWindow = time.Hour
+	passwordResetCooldown    = 5 * time.Minute
+	// Pengiriman ulang email verifikasi per user
+	verificationResendRateLimit  = 3
+	verificationResendRateWindow = time.Hour
+)
+
+// Jumlah key yang disimpan rateLimiter sebelum jendela yang sudah lewat dibersihkan
+const rateLimiterSweepSize = 10000
+
+var errRateLimited = newAPIError(codeRateLimited, "too many requests, please try again later")
+
+// rateLimiter membatasi jumlah request per key dalam jendela waktu tetap. Hitungan disimpan di memori,
+// jadi setiap instance server membatasi secara terpisah.
+type rateLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	windows map[string]rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]rateWindow),
+	}
+}
+
+// allow mencatat satu request untuk key. Jika batas sudah tercapai, false dikembalikan beserta sisa waktu
+// sampai jendela berikutnya.
+func (l *rateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.windows) >= rateLimiterSweepSize {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = rateWindow{start: now}
+	}
+	if w.count >= l.limit {
+		return false, w.start.Add(l.window).Sub(now)
+	}
+	w.count++
+	l.windows[key] = w
+	return true, 0
+}
+
+// rateLimitByIP menolak request dengan 429 jika IP pengirim sudah melewati batas limiter.
+func rateLimitByIP(limiter *rateLimiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ok, retryAfter := limiter.allow(ctx.ClientIP(), time.Now()); !ok {
+			setRetryAfter(ctx, retryAfter)
+			abortWithError(ctx, http.StatusTooManyRequests, errRateLimited)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// setRetryAfter mengisi header Retry-After dalam detik, dibulatkan ke atas.
+func setRetryAfter(ctx *gin.Context, d time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(int((d+time.Second-1)/time.Second)))
+}
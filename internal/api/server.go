@@ -1,58 +1,167 @@
 package api
 
 import (
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/mail"
+	"github.com/tajri15/go-pulse-monitoring/internal/metrics"
+	"github.com/tajri15/go-pulse-monitoring/internal/oidc"
 	"github.com/tajri15/go-pulse-monitoring/internal/ws"
 )
 
 // Server adalah struct utama yang menampung semua dependensi server.
 type Server struct {
-	store  *db.Store
+	store  db.Store
 	hub    *ws.Hub
+	mailer mail.Mailer
+	// oidc terisi jika login SSO diaktifkan lewat EnableOIDC
+	oidc   *oidc.Provider
 	router *gin.Engine
+
+	// Batas request per IP untuk endpoint login, registrasi dan lupa password
+	loginLimiter          *rateLimiter
+	registerLimiter       *rateLimiter
+	forgotPasswordLimiter *rateLimiter
+	// passwordResetEmails membatasi email reset per alamat tujuan, bukan per IP
+	passwordResetEmails *rateLimiter
+	// verificationEmails membatasi pengiriman ulang email verifikasi per user
+	verificationEmails *rateLimiter
 }
 
 // NewServer membuat instance server baru dan mengatur semua rute.
-func NewServer(store *db.Store, hub *ws.Hub) *Server {
+func NewServer(store db.Store, hub *ws.Hub, mailer mail.Mailer) *Server {
 	server := &Server{
-		store: store,
-		hub:   hub,
+		store:  store,
+		hub:    hub,
+		mailer: mailer,
+
+		loginLimiter:          newRateLimiter(loginRateLimit, loginRateWindow),
+		registerLimiter:       newRateLimiter(registerRateLimit, registerRateWindow),
+		forgotPasswordLimiter: newRateLimiter(forgotPasswordRateLimit, forgotPasswordRateWindow),
+		passwordResetEmails:   newRateLimiter(1, passwordResetCooldown),
+		verificationEmails:    newRateLimiter(verificationResendRateLimit, verificationResendRateWindow),
 	}
 	router := gin.Default()
 
+	// IP client (untuk rate limit, session dan audit log) hanya diambil dari X-Forwarded-For jika request
+	// datang dari proxy di TRUSTED_PROXIES (daftar IP/CIDR dipisah koma)
+	var trustedProxies []string
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		for _, proxy := range strings.Split(v, ",") {
+			trustedProxies = append(trustedProxies, strings.TrimSpace(proxy))
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+
 	// --- Konfigurasi Middleware CORS ---
 	// Ini penting agar browser tidak memblokir permintaan dari frontend Vue Anda.
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:5173"} // Izinkan frontend dev server
-	config.AllowMethods = []string{"GET", "POST", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization"}
+	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	config.AllowHeaders = []string{"Origin", "Content-Type", "Authorization", organizationHeaderKey}
 	router.Use(cors.New(config))
 	// --------------------------------
 
-	// Endpoint WebSocket (tidak perlu dilindungi middleware API karena punya auth sendiri)
-	router.GET("/ws", func(c *gin.Context) {
-		ws.ServeWs(server.hub, c)
-	})
+	// Endpoint WebSocket; browser tidak bisa mengirim header Authorization sehingga token dikirim lewat query
+	router.GET("/ws", server.serveWs)
+
+	// Endpoint Prometheus, dilindungi bearer token METRICS_TOKEN jika diset
+	router.GET("/metrics", metricsAuthMiddleware(), gin.WrapH(metrics.Handler()))
 
 	// Rute publik untuk autentikasi
 	authRoutes := router.Group("/api/auth")
 	{
-		authRoutes.POST("/register", server.registerUser)
-		authRoutes.POST("/login", server.loginUser)
+		authRoutes.POST("/register", rateLimitByIP(server.registerLimiter), server.registerUser)
+		authRoutes.POST("/login", rateLimitByIP(server.loginLimiter), server.loginUser)
+		authRoutes.POST("/refresh", server.refreshSession)
+		authRoutes.POST("/logout", server.logoutUser)
+		authRoutes.POST("/password/forgot", rateLimitByIP(server.forgotPasswordLimiter), server.forgotPassword)
+		authRoutes.POST("/password/reset", server.resetPassword)
+		authRoutes.POST("/verify-email", server.verifyEmail)
+		authRoutes.POST("/2fa/verify", rateLimitByIP(server.loginLimiter), server.verifyTwoFactorLogin)
+		authRoutes.GET("/oidc/login", server.oidcLogin)
+		authRoutes.GET("/oidc/callback", server.oidcCallback)
 	}
 
-	// Grup rute yang dilindungi oleh middleware otentikasi JWT
-	api := router.Group("/api").Use(authMiddleware())
+	// Grup rute yang dilindungi oleh middleware otentikasi (JWT atau API key)
+	api := router.Group("/api").Use(server.authMiddleware())
 	{
-		api.POST("/sites", server.createSite)
-		api.GET("/sites", server.listSites)
-		api.DELETE("/sites/:id", server.deleteSite)
+		// Request dengan API key dibatasi oleh scope-nya; JWT memiliki akses penuh. Selain itu, user harus
+		// memiliki role yang cukup di organisasi aktif (header X-Organization-ID)
+		read, write := requireScope(db.ScopeSitesRead), requireScope(db.ScopeSitesWrite)
+		viewer, editor := server.requireRole(db.RoleViewer), server.requireRole(db.RoleEditor)
+
+		api.POST("/sites", write, editor, server.createSite)
+		api.GET("/sites", read, viewer, server.listSites)
+		api.POST("/sites/import", write, editor, server.importSites)
+		api.GET("/sites/archived", read, viewer, server.listArchivedSites)
+		api.PATCH("/sites/:id", write, editor, server.updateSite)
+		api.DELETE("/sites/:id", write, editor, server.deleteSite)
+		api.POST("/sites/:id/pause", write, editor, server.pauseSite)
+		api.POST("/sites/:id/resume", write, editor, server.resumeSite)
+		api.GET("/sites/:id/uptime", read, viewer, server.getSiteUptime)
+		api.PUT("/sites/:id/tags", write, editor, server.setSiteTags)
+		api.POST("/sites/:id/restore", write, editor, server.restoreSite)
+		api.GET("/sites/:id/checks/export", read, viewer, server.exportSiteChecks)
+		api.GET("/checks/export", read, viewer, server.exportAllChecks)
+
+		api.POST("/groups", write, editor, server.createGroup)
+		api.GET("/groups", read, viewer, server.listGroups)
+		api.GET("/groups/:id", read, viewer, server.getGroup)
+		api.PATCH("/groups/:id", write, editor, server.updateGroup)
+		api.DELETE("/groups/:id", write, editor, server.deleteGroup)
+
+		// Rute organisasi mengambil organisasi dari path, bukan dari header
+		orgViewer := server.requireOrganizationRole(db.RoleViewer)
+		orgAdmin := server.requireOrganizationRole(db.RoleAdmin)
+		api.GET("/organizations", requireSession(), server.listOrganizations)
+		api.POST("/organizations", requireSession(), server.createOrganization)
+		api.GET("/organizations/:id", requireSession(), orgViewer, server.getOrganization)
+		api.PATCH("/organizations/:id", requireSession(), orgAdmin, server.updateOrganization)
+		api.DELETE("/organizations/:id", requireSession(), server.requireOrganizationRole(db.RoleOwner), server.deleteOrganization)
+		api.GET("/organizations/:id/members", requireSession(), orgViewer, server.listOrganizationMembers)
+		api.POST("/organizations/:id/members", requireSession(), orgAdmin, server.addOrganizationMember)
+		api.PATCH("/organizations/:id/members/:user_id", requireSession(), orgAdmin, server.updateOrganizationMember)
+		api.DELETE("/organizations/:id/members/:user_id", requireSession(), orgViewer, server.removeOrganizationMember)
+		api.POST("/organizations/:id/teams", requireSession(), orgAdmin, server.createTeam)
+		api.GET("/organizations/:id/teams", requireSession(), orgViewer, server.listTeams)
+		api.GET("/organizations/:id/teams/:team_id", requireSession(), orgViewer, server.getTeam)
+		api.PATCH("/organizations/:id/teams/:team_id", requireSession(), orgAdmin, server.updateTeam)
+		api.DELETE("/organizations/:id/teams/:team_id", requireSession(), orgAdmin, server.deleteTeam)
+
+		api.GET("/sessions", requireSession(), server.listSessions)
+		api.DELETE("/sessions", requireSession(), server.revokeOtherSessions)
+		api.DELETE("/sessions/:id", requireSession(), server.revokeSession)
+
+		api.POST("/auth/verify-email/resend", requireSession(), server.resendVerificationEmail)
+		api.POST("/auth/2fa/totp", requireSession(), server.enrollTOTP)
+		api.POST("/auth/2fa/totp/confirm", requireSession(), server.confirmTOTP)
+		api.DELETE("/auth/2fa/totp", requireSession(), server.disableTOTP)
+
+		api.POST("/keys", requireSession(), server.createAPIKey)
+		api.GET("/keys", requireSession(), server.listAPIKeys)
+		api.DELETE("/keys/:id", requireSession(), server.revokeAPIKey)
+
+		api.GET("/config/export", read, viewer, server.exportConfig)
+		api.POST("/config/apply", write, editor, server.applyConfig)
+
+		api.GET("/audit", requireSession(), server.requireRole(db.RoleAdmin), server.listAuditEvents)
+		api.GET("/audit/account", requireSession(), server.listAccountAuditEvents)
 	}
 
+	router.NoRoute(func(ctx *gin.Context) {
+		respondError(ctx, http.StatusNotFound, errors.New("route not found"))
+	})
+
 	server.router = router
 	return server
 }
@@ -61,9 +170,4 @@ func NewServer(store *db.Store, hub *ws.Hub) *Server {
 func (server *Server) Start(address string) error {
 	log.Printf("Starting server on %s", address)
 	return server.router.Run(address)
-}
-
-// errorResponse adalah helper untuk membuat response error yang konsisten.
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
 }
\ No newline at end of file
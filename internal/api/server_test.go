@@ -0,0 +1,180 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/mail"
+	"github.com/tajri15/go-pulse-monitoring/internal/ws"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testPassword = "secret123"
+
+// testMailer menyimpan email yang dikirim server agar bisa diperiksa oleh test.
+type testMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+	sent     chan mail.Message
+}
+
+func newTestMailer() *testMailer {
+	return &testMailer{sent: make(chan mail.Message, 100)}
+}
+
+func (m *testMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	m.messages = append(m.messages, msg)
+	m.mu.Unlock()
+	m.sent <- msg
+	return nil
+}
+
+// testServer adalah Server di atas MemoryStore untuk test handler tanpa database.
+type testServer struct {
+	*Server
+	store  *db.MemoryStore
+	hub    *ws.Hub
+	mailer *testMailer
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	store := db.NewMemoryStore()
+	hub := ws.NewHub()
+	go hub.Run()
+	mailer := newTestMailer()
+	return &testServer{
+		Server: NewServer(store, hub, mailer),
+		store:  store,
+		hub:    hub,
+		mailer: mailer,
+	}
+}
+
+// request mengirim request ke router. body di-encode sebagai JSON jika bukan nil; token dapat kosong.
+func (ts *testServer) request(t *testing.T, method, path, token string, body any, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	ts.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// createUser membuat user beserta organisasi default-nya langsung di store.
+func (ts *testServer) createUser(t *testing.T, username string, verified bool) db.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := createUser(context.Background(), ts.store, db.CreateUserParams{
+		Username:     username,
+		Email:        username + "@example.com",
+		PasswordHash: string(hash),
+	})
+	if err != nil {
+		t.Fatalf("createUser: %v", err)
+	}
+	if verified {
+		if user, err = ts.store.VerifyUserEmail(context.Background(), user.ID, user.Email); err != nil {
+			t.Fatalf("VerifyUserEmail: %v", err)
+		}
+	}
+	return user
+}
+
+// loginSession masuk lewat API dan mengembalikan access token beserta refresh token.
+func (ts *testServer) loginSession(t *testing.T, email string) loginUserResponse {
+	t.Helper()
+	rec := ts.request(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": email, "password": testPassword})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login %s: status %d: %s", email, rec.Code, rec.Body)
+	}
+	var resp loginUserResponse
+	decodeBody(t, rec, &resp)
+	return resp
+}
+
+// login masuk lewat API dan mengembalikan access token.
+func (ts *testServer) login(t *testing.T, email string) string {
+	t.Helper()
+	return ts.loginSession(t, email).Token
+}
+
+// defaultOrganization mengembalikan organisasi pertama milik user.
+func (ts *testServer) defaultOrganization(t *testing.T, userID int64) db.UserOrganization {
+	t.Helper()
+	orgs, err := ts.store.ListUserOrganizations(context.Background(), userID)
+	if err != nil || len(orgs) == 0 {
+		t.Fatalf("ListUserOrganizations: %v (%d organizations)", err, len(orgs))
+	}
+	return orgs[0]
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body, err)
+	}
+}
+
+// errorResponseBody adalah bentuk respons error standar, lihat errorBody.
+type errorResponseBody struct {
+	Error errorBody `json:"error"`
+}
+
+// requireError memastikan respons adalah error dengan status dan kode yang diharapkan.
+func requireError(t *testing.T, rec *httptest.ResponseRecorder, status int, code string) errorBody {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, status, rec.Body)
+	}
+	var body errorResponseBody
+	decodeBody(t, rec, &body)
+	if body.Error.Code != code {
+		t.Fatalf("error code = %q, want %q: %s", body.Error.Code, code, rec.Body)
+	}
+	return body.Error
+}
+
+func orgHeader(orgID int64) []string {
+	return []string{organizationHeaderKey, strconv.FormatInt(orgID, 10)}
+}
+
+func TestUnknownRouteReturnsNotFound(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.request(t, http.MethodGet, "/api/does-not-exist", "", nil)
+	requireError(t, rec, http.StatusNotFound, codeNotFound)
+}
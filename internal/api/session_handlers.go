@@ -0,0 +1,215 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/ws"
+)
+
+type refreshSessionRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type refreshSessionResponse struct {
+	Token                 string    `json:"token"`
+	RefreshToken          string    `json:"refresh_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// refreshSession menukar refresh token dengan access token baru. Refresh token lama langsung tidak berlaku;
+// jika token lama dipakai lagi, seluruh session dicabut.
+func (server *Server) refreshSession(ctx *gin.Context) {
+	var req refreshSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	refreshToken, refreshTokenHash, err := newOpaqueToken()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	session, err := server.store.RotateSession(ctx, db.RotateSessionParams{
+		OldTokenHash: hashToken(req.RefreshToken),
+		NewTokenHash: refreshTokenHash,
+		ExpiresAt:    time.Now().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRefreshTokenReused) {
+			server.hub.DisconnectSession(session.ID)
+			respondError(ctx, http.StatusUnauthorized, errors.New("refresh token has already been used, the session has been revoked"))
+			return
+		}
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusUnauthorized, errors.New("invalid or expired refresh token"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	token, err := generateToken(session.UserID, session.ID, accessTokenTTL)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, refreshSessionResponse{
+		Token:                 token,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  time.Now().Add(accessTokenTTL),
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	})
+}
+
+// logoutUser mencabut session milik refresh token; access token session tersebut ikut tidak berlaku.
+func (server *Server) logoutUser(ctx *gin.Context) {
+	var req refreshSessionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	session, err := server.store.GetSessionByRefreshToken(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusUnauthorized, errors.New("invalid refresh token"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Logout dua kali tetap dianggap berhasil
+	if err := server.store.RevokeSession(ctx, session.ID, session.UserID); err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	server.hub.DisconnectSession(session.ID)
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     session.UserID,
+		ActorID:    session.UserID,
+		Action:     auditLogout,
+		TargetType: auditTargetSession,
+		TargetID:   session.ID,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "logged out successfully"})
+}
+
+type sessionResponse struct {
+	db.Session
+	// Current bernilai true untuk session milik access token yang dipakai request ini
+	Current bool `json:"current"`
+}
+
+func (server *Server) listSessions(ctx *gin.Context) {
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+	currentSessionID := ctx.GetInt64(authorizationSessionKey)
+
+	sessions, err := server.store.ListSessions(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	rsp := make([]sessionResponse, len(sessions))
+	for i, session := range sessions {
+		rsp[i] = sessionResponse{Session: session, Current: session.ID == currentSessionID}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+func (server *Server) revokeSession(ctx *gin.Context) {
+	sessionID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid session ID"))
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	if err := server.store.RevokeSession(ctx, sessionID, userID); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("session not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	server.hub.DisconnectSession(sessionID)
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		Action:     auditSessionRevoke,
+		TargetType: auditTargetSession,
+		TargetID:   sessionID,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "session revoked successfully"})
+}
+
+// revokeOtherSessions mencabut semua session milik user kecuali session yang sedang dipakai.
+func (server *Server) revokeOtherSessions(ctx *gin.Context) {
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	currentSessionID := ctx.GetInt64(authorizationSessionKey)
+	revoked, err := server.store.RevokeUserSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	server.hub.DisconnectUserSessions(userID, currentSessionID)
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		Action:     auditSessionRevokeOther,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+		Details:    map[string]any{"revoked": revoked},
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "sessions revoked successfully", "revoked": revoked})
+}
+
+// serveWs membuka koneksi WebSocket untuk update realtime. Access token diperiksa dengan cara yang sama
+// seperti authMiddleware, sehingga token dari session yang sudah dicabut ditolak.
+func (server *Server) serveWs(ctx *gin.Context) {
+	tokenString := ctx.Query("token")
+	if tokenString == "" {
+		respondError(ctx, http.StatusUnauthorized, errors.New("token is required"))
+		return
+	}
+	session, err := server.verifyAccessToken(ctx, tokenString)
+	if err != nil {
+		respondError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	ws.ServeWs(server.hub, ctx, session.UserID, session.ID)
+}
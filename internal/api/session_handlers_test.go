@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func TestRefreshRotatesRefreshToken(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	session := ts.loginSession(t, user.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refresh_token": session.RefreshToken})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d: %s", rec.Code, rec.Body)
+	}
+	var refreshed loginUserResponse
+	decodeBody(t, rec, &refreshed)
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == session.RefreshToken {
+		t.Fatal("refresh did not rotate the refresh token")
+	}
+
+	// Refresh token lama yang dipakai ulang dianggap dicuri: seluruh session dicabut
+	rec = ts.request(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refresh_token": session.RefreshToken})
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+	rec = ts.request(t, http.MethodGet, "/api/sites", refreshed.Token, nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+}
+
+func TestLogoutRevokesAccessToken(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	session := ts.loginSession(t, user.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/logout", "", gin.H{"refresh_token": session.RefreshToken})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("logout: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = ts.request(t, http.MethodGet, "/api/sites", session.Token, nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+}
+
+// dialWs membuka koneksi WebSocket ke router test dengan token yang diberikan.
+func dialWs(t *testing.T, srv *httptest.Server, token string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?token=" + token
+	conn, rsp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, rsp, err
+}
+
+// requireWsClosed memastikan server menutup koneksi WebSocket.
+func requireWsClosed(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNoStatusReceived, websocket.CloseNormalClosure) {
+				return
+			}
+			t.Fatalf("connection was not closed by the server: %v", err)
+		}
+	}
+}
+
+func TestWebSocketRejectsRevokedSession(t *testing.T) {
+	ts := newTestServer(t)
+	srv := httptest.NewServer(ts.router)
+	defer srv.Close()
+	user := ts.createUser(t, "alice", true)
+	session := ts.loginSession(t, user.Email)
+
+	if _, rsp, err := dialWs(t, srv, "not-a-token"); err == nil || rsp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("dial with invalid token: err %v", err)
+	}
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/logout", "", gin.H{"refresh_token": session.RefreshToken})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("logout: status %d: %s", rec.Code, rec.Body)
+	}
+	if _, rsp, err := dialWs(t, srv, session.Token); err == nil || rsp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("dial with revoked session: err %v", err)
+	}
+}
+
+func TestRevokingSessionClosesItsWebSocket(t *testing.T) {
+	tests := map[string]func(t *testing.T, ts *testServer, current, other loginUserResponse) *httptest.ResponseRecorder{
+		"logout": func(t *testing.T, ts *testServer, current, other loginUserResponse) *httptest.ResponseRecorder {
+			return ts.request(t, http.MethodPost, "/api/auth/logout", "", gin.H{"refresh_token": other.RefreshToken})
+		},
+		"revoke others": func(t *testing.T, ts *testServer, current, other loginUserResponse) *httptest.ResponseRecorder {
+			return ts.request(t, http.MethodDelete, "/api/sessions", current.Token, nil)
+		},
+	}
+	for name, revoke := range tests {
+		t.Run(name, func(t *testing.T) {
+			ts := newTestServer(t)
+			srv := httptest.NewServer(ts.router)
+			defer srv.Close()
+			user := ts.createUser(t, "alice", true)
+			current := ts.loginSession(t, user.Email)
+			other := ts.loginSession(t, user.Email)
+
+			conn, _, err := dialWs(t, srv, other.Token)
+			if err != nil {
+				t.Fatalf("dial: %v", err)
+			}
+			waitForClients(t, ts, 1)
+
+			if rec := revoke(t, ts, current, other); rec.Code != http.StatusOK {
+				t.Fatalf("revoke: status %d: %s", rec.Code, rec.Body)
+			}
+			requireWsClosed(t, conn)
+			if n := ts.hub.ClientCount(); n != 0 {
+				t.Errorf("hub still has %d clients", n)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenReuseClosesWebSocket(t *testing.T) {
+	ts := newTestServer(t)
+	srv := httptest.NewServer(ts.router)
+	defer srv.Close()
+	user := ts.createUser(t, "alice", true)
+	session := ts.loginSession(t, user.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refresh_token": session.RefreshToken})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d: %s", rec.Code, rec.Body)
+	}
+	var refreshed loginUserResponse
+	decodeBody(t, rec, &refreshed)
+
+	conn, _, err := dialWs(t, srv, refreshed.Token)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	waitForClients(t, ts, 1)
+
+	rec = ts.request(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refresh_token": session.RefreshToken})
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+	requireWsClosed(t, conn)
+	if n := ts.hub.ClientCount(); n != 0 {
+		t.Errorf("hub still has %d clients", n)
+	}
+
+	// Refresh token terbaru ikut tidak berlaku karena session-nya sudah dicabut
+	rec = ts.request(t, http.MethodPost, "/api/auth/refresh", "", gin.H{"refresh_token": refreshed.RefreshToken})
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+	sessions, err := ts.store.ListSessions(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("user still has %d active sessions", len(sessions))
+	}
+}
+
+func TestRevokingOtherSessionKeepsCurrentWebSocket(t *testing.T) {
+	ts := newTestServer(t)
+	srv := httptest.NewServer(ts.router)
+	defer srv.Close()
+	user := ts.createUser(t, "alice", true)
+	current := ts.loginSession(t, user.Email)
+	ts.loginSession(t, user.Email)
+
+	if _, _, err := dialWs(t, srv, current.Token); err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	waitForClients(t, ts, 1)
+
+	rec := ts.request(t, http.MethodDelete, "/api/sessions", current.Token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("revoke others: status %d: %s", rec.Code, rec.Body)
+	}
+	if n := ts.hub.ClientCount(); n != 1 {
+		t.Errorf("hub has %d clients, want the current session to stay connected", n)
+	}
+}
+
+// waitForClients menunggu Hub selesai mendaftarkan client, karena Register diproses secara asinkron.
+func waitForClients(t *testing.T, ts *testServer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for ts.hub.ClientCount() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("hub has %d clients, want %d", ts.hub.ClientCount(), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -1,83 +1,419 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tajri15/go-pulse-monitoring/internal/db"
 )
 
 type createSiteRequest struct {
-	URL string `json:"url" binding:"required,url"`
+	URL                  string   `json:"url" binding:"required,url"`
+	Name                 string   `json:"name" binding:"max=100"`
+	Description          string   `json:"description" binding:"max=500"`
+	CheckType            string   `json:"check_type" binding:"omitempty,oneof=HTTP"`
+	CheckIntervalSeconds int      `json:"check_interval_seconds" binding:"omitempty,min=30,max=86400"`
+	TimeoutSeconds       int      `json:"timeout_seconds" binding:"omitempty,min=1,max=60"`
+	Tags                 []string `json:"tags"`
 }
 
 func (server *Server) createSite(ctx *gin.Context) {
 	var req createSiteRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	tags, err := db.NormalizeTags(req.Tags)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	// Ambil userID dari context yang sudah di-set oleh middleware
 	authPayload, exists := ctx.Get(authorizationPayloadKey)
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("authorization payload does not exist")))
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
 		return
 	}
 	userID := authPayload.(int64)
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	remaining, err := server.remainingSites(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if remaining == 0 {
+		respondError(ctx, http.StatusForbidden, errSiteLimitReached)
+		return
+	}
 
 	arg := db.CreateSiteParams{
-		UserID: userID,
-		URL:    req.URL,
+		OrganizationID:       orgID,
+		UserID:               userID,
+		URL:                  req.URL,
+		Name:                 req.Name,
+		Description:          req.Description,
+		CheckType:            req.CheckType,
+		CheckIntervalSeconds: req.CheckIntervalSeconds,
+		TimeoutSeconds:       req.TimeoutSeconds,
 	}
 
-	site, err := server.store.CreateSite(ctx, arg)
+	site, err := createSiteWithTags(ctx, server.store, arg, tags)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
+	server.recordSiteAudit(ctx, auditSiteCreate, site.ID, nil, &site)
+
 	ctx.JSON(http.StatusOK, site)
 }
 
+// createSiteWithTags membuat site beserta tag-nya dalam satu transaksi, sehingga tag yang gagal disimpan
+// tidak meninggalkan site tanpa tag.
+func createSiteWithTags(ctx context.Context, store db.Store, arg db.CreateSiteParams, tags []string) (db.Site, error) {
+	var site db.Site
+	err := store.ExecTx(ctx, func(tx db.Store) error {
+		var err error
+		site, err = tx.CreateSite(ctx, arg)
+		if err != nil || len(tags) == 0 {
+			return err
+		}
+		site, err = tx.SetSiteTags(ctx, site.ID, arg.OrganizationID, tags)
+		return err
+	})
+	return site, err
+}
+
+type listSitesRequest struct {
+	Status string `form:"status" binding:"omitempty,oneof=up down paused"`
+	Tag    string `form:"tag"`
+	Type   string `form:"type"`
+	Search string `form:"q" binding:"max=200"`
+	Sort   string `form:"sort" binding:"omitempty,oneof=name created_at response_time uptime"`
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"`
+	Range  string `form:"range" binding:"omitempty,oneof=24h 7d 30d"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+	Cursor string `form:"cursor"`
+}
+
+// listSites mengembalikan site milik organisasi, diurutkan dari yang terbaru jika sort tidak diisi. Tanpa limit
+// dan cursor, semua site dikembalikan sebagai array seperti sebelumnya; dengan salah satunya, respons berupa
+// satu halaman {sites, total, next_cursor}.
 func (server *Server) listSites(ctx *gin.Context) {
-	authPayload, exists := ctx.Get(authorizationPayloadKey)
-	if !exists {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("authorization payload does not exist")))
+	var req listSitesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	userID := authPayload.(int64)
 
-	sites, err := server.store.GetSitesByUserID(ctx, userID)
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	if req.Sort == "" {
+		req.Sort = db.SiteSortCreatedAt
+	}
+	// Nama diurutkan A-Z, selain itu nilai terbesar/terbaru lebih dulu
+	if req.Order == "" {
+		req.Order = "desc"
+		if req.Sort == db.SiteSortName {
+			req.Order = "asc"
+		}
+	}
+	if req.Range == "" {
+		req.Range = "24h"
+	}
+	paginated := req.Limit > 0 || req.Cursor != ""
+	if paginated && req.Limit == 0 {
+		req.Limit = db.DefaultSitePageSize
+	}
+
+	arg := db.ListSitesParams{
+		OrganizationID: orgID,
+		Status:         req.Status,
+		Tag:            db.NormalizeTag(req.Tag),
+		Type:           strings.ToUpper(req.Type),
+		Search:         strings.TrimSpace(req.Search),
+		SortBy:         req.Sort,
+		Descending:     req.Order == "desc",
+		UptimeSince:    time.Now().Add(-uptimeRanges[req.Range]),
+		Limit:          req.Limit,
+		Cursor:         req.Cursor,
+	}
+
+	result, err := server.store.ListSites(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if errors.Is(err, db.ErrInvalidCursor) {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, sites)
+	if !paginated {
+		ctx.JSON(http.StatusOK, result.Sites)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
 }
 
 func (server *Server) deleteSite(ctx *gin.Context) {
 	siteID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("invalid site ID")))
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid site ID"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	before := server.siteBefore(ctx, siteID, orgID)
+
+	// Site hanya diarsipkan agar riwayat health check tetap tersimpan sampai di-purge
+	site, err := server.store.ArchiveSite(ctx, siteID, orgID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("site not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordSiteAudit(ctx, auditSiteDelete, siteID, before, &site)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "site deleted successfully"})
+}
+
+func (server *Server) listArchivedSites(ctx *gin.Context) {
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	sites, err := server.store.GetArchivedSitesByOrganizationID(ctx, orgID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sites)
+}
+
+func (server *Server) restoreSite(ctx *gin.Context) {
+	siteID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid site ID"))
 		return
 	}
 
 	authPayload, exists := ctx.Get(authorizationPayloadKey)
 	if !exists {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(errors.New("authorization payload does not exist")))
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
 		return
 	}
 	userID := authPayload.(int64)
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
 
-	err = server.store.DeleteSite(ctx, siteID, userID)
+	// Site yang dipulihkan kembali dipantau, sehingga ikut dihitung dalam batas akun yang belum terverifikasi
+	remaining, err := server.remainingSites(ctx, userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if remaining == 0 {
+		respondError(ctx, http.StatusForbidden, errSiteLimitReached)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"status": "site deleted successfully"})
-}
\ No newline at end of file
+	before := server.siteBefore(ctx, siteID, orgID)
+
+	site, err := server.store.RestoreSite(ctx, siteID, orgID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("archived site not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordSiteAudit(ctx, auditSiteRestore, siteID, before, &site)
+
+	ctx.JSON(http.StatusOK, site)
+}
+
+type updateSiteRequest struct {
+	URL                  *string `json:"url" binding:"omitempty,url"`
+	Name                 *string `json:"name" binding:"omitempty,max=100"`
+	Description          *string `json:"description" binding:"omitempty,max=500"`
+	CheckIntervalSeconds *int    `json:"check_interval_seconds" binding:"omitempty,min=30,max=86400"`
+	TimeoutSeconds       *int    `json:"timeout_seconds" binding:"omitempty,min=1,max=60"`
+}
+
+func (server *Server) updateSite(ctx *gin.Context) {
+	siteID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid site ID"))
+		return
+	}
+
+	var req updateSiteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	arg := db.UpdateSiteParams{
+		URL:                  req.URL,
+		Name:                 req.Name,
+		Description:          req.Description,
+		CheckIntervalSeconds: req.CheckIntervalSeconds,
+		TimeoutSeconds:       req.TimeoutSeconds,
+	}
+
+	before := server.siteBefore(ctx, siteID, orgID)
+
+	site, err := server.store.UpdateSite(ctx, siteID, orgID, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("site not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordSiteAudit(ctx, auditSiteUpdate, siteID, before, &site)
+
+	ctx.JSON(http.StatusOK, site)
+}
+
+func (server *Server) pauseSite(ctx *gin.Context) {
+	server.setSitePaused(ctx, true)
+}
+
+func (server *Server) resumeSite(ctx *gin.Context) {
+	server.setSitePaused(ctx, false)
+}
+
+// setSitePaused menjeda atau melanjutkan pengecekan site milik organisasi.
+func (server *Server) setSitePaused(ctx *gin.Context, paused bool) {
+	siteID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid site ID"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	before := server.siteBefore(ctx, siteID, orgID)
+
+	var site db.Site
+	action := auditSitePause
+	if paused {
+		site, err = server.store.PauseSite(ctx, siteID, orgID)
+	} else {
+		site, err = server.store.ResumeSite(ctx, siteID, orgID)
+		action = auditSiteResume
+	}
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("site not found or not in the expected state"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordSiteAudit(ctx, action, siteID, before, &site)
+
+	ctx.JSON(http.StatusOK, site)
+}
+
+// Rentang waktu yang didukung untuk perhitungan uptime
+var uptimeRanges = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+func (server *Server) getSiteUptime(ctx *gin.Context) {
+	siteID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid site ID"))
+		return
+	}
+
+	window, ok := uptimeRanges[ctx.DefaultQuery("range", "24h")]
+	if !ok {
+		respondError(ctx, http.StatusBadRequest, errors.New("range must be one of 24h, 7d or 30d"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	site, err := server.store.GetSite(ctx, siteID)
+	if err != nil || site.OrganizationID != orgID {
+		if err == nil || errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("site not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	now := time.Now()
+	uptime, err := server.store.GetSiteUptime(ctx, siteID, now.Add(-window), now)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, uptime)
+}
+
+type setSiteTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+func (server *Server) setSiteTags(ctx *gin.Context) {
+	siteID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid site ID"))
+		return
+	}
+
+	var req setSiteTagsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	tags, err := db.NormalizeTags(req.Tags)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	before := server.siteBefore(ctx, siteID, orgID)
+
+	site, err := server.store.SetSiteTags(ctx, siteID, orgID, tags)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("site not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordSiteAudit(ctx, auditSiteUpdate, siteID, before, &site)
+
+	ctx.JSON(http.StatusOK, site)
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+func TestCreateAndListSites(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{
+		"url": "https://example.com", "name": "Example", "tags": []string{"Prod", "eu"},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("create site: status %d: %s", rec.Code, rec.Body)
+	}
+	var site db.Site
+	decodeBody(t, rec, &site)
+	if site.URL != "https://example.com" || site.UserID != user.ID {
+		t.Errorf("created site = %+v", site)
+	}
+	if len(site.Tags) != 2 || site.Tags[0] != "eu" || site.Tags[1] != "prod" {
+		t.Errorf("tags = %v, want normalized [eu prod]", site.Tags)
+	}
+	if site.CheckIntervalSeconds != db.DefaultCheckIntervalSeconds {
+		t.Errorf("check interval = %d, want default %d", site.CheckIntervalSeconds, db.DefaultCheckIntervalSeconds)
+	}
+
+	rec = ts.request(t, http.MethodGet, "/api/sites", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list sites: status %d: %s", rec.Code, rec.Body)
+	}
+	if !bodyContainsSite(t, rec.Body.Bytes(), site.ID) {
+		t.Errorf("list sites does not contain site %d: %s", site.ID, rec.Body)
+	}
+}
+
+func TestCreateSiteValidation(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "not a url", "check_interval_seconds": 5})
+	body := requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+	fields := map[string]bool{}
+	for _, d := range body.Details {
+		fields[d.Field] = true
+	}
+	if !fields["url"] || !fields["check_interval_seconds"] {
+		t.Errorf("details = %v, want url and check_interval_seconds", body.Details)
+	}
+}
+
+func TestUnverifiedUserSiteLimit(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", false)
+	token := ts.login(t, user.Email)
+
+	for i := range unverifiedSiteLimit {
+		rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": fmt.Sprintf("https://example.com/%d", i)})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("create site %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+	}
+	rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "https://example.com/extra"})
+	requireError(t, rec, http.StatusForbidden, codeForbidden)
+}
+
+func TestUnverifiedUserSiteLimitSpansOrganizations(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", false)
+	token := ts.login(t, user.Email)
+
+	for i := range unverifiedSiteLimit {
+		rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": fmt.Sprintf("https://example.com/%d", i)})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("create site %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+	}
+
+	// Organisasi baru tidak memberi kuota baru
+	rec := ts.request(t, http.MethodPost, "/api/organizations", token, gin.H{"name": "Second"})
+	if rec.Code != http.StatusOK && rec.Code != http.StatusCreated {
+		t.Fatalf("create organization: status %d: %s", rec.Code, rec.Body)
+	}
+	var org db.Organization
+	decodeBody(t, rec, &org)
+	rec = ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "https://example.com/extra"}, orgHeader(org.ID)...)
+	requireError(t, rec, http.StatusForbidden, codeForbidden)
+}
+
+func TestSitesAreScopedToOrganization(t *testing.T) {
+	ts := newTestServer(t)
+	alice := ts.createUser(t, "alice", true)
+	bob := ts.createUser(t, "bob", true)
+	aliceToken, bobToken := ts.login(t, alice.Email), ts.login(t, bob.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/sites", aliceToken, gin.H{"url": "https://example.com"})
+	var site db.Site
+	decodeBody(t, rec, &site)
+
+	// Site organisasi lain diperlakukan seolah tidak ada
+	path := fmt.Sprintf("/api/sites/%d", site.ID)
+	rec = ts.request(t, http.MethodDelete, path, bobToken, nil)
+	requireError(t, rec, http.StatusNotFound, codeNotFound)
+
+	// Organisasi yang bukan miliknya juga tidak dapat dipilih lewat header
+	rec = ts.request(t, http.MethodGet, "/api/sites", bobToken, nil, orgHeader(ts.defaultOrganization(t, alice.ID).ID)...)
+	requireError(t, rec, http.StatusNotFound, codeNotFound)
+}
+
+func TestViewerCannotModifySites(t *testing.T) {
+	ts := newTestServer(t)
+	owner := ts.createUser(t, "alice", true)
+	viewer := ts.createUser(t, "bob", true)
+	org := ts.defaultOrganization(t, owner.ID)
+	_, err := ts.store.AddOrganizationMember(context.Background(), db.AddOrganizationMemberParams{
+		OrganizationID: org.ID, UserID: viewer.ID, Role: db.RoleViewer,
+	})
+	if err != nil {
+		t.Fatalf("AddOrganizationMember: %v", err)
+	}
+	token := ts.login(t, viewer.Email)
+
+	rec := ts.request(t, http.MethodGet, "/api/sites", token, nil, orgHeader(org.ID)...)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("viewer list sites: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "https://example.com"}, orgHeader(org.ID)...)
+	requireError(t, rec, http.StatusForbidden, codeForbidden)
+}
+
+func TestDeleteAndRestoreSite(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": "https://example.com"})
+	var site db.Site
+	decodeBody(t, rec, &site)
+
+	rec = ts.request(t, http.MethodDelete, fmt.Sprintf("/api/sites/%d", site.ID), token, nil)
+	if rec.Code != http.StatusOK && rec.Code != http.StatusNoContent {
+		t.Fatalf("delete site: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.request(t, http.MethodGet, "/api/sites", token, nil)
+	if bodyContainsSite(t, rec.Body.Bytes(), site.ID) {
+		t.Errorf("deleted site %d still listed", site.ID)
+	}
+
+	rec = ts.request(t, http.MethodPost, fmt.Sprintf("/api/sites/%d/restore", site.ID), token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore site: status %d: %s", rec.Code, rec.Body)
+	}
+	rec = ts.request(t, http.MethodGet, "/api/sites", token, nil)
+	if !bodyContainsSite(t, rec.Body.Bytes(), site.ID) {
+		t.Errorf("restored site %d not listed", site.ID)
+	}
+}
+
+// bodyContainsSite memeriksa apakah respons GET /api/sites memuat site dengan ID tersebut.
+func bodyContainsSite(t *testing.T, body []byte, siteID int64) bool {
+	t.Helper()
+	var sites []db.SiteListItem
+	if err := json.Unmarshal(body, &sites); err != nil {
+		t.Fatalf("decode site list %s: %v", body, err)
+	}
+	for _, site := range sites {
+		if site.ID == siteID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestListSitesPagination(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	token := ts.login(t, user.Email)
+
+	for i := range 3 {
+		rec := ts.request(t, http.MethodPost, "/api/sites", token, gin.H{"url": fmt.Sprintf("https://example.com/%d", i)})
+		if rec.Code != http.StatusOK {
+			t.Fatalf("create site %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+	}
+
+	// Tanpa limit dan cursor responsnya tetap array berisi semua site
+	rec := ts.request(t, http.MethodGet, "/api/sites", token, nil)
+	var all []db.SiteListItem
+	decodeBody(t, rec, &all)
+	if len(all) != 3 {
+		t.Fatalf("got %d sites, want 3", len(all))
+	}
+
+	var seen []int64
+	cursor := ""
+	for range 3 {
+		rec := ts.request(t, http.MethodGet, "/api/sites?limit=2&cursor="+cursor, token, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list page: status %d: %s", rec.Code, rec.Body)
+		}
+		var page db.ListSitesResult
+		decodeBody(t, rec, &page)
+		if page.Total != 3 {
+			t.Errorf("total = %d, want 3", page.Total)
+		}
+		for _, site := range page.Sites {
+			seen = append(seen, site.ID)
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(seen) != 3 || seen[0] != all[0].ID || seen[2] != all[2].ID {
+		t.Errorf("paged site IDs = %v, want the same order as %v", seen, all)
+	}
+
+	rec = ts.request(t, http.MethodGet, "/api/sites?cursor=bogus", token, nil)
+	requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+}
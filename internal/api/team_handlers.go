@@ -0,0 +1,217 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+var errTeamNameTaken = newAPIError(codeTeamNameTaken, "a team with this name already exists")
+
+// validateTeamMembers memastikan semua user yang akan dimasukkan ke tim adalah anggota organisasi.
+func (server *Server) validateTeamMembers(ctx context.Context, orgID int64, userIDs []int64) error {
+	members, err := server.store.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	isMember := make(map[int64]bool, len(members))
+	for _, m := range members {
+		isMember[m.UserID] = true
+	}
+	for _, id := range userIDs {
+		if !isMember[id] {
+			return fmt.Errorf("user %d is not a member of the organization", id)
+		}
+	}
+	return nil
+}
+
+// teamNameTaken memeriksa apakah nama tim sudah dipakai tim lain di organisasi yang sama.
+func (server *Server) teamNameTaken(ctx context.Context, orgID, teamID int64, name string) (bool, error) {
+	teams, err := server.store.ListTeams(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range teams {
+		if t.ID != teamID && t.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type createTeamRequest struct {
+	Name    string  `json:"name" binding:"required,max=100"`
+	UserIDs []int64 `json:"user_ids"`
+}
+
+func (server *Server) createTeam(ctx *gin.Context) {
+	var req createTeamRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	if err := server.validateTeamMembers(ctx, orgID, req.UserIDs); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if taken, err := server.teamNameTaken(ctx, orgID, 0, req.Name); err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	} else if taken {
+		respondError(ctx, http.StatusConflict, errTeamNameTaken)
+		return
+	}
+
+	team, err := server.store.CreateTeam(ctx, db.CreateTeamParams{
+		OrganizationID: orgID,
+		Name:           req.Name,
+		UserIDs:        req.UserIDs,
+	})
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditTeamCreate,
+		TargetType: auditTargetTeam,
+		TargetID:   team.ID,
+		Changes:    db.AuditDiff(nil, team),
+	})
+
+	ctx.JSON(http.StatusOK, team)
+}
+
+func (server *Server) listTeams(ctx *gin.Context) {
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	teams, err := server.store.ListTeams(ctx, orgID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, teams)
+}
+
+func (server *Server) getTeam(ctx *gin.Context) {
+	teamID, err := strconv.ParseInt(ctx.Param("team_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid team ID"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	team, err := server.store.GetTeam(ctx, teamID, orgID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("team not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, team)
+}
+
+type updateTeamRequest struct {
+	Name    *string  `json:"name" binding:"omitempty,min=1,max=100"`
+	UserIDs *[]int64 `json:"user_ids"`
+}
+
+func (server *Server) updateTeam(ctx *gin.Context) {
+	teamID, err := strconv.ParseInt(ctx.Param("team_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid team ID"))
+		return
+	}
+
+	var req updateTeamRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	if req.UserIDs != nil {
+		if err := server.validateTeamMembers(ctx, orgID, *req.UserIDs); err != nil {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+	}
+	if req.Name != nil {
+		if taken, err := server.teamNameTaken(ctx, orgID, teamID, *req.Name); err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		} else if taken {
+			respondError(ctx, http.StatusConflict, errTeamNameTaken)
+			return
+		}
+	}
+
+	before, _ := server.store.GetTeam(ctx, teamID, orgID)
+
+	team, err := server.store.UpdateTeam(ctx, teamID, orgID, db.UpdateTeamParams{
+		Name:    req.Name,
+		UserIDs: req.UserIDs,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("team not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditTeamUpdate,
+		TargetType: auditTargetTeam,
+		TargetID:   teamID,
+		Changes:    db.AuditDiff(before, team),
+	})
+
+	ctx.JSON(http.StatusOK, team)
+}
+
+func (server *Server) deleteTeam(ctx *gin.Context) {
+	teamID, err := strconv.ParseInt(ctx.Param("team_id"), 10, 64)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid team ID"))
+		return
+	}
+
+	orgID := ctx.GetInt64(authorizationOrganizationKey)
+
+	before, _ := server.store.GetTeam(ctx, teamID, orgID)
+
+	if err := server.store.DeleteTeam(ctx, teamID, orgID); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusNotFound, errors.New("team not found"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		Action:     auditTeamDelete,
+		TargetType: auditTargetTeam,
+		TargetID:   teamID,
+		Changes:    db.AuditDiff(before, nil),
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "team deleted successfully"})
+}
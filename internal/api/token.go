@@ -1,20 +1,123 @@
 package api
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
 )
 
-func generateToken(userID int64, ttl time.Duration) (string, error) {
+const (
+	// Access token sengaja berumur pendek; client memperbaruinya lewat /api/auth/refresh
+	accessTokenTTL = 15 * time.Minute
+	// Masa berlaku refresh token, diperpanjang setiap kali token dirotasi
+	refreshTokenTTL = 30 * 24 * time.Hour
+	// Masa berlaku token reset password yang dikirim lewat email
+	passwordResetTokenTTL = time.Hour
+	// Masa berlaku link verifikasi email
+	emailVerificationTokenTTL = 48 * time.Hour
+	// Waktu yang dimiliki user untuk memasukkan kode 2FA setelah password-nya benar
+	twoFactorChallengeTTL = 5 * time.Minute
+	// Waktu yang dimiliki user untuk login di identity provider OIDC
+	oidcLoginTTL = 10 * time.Minute
+)
+
+// Nilai claim purpose untuk token yang bukan access token. Token ini tidak memiliki claim sid sehingga
+// authMiddleware tidak menerimanya sebagai access token.
+const (
+	emailVerificationPurpose  = "verify_email"
+	twoFactorChallengePurpose = "2fa_challenge"
+	oidcLoginPurpose          = "oidc_login"
+)
+
+// generateToken membuat access token untuk session tertentu. Claim sid dipakai middleware
+// untuk menolak token dari session yang sudah dicabut.
+func generateToken(userID int64, sessionID int64, ttl time.Duration) (string, error) {
 	secretKey := os.Getenv("JWT_SECRET")
-    
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"sub": userID,
+		"sid": sessionID,
 		"iat": time.Now().Unix(),
 		"exp": time.Now().Add(ttl).Unix(),
 	})
 
 	return token.SignedString([]byte(secretKey))
-}
\ No newline at end of file
+}
+
+// generatePurposeToken membuat token bertanda tangan untuk satu keperluan selain akses API,
+// mis. link verifikasi email. claims berisi claim tambahan selain sub, purpose, iat dan exp.
+func generatePurposeToken(purpose string, userID int64, claims jwt.MapClaims, ttl time.Duration) (string, error) {
+	secretKey := os.Getenv("JWT_SECRET")
+
+	all := jwt.MapClaims{
+		"sub":     userID,
+		"purpose": purpose,
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(ttl).Unix(),
+	}
+	for k, v := range claims {
+		all[k] = v
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, all).SignedString([]byte(secretKey))
+}
+
+// parsePurposeToken memvalidasi token dari generatePurposeToken dan mengembalikan user ID serta semua claim-nya.
+func parsePurposeToken(tokenString string, purpose string) (int64, jwt.MapClaims, error) {
+	secretKey := os.Getenv("JWT_SECRET")
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	userID, ok := claims["sub"].(float64)
+	if !ok || claims["purpose"] != purpose {
+		return 0, nil, errors.New("invalid token purpose")
+	}
+	return int64(userID), claims, nil
+}
+
+// generateEmailVerificationToken membuat token untuk link verifikasi email. Email ikut ditandatangani
+// agar link tidak berlaku lagi jika email user berubah.
+func generateEmailVerificationToken(user db.User) (string, error) {
+	return generatePurposeToken(emailVerificationPurpose, user.ID, jwt.MapClaims{"email": user.Email}, emailVerificationTokenTTL)
+}
+
+// parseEmailVerificationToken memvalidasi token dari link verifikasi dan mengembalikan user ID serta email-nya.
+func parseEmailVerificationToken(tokenString string) (int64, string, error) {
+	userID, claims, err := parsePurposeToken(tokenString, emailVerificationPurpose)
+	if err != nil {
+		return 0, "", err
+	}
+	email, ok := claims["email"].(string)
+	if !ok {
+		return 0, "", errors.New("invalid verification token")
+	}
+	return userID, email, nil
+}
+
+// newOpaqueToken membuat token acak yang dikirim ke client beserta hash SHA-256 yang disimpan di database.
+func newOpaqueToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/totp"
+)
+
+// Nama yang ditampilkan aplikasi authenticator untuk akun Go-Pulse
+const totpIssuer = "Go-Pulse"
+
+// Jumlah recovery code yang dibuat saat 2FA diaktifkan
+const recoveryCodeCount = 10
+
+var errInvalidTwoFactorCode = newAPIError(codeInvalidTwoFactorCode, "invalid or already used two-factor code")
+
+type twoFactorChallengeResponse struct {
+	TwoFactorRequired bool `json:"two_factor_required"`
+	// ChallengeToken dikirim kembali ke /api/auth/2fa/verify bersama kode TOTP atau recovery code
+	ChallengeToken     string    `json:"challenge_token"`
+	ChallengeExpiresAt time.Time `json:"challenge_expires_at"`
+}
+
+// newTwoFactorChallenge dipakai loginUser sebagai pengganti token jika 2FA user aktif.
+func newTwoFactorChallenge(userID int64) (twoFactorChallengeResponse, error) {
+	token, err := generatePurposeToken(twoFactorChallengePurpose, userID, nil, twoFactorChallengeTTL)
+	if err != nil {
+		return twoFactorChallengeResponse{}, err
+	}
+	return twoFactorChallengeResponse{
+		TwoFactorRequired:  true,
+		ChallengeToken:     token,
+		ChallengeExpiresAt: time.Now().Add(twoFactorChallengeTTL),
+	}, nil
+}
+
+type verifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	// Code adalah kode TOTP 6 digit atau salah satu recovery code
+	Code string `json:"code" binding:"required"`
+}
+
+// verifyTwoFactorLogin menyelesaikan login yang memerlukan 2FA dan mengembalikan token seperti loginUser.
+func (server *Server) verifyTwoFactorLogin(ctx *gin.Context) {
+	var req verifyTwoFactorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _, err := parsePurposeToken(req.ChallengeToken, twoFactorChallengePurpose)
+	if err != nil {
+		respondError(ctx, http.StatusUnauthorized, errors.New("invalid or expired two-factor challenge"))
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// Kode 2FA yang salah dihitung sebagai login gagal untuk akun yang sama
+	if server.checkLoginLock(ctx, user.Email) {
+		return
+	}
+
+	if err := server.verifySecondFactor(ctx, userID, req.Code); err != nil {
+		if errors.Is(err, errInvalidTwoFactorCode) {
+			server.recordAudit(ctx, db.CreateAuditEventParams{
+				UserID:     userID,
+				Action:     auditLoginFailed,
+				TargetType: auditTargetUser,
+				TargetID:   userID,
+				Details:    map[string]any{"reason": "invalid two-factor code"},
+			})
+			server.recordLoginFailure(ctx, user.Email, &user)
+			respondError(ctx, http.StatusUnauthorized, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.resetLoginFailures(ctx, user.Email)
+
+	rsp, err := server.startSession(ctx, user)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type enrollTOTPResponse struct {
+	Secret string `json:"secret"`
+	// OtpauthURI dapat ditampilkan sebagai QR code untuk dipindai aplikasi authenticator
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+// enrollTOTP membuat secret TOTP baru. 2FA belum aktif sampai secret dikonfirmasi lewat confirmTOTP.
+func (server *Server) enrollTOTP(ctx *gin.Context) {
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	user, err := server.store.GetUser(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := server.store.CreateTOTPEnrollment(ctx, userID, secret); err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusConflict, errors.New("two-factor authentication is already enabled"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		Action:     auditTwoFactorEnroll,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+	})
+
+	ctx.JSON(http.StatusOK, enrollTOTPResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(totpIssuer, user.Email, secret),
+	})
+}
+
+type twoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type confirmTOTPResponse struct {
+	// RecoveryCodes hanya ditampilkan sekali; masing-masing dapat dipakai satu kali sebagai pengganti kode TOTP
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// confirmTOTP mengaktifkan 2FA setelah user memasukkan kode pertama dari aplikasi authenticator.
+func (server *Server) confirmTOTP(ctx *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	enrollment, err := server.store.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusBadRequest, errors.New("start two-factor enrolment first"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if enrollment.Enabled() {
+		respondError(ctx, http.StatusConflict, errors.New("two-factor authentication is already enabled"))
+		return
+	}
+
+	step, ok := totp.Validate(enrollment.Secret, req.Code, time.Now())
+	if !ok {
+		respondError(ctx, http.StatusBadRequest, errInvalidTwoFactorCode)
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	err = server.store.ConfirmTOTP(ctx, db.ConfirmTOTPParams{UserID: userID, Step: step, RecoveryCodeHashes: hashes})
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusConflict, errors.New("two-factor authentication is already enabled"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		Action:     auditTwoFactorEnable,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+	})
+
+	ctx.JSON(http.StatusOK, confirmTOTPResponse{RecoveryCodes: codes})
+}
+
+// disableTOTP menonaktifkan 2FA; user harus memasukkan kode TOTP atau recovery code yang masih berlaku.
+func (server *Server) disableTOTP(ctx *gin.Context) {
+	var req twoFactorCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	if err := server.verifySecondFactor(ctx, userID, req.Code); err != nil {
+		if errors.Is(err, errInvalidTwoFactorCode) {
+			respondError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := server.store.DeleteTOTP(ctx, userID); err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     userID,
+		Action:     auditTwoFactorDisable,
+		TargetType: auditTargetUser,
+		TargetID:   userID,
+	})
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "two-factor authentication disabled"})
+}
+
+// verifySecondFactor menerima kode TOTP atau recovery code milik user. Kode TOTP dari periode yang sudah
+// dipakai dan recovery code yang sudah terpakai ditolak dengan errInvalidTwoFactorCode.
+func (server *Server) verifySecondFactor(ctx context.Context, userID int64, code string) error {
+	enrollment, err := server.store.GetTOTP(ctx, userID)
+	if errors.Is(err, db.ErrRecordNotFound) || (err == nil && !enrollment.Enabled()) {
+		return errInvalidTwoFactorCode
+	}
+	if err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+		if !ok {
+			return errInvalidTwoFactorCode
+		}
+		err = server.store.UseTOTPStep(ctx, userID, step)
+	} else {
+		err = server.store.UseRecoveryCode(ctx, userID, hashToken(normalizeRecoveryCode(code)))
+	}
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return errInvalidTwoFactorCode
+	}
+	return err
+}
+
+// newRecoveryCodes membuat recovery code acak berformat xxxxx-xxxxx beserta hash-nya.
+func newRecoveryCodes() (codes []string, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range recoveryCodeCount {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		codes = append(codes, raw[:5]+"-"+raw[5:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode mengabaikan huruf besar, spasi dan tanda hubung yang diketik user.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
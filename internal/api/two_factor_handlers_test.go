@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/totp"
+)
+
+// enableTwoFactor mengaktifkan TOTP untuk user lewat API dan mengembalikan recovery code-nya.
+func (ts *testServer) enableTwoFactor(t *testing.T, token string) []string {
+	t.Helper()
+	rec := ts.request(t, http.MethodPost, "/api/auth/2fa/totp", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("enroll: status %d: %s", rec.Code, rec.Body)
+	}
+	var enrollment enrollTOTPResponse
+	decodeBody(t, rec, &enrollment)
+
+	code, err := totp.Code(enrollment.Secret, totp.Step(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec = ts.request(t, http.MethodPost, "/api/auth/2fa/totp/confirm", token, gin.H{"code": code})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("confirm: status %d: %s", rec.Code, rec.Body)
+	}
+	var confirmed confirmTOTPResponse
+	decodeBody(t, rec, &confirmed)
+	return confirmed.RecoveryCodes
+}
+
+func TestLoginWithTwoFactorRequiresChallenge(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", true)
+	recoveryCodes := ts.enableTwoFactor(t, ts.login(t, user.Email))
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": user.Email, "password": testPassword})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login: status %d: %s", rec.Code, rec.Body)
+	}
+	var challenge struct {
+		twoFactorChallengeResponse
+		Token string `json:"token"`
+	}
+	decodeBody(t, rec, &challenge)
+	if !challenge.TwoFactorRequired || challenge.ChallengeToken == "" || challenge.Token != "" {
+		t.Fatalf("login response = %s, want a challenge without a token", rec.Body)
+	}
+
+	// Token challenge bukan access token
+	rec = ts.request(t, http.MethodGet, "/api/sites", challenge.ChallengeToken, nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+
+	rec = ts.request(t, http.MethodPost, "/api/auth/2fa/verify", "", gin.H{"challenge_token": challenge.ChallengeToken, "code": "000000"})
+	requireError(t, rec, http.StatusUnauthorized, codeInvalidTwoFactorCode)
+
+	rec = ts.request(t, http.MethodPost, "/api/auth/2fa/verify", "", gin.H{"challenge_token": challenge.ChallengeToken, "code": recoveryCodes[0]})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verify: status %d: %s", rec.Code, rec.Body)
+	}
+	var session loginUserResponse
+	decodeBody(t, rec, &session)
+	if rec := ts.request(t, http.MethodGet, "/api/sites", session.Token, nil); rec.Code != http.StatusOK {
+		t.Errorf("token from 2FA verify rejected: status %d: %s", rec.Code, rec.Body)
+	}
+
+	// Recovery code hanya bisa dipakai sekali
+	rec = ts.request(t, http.MethodPost, "/api/auth/2fa/verify", "", gin.H{"challenge_token": challenge.ChallengeToken, "code": recoveryCodes[0]})
+	requireError(t, rec, http.StatusUnauthorized, codeInvalidTwoFactorCode)
+}
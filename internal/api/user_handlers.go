@@ -1,11 +1,16 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/mail"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -15,16 +20,21 @@ type registerUserRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+// Respons registrasi sama untuk email baru dan email yang sudah terdaftar
+const registerUserStatus = "registration received, check your email to continue"
+
+// registerUser membuat akun baru. Jika email sudah terdaftar, akun tidak dibuat dan pemiliknya diberi tahu
+// lewat email; responsnya tetap sama agar endpoint ini tidak dapat dipakai untuk menebak email terdaftar.
 func (server *Server) registerUser(ctx *gin.Context) {
 	var req registerUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -34,14 +44,63 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		PasswordHash: string(hashedPassword),
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	existing, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err == nil {
+		server.sendExistingAccountEmail(existing)
+		ctx.JSON(http.StatusOK, gin.H{"status": registerUserStatus})
+		return
+	} else if !errors.Is(err, db.ErrRecordNotFound) {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	user, err := createUser(ctx, server.store, arg)
 	if err != nil {
-		// Nanti di sini kita bisa cek error spesifik, misal email sudah ada
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		// Email bisa saja didaftarkan request lain setelah pengecekan di atas
+		if errors.Is(err, db.ErrConflict) {
+			if existing, err := server.store.GetUserByEmail(ctx, req.Email); err == nil {
+				server.sendExistingAccountEmail(existing)
+			}
+			ctx.JSON(http.StatusOK, gin.H{"status": registerUserStatus})
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, user)
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     user.ID,
+		ActorID:    user.ID,
+		Action:     auditUserRegister,
+		TargetType: auditTargetUser,
+		TargetID:   user.ID,
+		Changes:    db.AuditDiff(nil, user),
+	})
+
+	// Akun baru belum terverifikasi sampai link di email dibuka
+	server.sendVerificationEmail(user)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": registerUserStatus})
+}
+
+// sendExistingAccountEmail memberi tahu pemilik email bahwa ada yang mencoba mendaftar dengan email-nya.
+func (server *Server) sendExistingAccountEmail(user db.User) {
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Your Go-Pulse account already exists",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone tried to create a new Go-Pulse account with this email address, "+
+			"but it already belongs to your account. If this was you, sign in or reset your password here:\n\n%s\n\n"+
+			"If you did not try to sign up, you can ignore this email.\n",
+			user.Username, appBaseURL()+"/login"),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Registration: failed to send existing account email to user %d: %v", user.ID, err)
+		}
+	}()
 }
 
 type loginUserRequest struct {
@@ -52,42 +111,123 @@ type loginUserRequest struct {
 type loginUserResponse struct {
 	Token string   `json:"token"`
 	User  db.User `json:"user"`
+	// Refresh token hanya dikirim sekali; simpan untuk memperbarui access token lewat /api/auth/refresh
+	RefreshToken          string    `json:"refresh_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
 }
 
 
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// Penguncian dicek sebelum password, sehingga password yang benar pun ditolak selama akun dikunci
+	if server.checkLoginLock(ctx, req.Email) {
 		return
 	}
 
 	user, err := server.store.GetUserByEmail(ctx, req.Email)
 	if err != nil {
-		// Jika user tidak ditemukan, beri pesan unauthorized
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		// Email tidak terdaftar diperlakukan sama dengan password salah, termasuk waktu respons-nya
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+		server.recordAudit(ctx, db.CreateAuditEventParams{
+			Action:     auditLoginFailed,
+			TargetType: auditTargetUser,
+			Details:    map[string]any{"email": req.Email, "reason": "unknown email"},
+		})
+		server.recordLoginFailure(ctx, req.Email, nil)
+		respondError(ctx, http.StatusUnauthorized, errInvalidCredentials)
 		return
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
-		// Jika password salah
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		server.recordAudit(ctx, db.CreateAuditEventParams{
+			UserID:     user.ID,
+			Action:     auditLoginFailed,
+			TargetType: auditTargetUser,
+			TargetID:   user.ID,
+			Details:    map[string]any{"email": req.Email, "reason": "invalid password"},
+		})
+		server.recordLoginFailure(ctx, req.Email, &user)
+		respondError(ctx, http.StatusUnauthorized, errInvalidCredentials)
 		return
 	}
-	
-	// Jika berhasil, generate token JWT
-	// (Kita akan buat fungsi generateToken di langkah selanjutnya)
-	token, err := generateToken(user.ID, 3 * time.Hour) // Token valid selama 3 jam
-    if err != nil {
-        ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-        return
-    }
-	
-	rsp := loginUserResponse{
-		Token: token,
-		User: user,
+
+	// Hitungan login gagal hanya dihapus setelah 2FA juga lolos, agar kode 2FA tidak dapat ditebak tanpa batas
+
+	// Jika 2FA aktif, token baru diberikan setelah kode 2FA diverifikasi di /api/auth/2fa/verify
+	enrollment, err := server.store.GetTOTP(ctx, user.ID)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if err == nil && enrollment.Enabled() {
+		challenge, err := newTwoFactorChallenge(user.ID)
+		if err != nil {
+			respondError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		ctx.JSON(http.StatusOK, challenge)
+		return
+	}
+
+	server.resetLoginFailures(ctx, req.Email)
+
+	// Jika berhasil, buat session baru beserta access token dan refresh token-nya
+	rsp, err := server.startSession(ctx, user)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, rsp)
-}
\ No newline at end of file
+}
+
+// startSession membuat session baru untuk user yang berhasil login.
+func (server *Server) startSession(ctx *gin.Context, user db.User) (loginUserResponse, error) {
+	refreshToken, refreshTokenHash, err := newOpaqueToken()
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
+	session, err := server.store.CreateSession(ctx, db.CreateSessionParams{
+		UserID:           user.ID,
+		RefreshTokenHash: refreshTokenHash,
+		UserAgent:        ctx.Request.UserAgent(),
+		IPAddress:        ctx.ClientIP(),
+		ExpiresAt:        time.Now().Add(refreshTokenTTL),
+	})
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
+	token, err := generateToken(user.ID, session.ID, accessTokenTTL)
+	if err != nil {
+		return loginUserResponse{}, err
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     user.ID,
+		ActorID:    user.ID,
+		Action:     auditLogin,
+		TargetType: auditTargetSession,
+		TargetID:   session.ID,
+	})
+
+	return loginUserResponse{
+		Token:                 token,
+		User:                  user,
+		RefreshToken:          refreshToken,
+		AccessTokenExpiresAt:  time.Now().Add(accessTokenTTL),
+		RefreshTokenExpiresAt: session.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRegisterThenLogin(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/register", "", gin.H{
+		"username": "alice", "email": "alice@example.com", "password": testPassword,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("register: status %d: %s", rec.Code, rec.Body)
+	}
+	if msg := <-ts.mailer.sent; msg.To != "alice@example.com" {
+		t.Errorf("verification email sent to %q", msg.To)
+	}
+
+	token := ts.login(t, "alice@example.com")
+	rec = ts.request(t, http.MethodGet, "/api/organizations", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list organizations: status %d: %s", rec.Code, rec.Body)
+	}
+	var orgs []map[string]any
+	decodeBody(t, rec, &orgs)
+	if len(orgs) != 1 || orgs[0]["role"] != "owner" {
+		t.Errorf("new user organizations = %v, want one owned organization", orgs)
+	}
+}
+
+func TestRegisterExistingEmailLooksLikeNewAccount(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createUser(t, "alice", true)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/register", "", gin.H{
+		"username": "mallory", "email": "alice@example.com", "password": testPassword,
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("register: status %d: %s", rec.Code, rec.Body)
+	}
+	var body map[string]string
+	decodeBody(t, rec, &body)
+	if body["status"] != registerUserStatus {
+		t.Errorf("status = %q, want %q", body["status"], registerUserStatus)
+	}
+	if msg := <-ts.mailer.sent; msg.Subject != "Your Go-Pulse account already exists" {
+		t.Errorf("email subject = %q", msg.Subject)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/register", "", gin.H{
+		"username": "alice", "email": "not-an-email", "password": "123",
+	})
+	body := requireError(t, rec, http.StatusBadRequest, codeValidationFailed)
+	fields := map[string]bool{}
+	for _, d := range body.Details {
+		fields[d.Field] = true
+	}
+	if !fields["email"] || !fields["password"] {
+		t.Errorf("details = %v, want email and password", body.Details)
+	}
+}
+
+func TestLoginRejectsInvalidCredentialsUniformly(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createUser(t, "alice", true)
+
+	for _, req := range []gin.H{
+		{"email": "alice@example.com", "password": "wrong-password"},
+		{"email": "nobody@example.com", "password": testPassword},
+	} {
+		rec := ts.request(t, http.MethodPost, "/api/auth/login", "", req)
+		body := requireError(t, rec, http.StatusUnauthorized, codeInvalidCredentials)
+		if body.Message != errInvalidCredentials.Error() {
+			t.Errorf("message for %v = %q", req["email"], body.Message)
+		}
+	}
+}
+
+func TestLoginLocksAfterRepeatedFailures(t *testing.T) {
+	ts := newTestServer(t)
+	ts.createUser(t, "alice", true)
+
+	for range loginLockThreshold {
+		rec := ts.request(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": "alice@example.com", "password": "wrong-password"})
+		requireError(t, rec, http.StatusUnauthorized, codeInvalidCredentials)
+	}
+
+	// Password yang benar pun ditolak selama akun dikunci
+	rec := ts.request(t, http.MethodPost, "/api/auth/login", "", gin.H{"email": "alice@example.com", "password": testPassword})
+	requireError(t, rec, http.StatusTooManyRequests, codeLoginLocked)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("locked login response has no Retry-After header")
+	}
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.request(t, http.MethodGet, "/api/sites", "", nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+
+	rec = ts.request(t, http.MethodGet, "/api/sites", "not-a-token", nil)
+	requireError(t, rec, http.StatusUnauthorized, codeUnauthorized)
+}
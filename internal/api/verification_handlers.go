@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/mail"
+)
+
+// Jumlah site aktif maksimum untuk akun yang email-nya belum terverifikasi
+const unverifiedSiteLimit = 3
+
+var errSiteLimitReached = fmt.Errorf("verify your email address to monitor more than %d sites", unverifiedSiteLimit)
+
+// remainingSites mengembalikan jumlah site yang masih boleh ditambahkan user, atau -1 jika tidak dibatasi.
+// Batas untuk akun yang belum terverifikasi dihitung dari site aktif yang dibuat user di semua organisasi,
+// sehingga tidak bisa dilewati dengan membuat organisasi baru.
+func (server *Server) remainingSites(ctx context.Context, userID int64) (int, error) {
+	user, err := server.store.GetUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if user.Verified() {
+		return -1, nil
+	}
+	count, err := server.store.CountActiveSitesByUserID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	return max(unverifiedSiteLimit-count, 0), nil
+}
+
+// sendVerificationEmail mengirim link verifikasi email ke user di background.
+func (server *Server) sendVerificationEmail(user db.User) {
+	token, err := generateEmailVerificationToken(user)
+	if err != nil {
+		log.Printf("Email verification: failed to sign token for user %d: %v", user.ID, err)
+		return
+	}
+
+	link := appBaseURL() + "/verify-email?token=" + url.QueryEscape(token)
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "Verify your Go-Pulse email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in %d hours. Until your email is verified you can monitor at most %d sites "+
+			"and will not receive email alerts.\n",
+			user.Username, link, int(emailVerificationTokenTTL.Hours()), unverifiedSiteLimit),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.mailer.Send(ctx, msg); err != nil {
+			log.Printf("Email verification: failed to send email to user %d: %v", user.ID, err)
+		}
+	}()
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// verifyEmail mengonfirmasi email user dengan token dari link verifikasi.
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		respondError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, email, err := parseEmailVerificationToken(req.Token)
+	if err != nil {
+		respondError(ctx, http.StatusBadRequest, errors.New("invalid or expired verification link"))
+		return
+	}
+
+	user, err := server.store.VerifyUserEmail(ctx, userID, email)
+	if err != nil {
+		if errors.Is(err, db.ErrRecordNotFound) {
+			respondError(ctx, http.StatusBadRequest, errors.New("invalid or expired verification link"))
+			return
+		}
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	server.recordAudit(ctx, db.CreateAuditEventParams{
+		UserID:     user.ID,
+		ActorID:    user.ID,
+		Action:     auditUserVerifyEmail,
+		TargetType: auditTargetUser,
+		TargetID:   user.ID,
+		Changes:    map[string]db.AuditChange{"email_verified_at": {After: user.EmailVerifiedAt}},
+	})
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// resendVerificationEmail mengirim ulang link verifikasi ke email user yang sedang login, paling banyak
+// verificationResendRateLimit kali per verificationResendRateWindow.
+func (server *Server) resendVerificationEmail(ctx *gin.Context) {
+	authPayload, exists := ctx.Get(authorizationPayloadKey)
+	if !exists {
+		respondError(ctx, http.StatusUnauthorized, errors.New("authorization payload does not exist"))
+		return
+	}
+	userID := authPayload.(int64)
+
+	user, err := server.store.GetUser(ctx, userID)
+	if err != nil {
+		respondError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if user.Verified() {
+		respondError(ctx, http.StatusConflict, errors.New("email address is already verified"))
+		return
+	}
+	if ok, retryAfter := server.verificationEmails.allow(strconv.FormatInt(userID, 10), time.Now()); !ok {
+		setRetryAfter(ctx, retryAfter)
+		respondError(ctx, http.StatusTooManyRequests, errRateLimited)
+		return
+	}
+
+	server.sendVerificationEmail(user)
+
+	ctx.JSON(http.StatusOK, gin.H{"status": "verification email sent"})
+}
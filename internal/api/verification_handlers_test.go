@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+var verifyLinkPattern = regexp.MustCompile(`/verify-email\?token=(\S+)`)
+
+func TestVerifyEmailWithResentLink(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", false)
+	token := ts.login(t, user.Email)
+
+	rec := ts.request(t, http.MethodPost, "/api/auth/verify-email/resend", token, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("resend: status %d: %s", rec.Code, rec.Body)
+	}
+	match := verifyLinkPattern.FindStringSubmatch(ts.waitForMail(t))
+	if match == nil {
+		t.Fatal("email does not contain a verification link")
+	}
+	verifyToken, _ := url.QueryUnescape(match[1])
+
+	rec = ts.request(t, http.MethodPost, "/api/auth/verify-email", "", gin.H{"token": verifyToken})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verify: status %d: %s", rec.Code, rec.Body)
+	}
+	var verified db.User
+	decodeBody(t, rec, &verified)
+	if !verified.Verified() {
+		t.Error("user is not verified after following the link")
+	}
+
+	rec = ts.request(t, http.MethodPost, "/api/auth/verify-email/resend", token, nil)
+	requireError(t, rec, http.StatusConflict, codeConflict)
+	rec = ts.request(t, http.MethodPost, "/api/auth/verify-email", "", gin.H{"token": "invalid"})
+	requireError(t, rec, http.StatusBadRequest, codeBadRequest)
+}
+
+func TestResendVerificationEmailIsThrottled(t *testing.T) {
+	ts := newTestServer(t)
+	user := ts.createUser(t, "alice", false)
+	token := ts.login(t, user.Email)
+
+	for i := range verificationResendRateLimit {
+		if rec := ts.request(t, http.MethodPost, "/api/auth/verify-email/resend", token, nil); rec.Code != http.StatusOK {
+			t.Fatalf("resend %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+	}
+	rec := ts.request(t, http.MethodPost, "/api/auth/verify-email/resend", token, nil)
+	requireError(t, rec, http.StatusTooManyRequests, codeRateLimited)
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("throttled response has no Retry-After header")
+	}
+}
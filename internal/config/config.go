@@ -0,0 +1,197 @@
+// Package config mengekspor dan menerapkan konfigurasi monitor milik organisasi secara deklaratif (YAML atau JSON).
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"gopkg.in/yaml.v3"
+)
+
+// Versi format dokumen yang dihasilkan dan diterima
+const CurrentVersion = 1
+
+// Format dokumen yang didukung
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Document adalah seluruh konfigurasi monitor milik satu organisasi.
+type Document struct {
+	Version int     `json:"version" yaml:"version"`
+	Sites   []Site  `json:"sites" yaml:"sites"`
+	Groups  []Group `json:"groups,omitempty" yaml:"groups,omitempty"`
+}
+
+// Site diidentifikasi dengan URL-nya; setiap URL hanya boleh muncul sekali dalam dokumen.
+// Field yang tidak diisi memakai nilai default yang sama dengan pembuatan site lewat API.
+type Site struct {
+	URL                  string   `json:"url" yaml:"url"`
+	Name                 string   `json:"name,omitempty" yaml:"name,omitempty"`
+	Description          string   `json:"description,omitempty" yaml:"description,omitempty"`
+	CheckType            string   `json:"check_type,omitempty" yaml:"check_type,omitempty"`
+	CheckIntervalSeconds int      `json:"check_interval_seconds,omitempty" yaml:"check_interval_seconds,omitempty"`
+	TimeoutSeconds       int      `json:"timeout_seconds,omitempty" yaml:"timeout_seconds,omitempty"`
+	Paused               bool     `json:"paused,omitempty" yaml:"paused,omitempty"`
+	Tags                 []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// Group diidentifikasi dengan namanya; anggotanya dirujuk dengan URL site di dokumen yang sama.
+type Group struct {
+	Name  string   `json:"name" yaml:"name"`
+	Sites []string `json:"sites" yaml:"sites"`
+}
+
+// ValidationError berisi semua kesalahan yang ditemukan pada dokumen.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		problems[i] = p.String()
+	}
+	return "invalid configuration: " + strings.Join(problems, "; ")
+}
+
+// Problem adalah satu kesalahan pada dokumen. Field berisi path field, misalnya "sites[0].url", dan kosong
+// jika dokumen tidak dapat dibaca sama sekali.
+type Problem struct {
+	Field   string
+	Message string
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return p.Message
+	}
+	return p.Field + ": " + p.Message
+}
+
+// Parse membaca dokumen dalam format yang diberikan. Field yang tidak dikenal ditolak
+// agar salah ketik tidak diam-diam diabaikan.
+func Parse(data []byte, format string) (Document, error) {
+	var doc Document
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&doc); err != nil {
+			return Document{}, &ValidationError{Problems: []Problem{{Message: err.Error()}}}
+		}
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&doc); err != nil {
+			return Document{}, &ValidationError{Problems: []Problem{{Message: err.Error()}}}
+		}
+	default:
+		return Document{}, fmt.Errorf("unsupported format %q", format)
+	}
+	return doc, nil
+}
+
+// Marshal menulis dokumen dalam format yang diberikan.
+func Marshal(doc Document, format string) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(doc, "", "  ")
+	case FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// normalize memvalidasi dokumen dan mengisi nilai default. Batasan nilainya sama dengan validasi pada API site.
+func (doc Document) normalize() (Document, error) {
+	var problems []Problem
+	addProblem := func(field, format string, args ...any) {
+		problems = append(problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if doc.Version != CurrentVersion {
+		addProblem("version", "must be %d", CurrentVersion)
+	}
+
+	urls := map[string]bool{}
+	sites := make([]Site, 0, len(doc.Sites))
+	for i, site := range doc.Sites {
+		field := fmt.Sprintf("sites[%d]", i)
+		u, err := url.Parse(site.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			addProblem(field+".url", "must be an absolute http or https URL")
+		}
+		if urls[site.URL] {
+			addProblem(field+".url", "duplicate site %s", site.URL)
+		}
+		urls[site.URL] = true
+
+		if len(site.Name) > 100 {
+			addProblem(field+".name", "must be at most 100 characters")
+		}
+		if len(site.Description) > 500 {
+			addProblem(field+".description", "must be at most 500 characters")
+		}
+		if site.CheckType == "" {
+			site.CheckType = db.CheckTypeHTTP
+		}
+		if site.CheckType != db.CheckTypeHTTP {
+			addProblem(field+".check_type", "only %s is supported", db.CheckTypeHTTP)
+		}
+		if site.CheckIntervalSeconds == 0 {
+			site.CheckIntervalSeconds = db.DefaultCheckIntervalSeconds
+		}
+		if site.CheckIntervalSeconds < 30 || site.CheckIntervalSeconds > 86400 {
+			addProblem(field+".check_interval_seconds", "must be between 30 and 86400")
+		}
+		if site.TimeoutSeconds == 0 {
+			site.TimeoutSeconds = db.DefaultTimeoutSeconds
+		}
+		if site.TimeoutSeconds < 1 || site.TimeoutSeconds > 60 {
+			addProblem(field+".timeout_seconds", "must be between 1 and 60")
+		}
+		if site.Tags, err = db.NormalizeTags(site.Tags); err != nil {
+			addProblem(field+".tags", "%v", err)
+		}
+		sites = append(sites, site)
+	}
+	doc.Sites = sites
+
+	names := map[string]bool{}
+	for i, group := range doc.Groups {
+		field := fmt.Sprintf("groups[%d]", i)
+		if group.Name == "" || len(group.Name) > 100 {
+			addProblem(field+".name", "must be between 1 and 100 characters")
+		}
+		if names[group.Name] {
+			addProblem(field+".name", "duplicate group %s", group.Name)
+		}
+		names[group.Name] = true
+		for j, siteURL := range group.Sites {
+			if !urls[siteURL] {
+				addProblem(fmt.Sprintf("%s.sites[%d]", field, j), "%s is not a site in this document", siteURL)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return Document{}, &ValidationError{Problems: problems}
+	}
+	return doc, nil
+}
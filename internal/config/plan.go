@@ -0,0 +1,350 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// Jenis perubahan pada rencana
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Jenis objek yang diubah
+const (
+	KindSite  = "site"
+	KindGroup = "group"
+)
+
+// FieldChange adalah nilai lama dan baru dari satu field; Old kosong untuk objek baru dan New kosong untuk objek yang dihapus.
+type FieldChange struct {
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+// Change adalah satu perubahan yang diperlukan agar keadaan di database sama dengan dokumen.
+type Change struct {
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	// Key adalah URL untuk site dan nama untuk grup
+	Key    string                 `json:"key"`
+	Fields map[string]FieldChange `json:"fields,omitempty"`
+
+	id   int64
+	site Site
+	urls []string
+}
+
+type PlanSummary struct {
+	Create    int `json:"create"`
+	Update    int `json:"update"`
+	Delete    int `json:"delete"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Plan adalah daftar perubahan berurutan: site dibuat, diubah dan dihapus lebih dulu, baru kemudian grup.
+type Plan struct {
+	Changes []Change    `json:"changes"`
+	Summary PlanSummary `json:"summary"`
+}
+
+// Export membaca semua site aktif dan grup milik organisasi sebagai dokumen, diurutkan agar hasilnya stabil.
+func Export(ctx context.Context, store db.Store, orgID int64) (Document, error) {
+	sites, err := store.GetSitesByOrganizationID(ctx, orgID)
+	if err != nil {
+		return Document{}, err
+	}
+	groups, err := store.ListSiteGroups(ctx, orgID)
+	if err != nil {
+		return Document{}, err
+	}
+
+	doc := Document{Version: CurrentVersion, Sites: []Site{}}
+	urlsByID := map[int64]string{}
+	for _, site := range canonicalSites(sites) {
+		urlsByID[site.ID] = site.URL
+		doc.Sites = append(doc.Sites, siteFromDB(site))
+	}
+	for _, g := range canonicalGroups(groups) {
+		doc.Groups = append(doc.Groups, Group{Name: g.Name, Sites: groupURLs(g, urlsByID)})
+	}
+	return doc, nil
+}
+
+// BuildPlan menghitung perubahan yang diperlukan tanpa mengubah apa pun (dry-run).
+func BuildPlan(ctx context.Context, store db.Store, orgID int64, doc Document) (Plan, error) {
+	doc, err := doc.normalize()
+	if err != nil {
+		return Plan{}, err
+	}
+	return buildPlan(ctx, store, orgID, doc)
+}
+
+// Apply menerapkan dokumen dalam satu transaksi: jika satu perubahan gagal, tidak ada yang disimpan.
+// Rencana dihitung ulang di dalam transaksi sehingga yang diterapkan sama dengan yang dikembalikan.
+// Site dan grup baru dicatat sebagai dibuat oleh userID.
+func Apply(ctx context.Context, store db.Store, orgID, userID int64, doc Document) (Plan, error) {
+	doc, err := doc.normalize()
+	if err != nil {
+		return Plan{}, err
+	}
+
+	var plan Plan
+	err = store.ExecTx(ctx, func(tx db.Store) error {
+		plan, err = buildPlan(ctx, tx, orgID, doc)
+		if err != nil {
+			return err
+		}
+		return applyPlan(ctx, tx, orgID, userID, plan)
+	})
+	if err != nil {
+		return Plan{}, err
+	}
+	return plan, nil
+}
+
+func buildPlan(ctx context.Context, store db.Store, orgID int64, doc Document) (Plan, error) {
+	sites, err := store.GetSitesByOrganizationID(ctx, orgID)
+	if err != nil {
+		return Plan{}, err
+	}
+	groups, err := store.ListSiteGroups(ctx, orgID)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	plan := Plan{Changes: []Change{}}
+	add := func(c Change) {
+		plan.Changes = append(plan.Changes, c)
+		switch c.Action {
+		case ActionCreate:
+			plan.Summary.Create++
+		case ActionUpdate:
+			plan.Summary.Update++
+		case ActionDelete:
+			plan.Summary.Delete++
+		}
+	}
+
+	existingSites := map[string]db.Site{}
+	urlsByID := map[int64]string{}
+	for _, site := range canonicalSites(sites) {
+		existingSites[site.URL] = site
+		urlsByID[site.ID] = site.URL
+	}
+
+	wanted := map[string]bool{}
+	for _, site := range doc.Sites {
+		wanted[site.URL] = true
+		current, ok := existingSites[site.URL]
+		if !ok {
+			add(Change{Action: ActionCreate, Kind: KindSite, Key: site.URL, Fields: diffSites(Site{}, site, true), site: site})
+			continue
+		}
+		if fields := diffSites(siteFromDB(current), site, false); len(fields) > 0 {
+			add(Change{Action: ActionUpdate, Kind: KindSite, Key: site.URL, Fields: fields, id: current.ID, site: site})
+		} else {
+			plan.Summary.Unchanged++
+		}
+	}
+	// Site dengan URL ganda di database juga dihapus agar setiap URL hanya dimiliki satu site
+	for _, site := range sites {
+		if !wanted[site.URL] || existingSites[site.URL].ID != site.ID {
+			add(Change{Action: ActionDelete, Kind: KindSite, Key: site.URL, id: site.ID})
+		}
+	}
+
+	existingGroups := map[string]db.SiteGroup{}
+	for _, g := range canonicalGroups(groups) {
+		existingGroups[g.Name] = g
+	}
+	wantedGroups := map[string]bool{}
+	for _, group := range doc.Groups {
+		wantedGroups[group.Name] = true
+		urls := slices.Sorted(slices.Values(group.Sites))
+		urls = slices.Compact(urls)
+		current, ok := existingGroups[group.Name]
+		if !ok {
+			add(Change{Action: ActionCreate, Kind: KindGroup, Key: group.Name,
+				Fields: map[string]FieldChange{"sites": {New: urls}}, urls: urls})
+			continue
+		}
+		if currentURLs := groupURLs(current, urlsByID); !slices.Equal(currentURLs, urls) {
+			add(Change{Action: ActionUpdate, Kind: KindGroup, Key: group.Name,
+				Fields: map[string]FieldChange{"sites": {Old: currentURLs, New: urls}}, id: current.ID, urls: urls})
+		} else {
+			plan.Summary.Unchanged++
+		}
+	}
+	for _, g := range groups {
+		if !wantedGroups[g.Name] || existingGroups[g.Name].ID != g.ID {
+			add(Change{Action: ActionDelete, Kind: KindGroup, Key: g.Name, id: g.ID})
+		}
+	}
+
+	return plan, nil
+}
+
+func applyPlan(ctx context.Context, store db.Store, orgID, userID int64, plan Plan) error {
+	siteIDs := map[string]int64{}
+	sites, err := store.GetSitesByOrganizationID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, site := range canonicalSites(sites) {
+		siteIDs[site.URL] = site.ID
+	}
+
+	for _, c := range plan.Changes {
+		var err error
+		switch {
+		case c.Kind == KindSite && c.Action == ActionCreate:
+			var site db.Site
+			site, err = store.CreateSite(ctx, db.CreateSiteParams{
+				OrganizationID:       orgID,
+				UserID:               userID,
+				URL:                  c.site.URL,
+				Name:                 c.site.Name,
+				Description:          c.site.Description,
+				CheckType:            c.site.CheckType,
+				CheckIntervalSeconds: c.site.CheckIntervalSeconds,
+				TimeoutSeconds:       c.site.TimeoutSeconds,
+			})
+			if err == nil {
+				siteIDs[site.URL] = site.ID
+				err = applySiteState(ctx, store, orgID, site.ID, c)
+			}
+		case c.Kind == KindSite && c.Action == ActionUpdate:
+			_, err = store.UpdateSite(ctx, c.id, orgID, db.UpdateSiteParams{
+				Name:                 &c.site.Name,
+				Description:          &c.site.Description,
+				CheckIntervalSeconds: &c.site.CheckIntervalSeconds,
+				TimeoutSeconds:       &c.site.TimeoutSeconds,
+			})
+			if err == nil {
+				err = applySiteState(ctx, store, orgID, c.id, c)
+			}
+		case c.Kind == KindSite && c.Action == ActionDelete:
+			_, err = store.ArchiveSite(ctx, c.id, orgID)
+		case c.Kind == KindGroup && c.Action == ActionCreate:
+			_, err = store.CreateSiteGroup(ctx, db.CreateSiteGroupParams{OrganizationID: orgID, UserID: userID, Name: c.Key, SiteIDs: resolveURLs(c.urls, siteIDs)})
+		case c.Kind == KindGroup && c.Action == ActionUpdate:
+			ids := resolveURLs(c.urls, siteIDs)
+			_, err = store.UpdateSiteGroup(ctx, c.id, orgID, db.UpdateSiteGroupParams{SiteIDs: &ids})
+		case c.Kind == KindGroup && c.Action == ActionDelete:
+			err = store.DeleteSiteGroup(ctx, c.id, orgID)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %s %s: %w", c.Action, c.Kind, c.Key, err)
+		}
+	}
+	return nil
+}
+
+// applySiteState menyimpan tag dan status jeda site jika berubah.
+func applySiteState(ctx context.Context, store db.Store, orgID, siteID int64, c Change) error {
+	if _, ok := c.Fields["tags"]; ok {
+		if _, err := store.SetSiteTags(ctx, siteID, orgID, c.site.Tags); err != nil {
+			return err
+		}
+	}
+	if _, ok := c.Fields["paused"]; ok {
+		var err error
+		if c.site.Paused {
+			_, err = store.PauseSite(ctx, siteID, orgID)
+		} else {
+			_, err = store.ResumeSite(ctx, siteID, orgID)
+		}
+		return err
+	}
+	return nil
+}
+
+// diffSites membandingkan dua site; jika create bernilai true, semua field yang tidak kosong pada want dilaporkan.
+func diffSites(current, want Site, create bool) map[string]FieldChange {
+	fields := map[string]FieldChange{}
+	compare := func(name string, old, new any, equal bool) {
+		if !equal {
+			if create {
+				old = nil
+			}
+			fields[name] = FieldChange{Old: old, New: new}
+		}
+	}
+	compare("name", current.Name, want.Name, current.Name == want.Name)
+	compare("description", current.Description, want.Description, current.Description == want.Description)
+	compare("check_type", current.CheckType, want.CheckType, current.CheckType == want.CheckType)
+	compare("check_interval_seconds", current.CheckIntervalSeconds, want.CheckIntervalSeconds, current.CheckIntervalSeconds == want.CheckIntervalSeconds)
+	compare("timeout_seconds", current.TimeoutSeconds, want.TimeoutSeconds, current.TimeoutSeconds == want.TimeoutSeconds)
+	compare("paused", current.Paused, want.Paused, current.Paused == want.Paused)
+	compare("tags", current.Tags, want.Tags, slices.Equal(current.Tags, want.Tags))
+	return fields
+}
+
+func siteFromDB(site db.Site) Site {
+	tags := site.Tags
+	if len(tags) == 0 {
+		tags = nil
+	}
+	return Site{
+		URL:                  site.URL,
+		Name:                 site.Name,
+		Description:          site.Description,
+		CheckType:            site.CheckType,
+		CheckIntervalSeconds: site.CheckIntervalSeconds,
+		TimeoutSeconds:       site.TimeoutSeconds,
+		Paused:               site.PausedAt != nil,
+		Tags:                 tags,
+	}
+}
+
+// canonicalSites mengurutkan site berdasarkan URL lalu ID dan hanya menyisakan site dengan ID terkecil untuk setiap URL.
+func canonicalSites(sites []db.Site) []db.Site {
+	sorted := slices.Clone(sites)
+	slices.SortFunc(sorted, func(a, b db.Site) int {
+		if c := strings.Compare(a.URL, b.URL); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return slices.CompactFunc(sorted, func(a, b db.Site) bool { return a.URL == b.URL })
+}
+
+// canonicalGroups adalah padanan canonicalSites untuk grup, berdasarkan nama.
+func canonicalGroups(groups []db.SiteGroup) []db.SiteGroup {
+	sorted := slices.Clone(groups)
+	slices.SortFunc(sorted, func(a, b db.SiteGroup) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return slices.CompactFunc(sorted, func(a, b db.SiteGroup) bool { return a.Name == b.Name })
+}
+
+// groupURLs mengembalikan URL anggota grup yang terurut; anggota yang bukan site kanonik diabaikan.
+func groupURLs(g db.SiteGroup, urlsByID map[int64]string) []string {
+	urls := []string{}
+	for _, id := range g.SiteIDs {
+		if url, ok := urlsByID[id]; ok {
+			urls = append(urls, url)
+		}
+	}
+	slices.Sort(urls)
+	return slices.Compact(urls)
+}
+
+func resolveURLs(urls []string, siteIDs map[string]int64) []int64 {
+	ids := make([]int64, 0, len(urls))
+	for _, url := range urls {
+		ids = append(ids, siteIDs[url])
+	}
+	return ids
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// newTestOrganization membuat user dan organisasi di MemoryStore baru.
+func newTestOrganization(t *testing.T) (*db.MemoryStore, int64, int64) {
+	t.Helper()
+	ctx := context.Background()
+	store := db.NewMemoryStore()
+	user, err := store.CreateUser(ctx, db.CreateUserParams{Username: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := store.CreateOrganization(ctx, "alice", user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, org.ID, user.ID
+}
+
+func parseYAML(t *testing.T, document string) Document {
+	t.Helper()
+	doc, err := Parse([]byte(document), FormatYAML)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return doc
+}
+
+// apply menerapkan dokumen dan mengembalikan rencananya.
+func apply(t *testing.T, store db.Store, orgID, userID int64, document string) Plan {
+	t.Helper()
+	plan, err := Apply(context.Background(), store, orgID, userID, parseYAML(t, document))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	return plan
+}
+
+func requireSummary(t *testing.T, plan Plan, want PlanSummary) {
+	t.Helper()
+	if plan.Summary != want {
+		t.Fatalf("summary = %+v, want %+v (changes %+v)", plan.Summary, want, plan.Changes)
+	}
+}
+
+// sitesByURL mengembalikan site aktif organisasi berdasarkan URL.
+func sitesByURL(t *testing.T, store db.Store, orgID int64) map[string]db.Site {
+	t.Helper()
+	sites, err := store.GetSitesByOrganizationID(context.Background(), orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byURL := map[string]db.Site{}
+	for _, site := range sites {
+		byURL[site.URL] = site
+	}
+	return byURL
+}
+
+const initialDocument = `version: 1
+sites:
+  - url: https://a.example.com
+    name: A
+    tags: [prod]
+  - url: https://b.example.com
+    paused: true
+  - url: https://c.example.com
+groups:
+  - name: web
+    sites: [https://a.example.com, https://b.example.com]
+`
+
+func TestApplyCreatesUpdatesAndDeletes(t *testing.T) {
+	store, orgID, userID := newTestOrganization(t)
+
+	plan := apply(t, store, orgID, userID, initialDocument)
+	requireSummary(t, plan, PlanSummary{Create: 4})
+	sites := sitesByURL(t, store, orgID)
+	if len(sites) != 3 {
+		t.Fatalf("sites = %v, want 3", sites)
+	}
+	a, b := sites["https://a.example.com"], sites["https://b.example.com"]
+	if a.Name != "A" || !slices.Equal(a.Tags, []string{"prod"}) || a.UserID != userID {
+		t.Errorf("site a = %+v", a)
+	}
+	if b.PausedAt == nil || a.PausedAt != nil {
+		t.Errorf("paused: a %v, b %v, want only b paused", a.PausedAt, b.PausedAt)
+	}
+
+	plan = apply(t, store, orgID, userID, `version: 1
+sites:
+  - url: https://a.example.com
+    name: A renamed
+    tags: [eu, prod]
+  - url: https://b.example.com
+  - url: https://d.example.com
+groups:
+  - name: web
+    sites: [https://a.example.com, https://d.example.com]
+  - name: api
+    sites: [https://b.example.com]
+`)
+	// a dan b diubah, d dan grup api dibuat, c dihapus, grup web diubah
+	requireSummary(t, plan, PlanSummary{Create: 2, Update: 3, Delete: 1})
+	sites = sitesByURL(t, store, orgID)
+	if _, ok := sites["https://c.example.com"]; ok || len(sites) != 3 {
+		t.Fatalf("sites = %v, want c archived", sites)
+	}
+	a, b = sites["https://a.example.com"], sites["https://b.example.com"]
+	if a.Name != "A renamed" || !slices.Equal(a.Tags, []string{"eu", "prod"}) {
+		t.Errorf("site a = %+v", a)
+	}
+	if b.PausedAt != nil {
+		t.Errorf("site b is still paused")
+	}
+	archived, err := store.GetArchivedSitesByOrganizationID(context.Background(), orgID)
+	if err != nil || len(archived) != 1 || archived[0].URL != "https://c.example.com" {
+		t.Errorf("archived sites = %v (%v), want c", archived, err)
+	}
+
+	groups, err := store.ListSiteGroups(context.Background(), orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	members := map[string][]int64{}
+	for _, g := range groups {
+		members[g.Name] = slices.Sorted(slices.Values(g.SiteIDs))
+	}
+	d := sites["https://d.example.com"]
+	if want := slices.Sorted(slices.Values([]int64{a.ID, d.ID})); !slices.Equal(members["web"], want) {
+		t.Errorf("web members = %v, want %v", members["web"], want)
+	}
+	if !slices.Equal(members["api"], []int64{b.ID}) {
+		t.Errorf("api members = %v, want [%d]", members["api"], b.ID)
+	}
+
+	// Dokumen tanpa grup menghapus semua grup
+	plan = apply(t, store, orgID, userID, `version: 1
+sites:
+  - url: https://a.example.com
+    name: A renamed
+    tags: [eu, prod]
+  - url: https://b.example.com
+  - url: https://d.example.com
+`)
+	requireSummary(t, plan, PlanSummary{Delete: 2, Unchanged: 3})
+}
+
+func TestExportThenApplyIsEmptyPlan(t *testing.T) {
+	store, orgID, userID := newTestOrganization(t)
+	apply(t, store, orgID, userID, initialDocument)
+
+	doc, err := Export(context.Background(), store, orgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		data, err := Marshal(doc, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(data, format)
+		if err != nil {
+			t.Fatalf("Parse %s: %v", format, err)
+		}
+		plan, err := BuildPlan(context.Background(), store, orgID, parsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan.Changes) != 0 || plan.Summary.Unchanged != 4 {
+			t.Errorf("%s round trip plan = %+v, want no changes", format, plan)
+		}
+	}
+}
+
+func TestBuildPlanDoesNotChangeStore(t *testing.T) {
+	store, orgID, userID := newTestOrganization(t)
+	apply(t, store, orgID, userID, initialDocument)
+	before := sitesByURL(t, store, orgID)
+
+	plan, err := BuildPlan(context.Background(), store, orgID, parseYAML(t, "version: 1\nsites: []\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireSummary(t, plan, PlanSummary{Delete: 4})
+	if after := sitesByURL(t, store, orgID); len(after) != len(before) {
+		t.Errorf("BuildPlan changed the store: %d sites, want %d", len(after), len(before))
+	}
+}
+
+func TestDuplicateURLsAreRejected(t *testing.T) {
+	store, orgID, userID := newTestOrganization(t)
+
+	_, err := Apply(context.Background(), store, orgID, userID, parseYAML(t, `version: 1
+sites:
+  - url: https://a.example.com
+  - url: https://a.example.com
+`))
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 || validationErr.Problems[0].Field != "sites[1].url" {
+		t.Fatalf("err = %v, want a duplicate problem on sites[1].url", err)
+	}
+	if sites := sitesByURL(t, store, orgID); len(sites) != 0 {
+		t.Errorf("sites = %v, want none", sites)
+	}
+}
+
+var errTestGroup = errors.New("group failed")
+
+// failingGroupStore gagal saat membuat grup, juga di dalam transaksi.
+type failingGroupStore struct {
+	db.Store
+}
+
+func (s failingGroupStore) ExecTx(ctx context.Context, fn func(db.Store) error) error {
+	return s.Store.ExecTx(ctx, func(tx db.Store) error {
+		return fn(failingGroupStore{tx})
+	})
+}
+
+func (s failingGroupStore) CreateSiteGroup(ctx context.Context, arg db.CreateSiteGroupParams) (db.SiteGroup, error) {
+	return db.SiteGroup{}, errTestGroup
+}
+
+func TestApplyRollsBackWhenAChangeFails(t *testing.T) {
+	store, orgID, userID := newTestOrganization(t)
+	apply(t, store, orgID, userID, "version: 1\nsites:\n  - url: https://old.example.com\n")
+
+	// Grup dibuat setelah semua perubahan site, jadi kegagalannya harus membatalkan perubahan site juga
+	_, err := Apply(context.Background(), failingGroupStore{store}, orgID, userID, parseYAML(t, initialDocument))
+	if !errors.Is(err, errTestGroup) {
+		t.Fatalf("err = %v, want %v", err, errTestGroup)
+	}
+	sites := sitesByURL(t, store, orgID)
+	if len(sites) != 1 || sites["https://old.example.com"].ID == 0 {
+		t.Errorf("sites after failed apply = %v, want only the old site", sites)
+	}
+	if archived, _ := store.GetArchivedSitesByOrganizationID(context.Background(), orgID); len(archived) != 0 {
+		t.Errorf("archived sites = %v, want none", archived)
+	}
+}
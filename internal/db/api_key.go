@@ -0,0 +1,266 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+)
+
+// Scope yang dapat diberikan pada API key
+const (
+	ScopeSitesRead      = "sites:read"
+	ScopeSitesWrite     = "sites:write"
+	ScopeIncidentsWrite = "incidents:write"
+)
+
+// APIScopes adalah semua scope yang dikenal, berurutan.
+var APIScopes = []string{ScopeSitesRead, ScopeSitesWrite, ScopeIncidentsWrite}
+
+// APIKey adalah token akses jangka panjang untuk otomasi. Key utuh tidak disimpan, hanya hash SHA-256-nya.
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active bernilai true jika key belum dicabut dan belum kedaluwarsa.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope bernilai true jika key memiliki scope tersebut.
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+type CreateAPIKeyParams struct {
+	UserID    int64
+	Name      string
+	Prefix    string
+	KeyHash   string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, created_at, expires_at, last_used_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...any) error }) (APIKey, error) {
+	var k APIKey
+	err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.Scopes, &k.CreatedAt, &k.ExpiresAt, &k.LastUsedAt, &k.RevokedAt)
+	return k, err
+}
+
+func (s *SQLStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	query := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at)
+              VALUES ($1, $2, $3, $4, $5, $6) RETURNING ` + apiKeyColumns
+
+	return scanAPIKey(s.conn.QueryRow(ctx, query, arg.UserID, arg.Name, arg.Prefix, arg.KeyHash, arg.Scopes, arg.ExpiresAt))
+}
+
+func (s *SQLStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	return scanAPIKey(s.conn.QueryRow(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash))
+}
+
+// ListAPIKeys mengembalikan key milik user yang belum dicabut, termasuk yang sudah kedaluwarsa.
+func (s *SQLStore) ListAPIKeys(ctx context.Context, userID int64) ([]APIKey, error) {
+	rows, err := s.conn.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (s *SQLStore) RevokeAPIKey(ctx context.Context, keyID int64, userID int64) error {
+	tag, err := s.conn.Exec(ctx, `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, keyID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey mencatat waktu terakhir key dipakai.
+func (s *SQLStore) TouchAPIKey(ctx context.Context, keyID int64) error {
+	_, err := s.conn.Exec(ctx, `UPDATE api_keys SET last_used_at = now() WHERE id = $1`, keyID)
+	return err
+}
+
+// --- SQLite ---
+
+func scanSQLiteAPIKey(row sqliteScanner) (APIKey, error) {
+	var k APIKey
+	var scopes, createdAt string
+	var expiresAt, lastUsedAt, revokedAt sql.NullString
+	if err := row.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &scopes, &createdAt, &expiresAt, &lastUsedAt, &revokedAt); err != nil {
+		return APIKey{}, err
+	}
+	if err := json.Unmarshal([]byte(scopes), &k.Scopes); err != nil {
+		return APIKey{}, err
+	}
+	var err error
+	if k.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return APIKey{}, err
+	}
+	if k.ExpiresAt, err = parseNullSQLiteTime(expiresAt); err != nil {
+		return APIKey{}, err
+	}
+	if k.LastUsedAt, err = parseNullSQLiteTime(lastUsedAt); err != nil {
+		return APIKey{}, err
+	}
+	k.RevokedAt, err = parseNullSQLiteTime(revokedAt)
+	return k, err
+}
+
+func (s *SQLiteStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	scopes, err := json.Marshal(arg.Scopes)
+	if err != nil {
+		return APIKey{}, err
+	}
+	var expiresAt *string
+	if arg.ExpiresAt != nil {
+		t := sqliteTime(*arg.ExpiresAt)
+		expiresAt = &t
+	}
+	query := `INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, created_at, expires_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING ` + apiKeyColumns
+
+	k, err := scanSQLiteAPIKey(s.conn.QueryRowContext(ctx, query, arg.UserID, arg.Name, arg.Prefix, arg.KeyHash, string(scopes), sqliteTime(time.Now()), expiresAt))
+	return k, sqliteError(err)
+}
+
+func (s *SQLiteStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	k, err := scanSQLiteAPIKey(s.conn.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = ?`, keyHash))
+	return k, sqliteError(err)
+}
+
+func (s *SQLiteStore) ListAPIKeys(ctx context.Context, userID int64) ([]APIKey, error) {
+	rows, err := s.conn.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? AND revoked_at IS NULL ORDER BY created_at DESC, id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		k, err := scanSQLiteAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (s *SQLiteStore) RevokeAPIKey(ctx context.Context, keyID int64, userID int64) error {
+	res, err := s.conn.ExecContext(ctx, `UPDATE api_keys SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`, sqliteTime(time.Now()), keyID, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrRecordNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteStore) TouchAPIKey(ctx context.Context, keyID int64) error {
+	_, err := s.conn.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, sqliteTime(time.Now()), keyID)
+	return err
+}
+
+// --- Memory ---
+
+func (s *MemoryStore) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextAPIKeyID++
+	k := APIKey{
+		ID:        s.nextAPIKeyID,
+		UserID:    arg.UserID,
+		Name:      arg.Name,
+		Prefix:    arg.Prefix,
+		KeyHash:   arg.KeyHash,
+		Scopes:    slices.Clone(arg.Scopes),
+		CreatedAt: time.Now(),
+		ExpiresAt: arg.ExpiresAt,
+	}
+	setEntry(s, s.apiKeys, k.ID, k)
+	return k, nil
+}
+
+func (s *MemoryStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, k := range s.apiKeys {
+		if k.KeyHash == keyHash {
+			return k, nil
+		}
+	}
+	return APIKey{}, ErrRecordNotFound
+}
+
+func (s *MemoryStore) ListAPIKeys(ctx context.Context, userID int64) ([]APIKey, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := []APIKey{}
+	for _, k := range s.apiKeys {
+		if k.UserID == userID && k.RevokedAt == nil {
+			keys = append(keys, k)
+		}
+	}
+	slices.SortFunc(keys, func(a, b APIKey) int { return cmp.Compare(b.ID, a.ID) })
+	return keys, nil
+}
+
+func (s *MemoryStore) RevokeAPIKey(ctx context.Context, keyID int64, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k, ok := s.apiKeys[keyID]
+	if !ok || k.UserID != userID || k.RevokedAt != nil {
+		return ErrRecordNotFound
+	}
+	now := time.Now()
+	k.RevokedAt = &now
+	setEntry(s, s.apiKeys, keyID, k)
+	return nil
+}
+
+func (s *MemoryStore) TouchAPIKey(ctx context.Context, keyID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if k, ok := s.apiKeys[keyID]; ok {
+		now := time.Now()
+		k.LastUsedAt = &now
+		setEntry(s, s.apiKeys, keyID, k)
+	}
+	return nil
+}
@@ -0,0 +1,382 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Batas jumlah event audit per halaman
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 200
+)
+
+// AuditChange adalah nilai satu field sebelum dan sesudah aksi; nil berarti field belum/tidak lagi ada.
+type AuditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// AuditEvent mencatat satu aksi yang mengubah data. ID bernilai 0 berarti tidak ada (NULL).
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// OrganizationID terisi untuk aksi pada data organisasi (site, grup, anggota, dll.)
+	OrganizationID int64 `json:"organization_id,omitempty"`
+	// UserID terisi untuk event akun (registrasi, login, session, API key, 2FA, dll.)
+	UserID     int64                  `json:"user_id,omitempty"`
+	ActorID    int64                  `json:"actor_id,omitempty"`
+	APIKeyID   int64                  `json:"api_key_id,omitempty"`
+	Action     string                 `json:"action"`
+	TargetType string                 `json:"target_type"`
+	TargetID   int64                  `json:"target_id,omitempty"`
+	IPAddress  string                 `json:"ip_address"`
+	UserAgent  string                 `json:"user_agent"`
+	Changes    map[string]AuditChange `json:"changes"`
+	Details    map[string]any         `json:"details"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+type CreateAuditEventParams struct {
+	OrganizationID int64
+	UserID         int64
+	ActorID        int64
+	APIKeyID       int64
+	Action         string
+	TargetType     string
+	TargetID       int64
+	IPAddress      string
+	UserAgent      string
+	Changes        map[string]AuditChange
+	Details        map[string]any
+}
+
+// ListAuditEventsParams memilih event milik organisasi (OrganizationID) atau event akun milik user (UserID).
+// Filter bernilai kosong tidak diterapkan.
+type ListAuditEventsParams struct {
+	OrganizationID int64
+	UserID         int64
+	Action         string
+	ActorID        int64
+	TargetType     string
+	TargetID       int64
+	Since          *time.Time
+	Until          *time.Time
+	Limit          int
+	Cursor         string
+}
+
+type ListAuditEventsResult struct {
+	Events     []AuditEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// AuditDiff membandingkan representasi JSON before dan after, lalu mengembalikan field yang berbeda.
+// before nil berarti data baru dibuat, after nil berarti data dihapus.
+func AuditDiff(before, after any) map[string]AuditChange {
+	b, a := auditFields(before), auditFields(after)
+	changes := map[string]AuditChange{}
+	for key, value := range a {
+		if old, ok := b[key]; !ok || !reflect.DeepEqual(old, value) {
+			changes[key] = AuditChange{Before: old, After: value}
+		}
+	}
+	for key, old := range b {
+		if _, ok := a[key]; !ok {
+			changes[key] = AuditChange{Before: old}
+		}
+	}
+	return changes
+}
+
+func auditFields(v any) map[string]any {
+	fields := map[string]any{}
+	if v == nil {
+		return fields
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+func (arg ListAuditEventsParams) withDefaults() ListAuditEventsParams {
+	if arg.Limit <= 0 {
+		arg.Limit = DefaultAuditPageSize
+	}
+	if arg.Limit > MaxAuditPageSize {
+		arg.Limit = MaxAuditPageSize
+	}
+	return arg
+}
+
+// Cursor event audit adalah ID event terakhir di halaman sebelumnya; event diurutkan dari yang terbaru.
+func encodeAuditCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// decodeAuditCursor mengembalikan 0 jika cursor kosong (halaman pertama).
+func decodeAuditCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// auditPage memotong hasil query (yang mengambil limit+1 baris) menjadi satu halaman beserta cursor berikutnya.
+func auditPage(events []AuditEvent, limit int) ListAuditEventsResult {
+	result := ListAuditEventsResult{Events: events}
+	if len(events) > limit {
+		result.Events = events[:limit]
+		result.NextCursor = encodeAuditCursor(events[limit-1].ID)
+	}
+	return result
+}
+
+func (arg CreateAuditEventParams) jsonColumns() (changes, details []byte, err error) {
+	if arg.Changes == nil {
+		arg.Changes = map[string]AuditChange{}
+	}
+	if arg.Details == nil {
+		arg.Details = map[string]any{}
+	}
+	if changes, err = json.Marshal(arg.Changes); err != nil {
+		return nil, nil, err
+	}
+	details, err = json.Marshal(arg.Details)
+	return changes, details, err
+}
+
+// Kolom event audit yang dipilih oleh semua query; kolom NULL dibaca sebagai 0
+const auditEventColumns = `id, COALESCE(organization_id, 0), COALESCE(user_id, 0), COALESCE(actor_id, 0), COALESCE(api_key_id, 0),
+  action, target_type, COALESCE(target_id, 0), ip_address, user_agent, changes, details, created_at`
+
+func scanAuditEvent(row interface{ Scan(...any) error }) (AuditEvent, error) {
+	var e AuditEvent
+	var changes, details []byte
+	err := row.Scan(&e.ID, &e.OrganizationID, &e.UserID, &e.ActorID, &e.APIKeyID, &e.Action, &e.TargetType, &e.TargetID,
+		&e.IPAddress, &e.UserAgent, &changes, &details, &e.CreatedAt)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	if err := json.Unmarshal(changes, &e.Changes); err != nil {
+		return AuditEvent{}, err
+	}
+	err = json.Unmarshal(details, &e.Details)
+	return e, err
+}
+
+func (s *SQLStore) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	changes, details, err := arg.jsonColumns()
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	query := `INSERT INTO audit_events (organization_id, user_id, actor_id, api_key_id, action, target_type, target_id,
+                ip_address, user_agent, changes, details)
+              VALUES (NULLIF($1::bigint, 0), NULLIF($2::bigint, 0), NULLIF($3::bigint, 0), NULLIF($4::bigint, 0), $5, $6,
+                NULLIF($7::bigint, 0), $8, $9, $10, $11)
+              RETURNING ` + auditEventColumns
+
+	return scanAuditEvent(s.conn.QueryRow(ctx, query, arg.OrganizationID, arg.UserID, arg.ActorID, arg.APIKeyID, arg.Action,
+		arg.TargetType, arg.TargetID, arg.IPAddress, arg.UserAgent, string(changes), string(details)))
+}
+
+// ListAuditEvents mengembalikan satu halaman event audit sesuai filter, dari yang terbaru.
+func (s *SQLStore) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) (ListAuditEventsResult, error) {
+	arg = arg.withDefaults()
+	cursor, err := decodeAuditCursor(arg.Cursor)
+	if err != nil {
+		return ListAuditEventsResult{}, err
+	}
+
+	// Event akun hanya mencakup event di luar organisasi
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events
+              WHERE (CASE WHEN $1::bigint <> 0 THEN organization_id = $1 ELSE organization_id IS NULL AND user_id = $2 END)
+                AND ($3::varchar = '' OR action = $3)
+                AND ($4::bigint = 0 OR actor_id = $4)
+                AND ($5::varchar = '' OR target_type = $5)
+                AND ($6::bigint = 0 OR target_id = $6)
+                AND ($7::timestamptz IS NULL OR created_at >= $7)
+                AND ($8::timestamptz IS NULL OR created_at < $8)
+                AND ($9::bigint = 0 OR id < $9)
+              ORDER BY id DESC LIMIT $10`
+	rows, err := s.conn.Query(ctx, query, arg.OrganizationID, arg.UserID, arg.Action, arg.ActorID, arg.TargetType,
+		arg.TargetID, arg.Since, arg.Until, cursor, arg.Limit+1)
+	if err != nil {
+		return ListAuditEventsResult{}, err
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		e, err := scanAuditEvent(rows)
+		if err != nil {
+			return ListAuditEventsResult{}, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return ListAuditEventsResult{}, err
+	}
+	return auditPage(events, arg.Limit), nil
+}
+
+// --- SQLite ---
+
+func scanSQLiteAuditEvent(row sqliteScanner) (AuditEvent, error) {
+	var e AuditEvent
+	var changes, details, createdAt string
+	err := row.Scan(&e.ID, &e.OrganizationID, &e.UserID, &e.ActorID, &e.APIKeyID, &e.Action, &e.TargetType, &e.TargetID,
+		&e.IPAddress, &e.UserAgent, &changes, &details, &createdAt)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	if err := json.Unmarshal([]byte(changes), &e.Changes); err != nil {
+		return AuditEvent{}, err
+	}
+	if err := json.Unmarshal([]byte(details), &e.Details); err != nil {
+		return AuditEvent{}, err
+	}
+	e.CreatedAt, err = parseSQLiteTime(createdAt)
+	return e, err
+}
+
+func (s *SQLiteStore) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	changes, details, err := arg.jsonColumns()
+	if err != nil {
+		return AuditEvent{}, err
+	}
+	query := `INSERT INTO audit_events (organization_id, user_id, actor_id, api_key_id, action, target_type, target_id,
+                ip_address, user_agent, changes, details, created_at)
+              VALUES (NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0), NULLIF(?, 0), ?, ?, NULLIF(?, 0), ?, ?, ?, ?, ?)
+              RETURNING ` + auditEventColumns
+
+	e, err := scanSQLiteAuditEvent(s.conn.QueryRowContext(ctx, query, arg.OrganizationID, arg.UserID, arg.ActorID, arg.APIKeyID,
+		arg.Action, arg.TargetType, arg.TargetID, arg.IPAddress, arg.UserAgent, string(changes), string(details), sqliteTime(time.Now())))
+	return e, sqliteError(err)
+}
+
+func (s *SQLiteStore) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) (ListAuditEventsResult, error) {
+	arg = arg.withDefaults()
+	cursor, err := decodeAuditCursor(arg.Cursor)
+	if err != nil {
+		return ListAuditEventsResult{}, err
+	}
+	var since, until sql.NullString
+	if arg.Since != nil {
+		since = sql.NullString{String: sqliteTime(*arg.Since), Valid: true}
+	}
+	if arg.Until != nil {
+		until = sql.NullString{String: sqliteTime(*arg.Until), Valid: true}
+	}
+
+	query := `SELECT ` + auditEventColumns + ` FROM audit_events
+              WHERE (CASE WHEN ?1 <> 0 THEN organization_id = ?1 ELSE organization_id IS NULL AND user_id = ?2 END)
+                AND (?3 = '' OR action = ?3)
+                AND (?4 = 0 OR actor_id = ?4)
+                AND (?5 = '' OR target_type = ?5)
+                AND (?6 = 0 OR target_id = ?6)
+                AND (?7 IS NULL OR created_at >= ?7)
+                AND (?8 IS NULL OR created_at < ?8)
+                AND (?9 = 0 OR id < ?9)
+              ORDER BY id DESC LIMIT ?10`
+	rows, err := s.conn.QueryContext(ctx, query, arg.OrganizationID, arg.UserID, arg.Action, arg.ActorID, arg.TargetType,
+		arg.TargetID, since, until, cursor, arg.Limit+1)
+	if err != nil {
+		return ListAuditEventsResult{}, err
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		e, err := scanSQLiteAuditEvent(rows)
+		if err != nil {
+			return ListAuditEventsResult{}, err
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return ListAuditEventsResult{}, err
+	}
+	return auditPage(events, arg.Limit), nil
+}
+
+// --- Memory ---
+
+func (s *MemoryStore) CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error) {
+	changes, details, err := arg.jsonColumns()
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextAuditEventID++
+	e := AuditEvent{
+		ID:             s.nextAuditEventID,
+		OrganizationID: arg.OrganizationID,
+		UserID:         arg.UserID,
+		ActorID:        arg.ActorID,
+		APIKeyID:       arg.APIKeyID,
+		Action:         arg.Action,
+		TargetType:     arg.TargetType,
+		TargetID:       arg.TargetID,
+		IPAddress:      arg.IPAddress,
+		UserAgent:      arg.UserAgent,
+		CreatedAt:      time.Now(),
+	}
+	// Disimpan lewat JSON seperti di database agar event tidak ikut berubah jika map milik pemanggil diubah
+	json.Unmarshal(changes, &e.Changes)
+	json.Unmarshal(details, &e.Details)
+	appendEntry(s, &s.auditEvents, e)
+	return e, nil
+}
+
+func (s *MemoryStore) ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) (ListAuditEventsResult, error) {
+	arg = arg.withDefaults()
+	cursor, err := decodeAuditCursor(arg.Cursor)
+	if err != nil {
+		return ListAuditEventsResult{}, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []AuditEvent{}
+	// Event disimpan berurutan menurut ID, jadi dibaca dari belakang untuk mendapatkan yang terbaru lebih dulu
+	for _, e := range slices.Backward(s.auditEvents) {
+		if len(events) > arg.Limit {
+			break
+		}
+		switch {
+		case arg.OrganizationID != 0 && e.OrganizationID != arg.OrganizationID,
+			arg.OrganizationID == 0 && (e.OrganizationID != 0 || e.UserID != arg.UserID),
+			arg.Action != "" && e.Action != arg.Action,
+			arg.ActorID != 0 && e.ActorID != arg.ActorID,
+			arg.TargetType != "" && e.TargetType != arg.TargetType,
+			arg.TargetID != 0 && e.TargetID != arg.TargetID,
+			arg.Since != nil && e.CreatedAt.Before(*arg.Since),
+			arg.Until != nil && !e.CreatedAt.Before(*arg.Until),
+			cursor != 0 && e.ID >= cursor:
+			continue
+		}
+		events = append(events, e)
+	}
+	return auditPage(events, arg.Limit), nil
+}
@@ -2,18 +2,24 @@ package db
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type Store struct {
-	conn *pgxpool.Pool
+// SQLStore adalah implementasi Store yang menggunakan PostgreSQL.
+type SQLStore struct {
+	// conn adalah pool, atau transaksi jika store dibuat oleh ExecTx
+	conn DBTX
+	pool *pgxpool.Pool
 }
 
-func NewStore(conn *pgxpool.Pool) *Store {
-	return &Store{conn: conn}
+func NewStore(conn *pgxpool.Pool) Store {
+	return &SQLStore{conn: pgConn{conn}, pool: conn}
 }
 
 // --- User ---
@@ -24,6 +30,14 @@ type User struct {
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"-"` // Jangan kirim hash password ke client
 	CreatedAt    time.Time `json:"created_at"`
+	// EmailVerifiedAt kosong selama user belum mengonfirmasi email-nya
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+}
+
+// Verified bernilai true jika email user sudah dikonfirmasi. Akun yang belum terverifikasi dibatasi:
+// jumlah site-nya dibatasi dan email alert tidak boleh dikirim ke alamatnya.
+func (u User) Verified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 type CreateUserParams struct {
@@ -32,54 +46,85 @@ type CreateUserParams struct {
 	PasswordHash string `json:"password_hash"`
 }
 
-func (s *Store) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	query := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3) RETURNING id, username, email, password_hash, created_at`
-	
-	row := s.conn.QueryRow(ctx, query, arg.Username, arg.Email, arg.PasswordHash)
-	
+const userColumns = `id, username, email, password_hash, created_at, email_verified_at`
+
+func scanUser(row interface{ Scan(...any) error }) (User, error) {
 	var u User
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.CreatedAt)
+	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.CreatedAt, &u.EmailVerifiedAt)
 	return u, err
 }
 
-func (s *Store) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	query := `SELECT id, username, email, password_hash, created_at FROM users WHERE email = $1 LIMIT 1`
-	
-	row := s.conn.QueryRow(ctx, query, email)
+func (s *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	query := `INSERT INTO users (username, email, password_hash) VALUES ($1, $2, $3) RETURNING ` + userColumns
 
-	var u User
-	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &u.CreatedAt)
-	return u, err
+	return scanUser(s.conn.QueryRow(ctx, query, arg.Username, arg.Email, arg.PasswordHash))
+}
+
+func (s *SQLStore) GetUser(ctx context.Context, userID int64) (User, error) {
+	return scanUser(s.conn.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, userID))
+}
+
+func (s *SQLStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1 LIMIT 1`
+
+	return scanUser(s.conn.QueryRow(ctx, query, email))
+}
+
+// VerifyUserEmail menandai email user sebagai terverifikasi selama email-nya masih sama dengan yang
+// ada di link verifikasi. Memverifikasi ulang email yang sudah terverifikasi tidak mengubah apa pun.
+func (s *SQLStore) VerifyUserEmail(ctx context.Context, userID int64, email string) (User, error) {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, now())
+              WHERE id = $1 AND email = $2 RETURNING ` + userColumns
+
+	return scanUser(s.conn.QueryRow(ctx, query, userID, email))
 }
 
 // --- Site ---
 
 type Site struct {
-	ID        int64     `json:"id"`
-	UserID    int64     `json:"user_id"`
-	URL       string    `json:"url"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             int64 `json:"id"`
+	OrganizationID int64 `json:"organization_id"`
+	// UserID adalah user yang membuat site
+	UserID               int64     `json:"user_id"`
+	URL                  string    `json:"url"`
+	Name                 string    `json:"name"`
+	Description          string    `json:"description"`
+	CheckType            string    `json:"check_type"`
+	CheckIntervalSeconds int       `json:"check_interval_seconds"`
+	TimeoutSeconds       int       `json:"timeout_seconds"`
+	CreatedAt            time.Time `json:"created_at"`
+	// PausedAt terisi jika site sedang dijeda; site yang dijeda tidak diperiksa
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+	// ArchivedAt terisi jika site sudah dihapus (diarsipkan); site yang diarsipkan tidak lagi diperiksa
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	// Tags diurutkan secara alfabetis
+	Tags []string `json:"tags"`
 }
 
-type CreateSiteParams struct {
-	UserID int64  `json:"user_id"`
-	URL    string `json:"url"`
-}
+// Jenis pengecekan yang didukung worker
+const CheckTypeHTTP = "HTTP"
 
-func (s *Store) CreateSite(ctx context.Context, arg CreateSiteParams) (Site, error) {
-	query := `INSERT INTO sites (user_id, url) VALUES ($1, $2) RETURNING id, user_id, url, created_at`
-	
-	row := s.conn.QueryRow(ctx, query, arg.UserID, arg.URL)
+// Nilai default pengaturan pengecekan untuk site baru
+const (
+	DefaultCheckIntervalSeconds = 60
+	DefaultTimeoutSeconds       = 10
+)
+
+// Kolom site yang dipilih oleh semua query, urutannya sesuai dengan scanSite
+const siteColumns = `id, organization_id, user_id, url, name, description, check_type, check_interval_seconds, timeout_seconds, created_at, paused_at, archived_at,
+    COALESCE((SELECT array_agg(t.tag ORDER BY t.tag) FROM site_tags t WHERE t.site_id = sites.id), '{}')`
 
+// scanSite membaca kolom siteColumns, diikuti kolom tambahan (jika ada) ke dalam extra.
+func scanSite(row pgx.Row, extra ...any) (Site, error) {
 	var site Site
-	err := row.Scan(&site.ID, &site.UserID, &site.URL, &site.CreatedAt)
+	dest := []any{&site.ID, &site.OrganizationID, &site.UserID, &site.URL, &site.Name, &site.Description, &site.CheckType,
+		&site.CheckIntervalSeconds, &site.TimeoutSeconds, &site.CreatedAt, &site.PausedAt, &site.ArchivedAt, &site.Tags}
+	err := row.Scan(append(dest, extra...)...)
 	return site, err
 }
 
-func (s *Store) GetSitesByUserID(ctx context.Context, userID int64) ([]Site, error) {
-	query := `SELECT id, user_id, url, created_at FROM sites WHERE user_id = $1 ORDER BY created_at DESC`
-	
-	rows, err := s.conn.Query(ctx, query, userID)
+func (s *SQLStore) querySites(ctx context.Context, query string, args ...any) ([]Site, error) {
+	rows, err := s.conn.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -87,26 +132,194 @@ func (s *Store) GetSitesByUserID(ctx context.Context, userID int64) ([]Site, err
 
 	sites := []Site{}
 	for rows.Next() {
-		var site Site
-		if err := rows.Scan(&site.ID, &site.UserID, &site.URL, &site.CreatedAt); err != nil {
+		site, err := scanSite(rows)
+		if err != nil {
 			return nil, err
 		}
 		sites = append(sites, site)
 	}
-	return sites, nil
+	return sites, rows.Err()
+}
+
+type CreateSiteParams struct {
+	OrganizationID       int64  `json:"organization_id"`
+	UserID               int64  `json:"user_id"`
+	URL                  string `json:"url"`
+	Name                 string `json:"name"`
+	Description          string `json:"description"`
+	CheckType            string `json:"check_type"`
+	CheckIntervalSeconds int    `json:"check_interval_seconds"`
+	TimeoutSeconds       int    `json:"timeout_seconds"`
+}
+
+// withDefaults mengisi pengaturan pengecekan yang kosong dengan nilai default.
+func (arg CreateSiteParams) withDefaults() CreateSiteParams {
+	if arg.CheckType == "" {
+		arg.CheckType = CheckTypeHTTP
+	}
+	if arg.CheckIntervalSeconds == 0 {
+		arg.CheckIntervalSeconds = DefaultCheckIntervalSeconds
+	}
+	if arg.TimeoutSeconds == 0 {
+		arg.TimeoutSeconds = DefaultTimeoutSeconds
+	}
+	return arg
+}
+
+func (s *SQLStore) CreateSite(ctx context.Context, arg CreateSiteParams) (Site, error) {
+	arg = arg.withDefaults()
+	query := `INSERT INTO sites (organization_id, user_id, url, name, description, check_type, check_interval_seconds, timeout_seconds)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING ` + siteColumns
+
+	return scanSite(s.conn.QueryRow(ctx, query, arg.OrganizationID, arg.UserID, arg.URL, arg.Name, arg.Description, arg.CheckType, arg.CheckIntervalSeconds, arg.TimeoutSeconds))
+}
+
+// UpdateSiteParams berisi field yang ingin diubah; field bernilai nil tidak diubah.
+type UpdateSiteParams struct {
+	URL                  *string `json:"url"`
+	Name                 *string `json:"name"`
+	Description          *string `json:"description"`
+	CheckIntervalSeconds *int    `json:"check_interval_seconds"`
+	TimeoutSeconds       *int    `json:"timeout_seconds"`
+}
+
+func (s *SQLStore) UpdateSite(ctx context.Context, siteID int64, orgID int64, arg UpdateSiteParams) (Site, error) {
+	query := `UPDATE sites SET
+                url = COALESCE($3, url),
+                name = COALESCE($4, name),
+                description = COALESCE($5, description),
+                check_interval_seconds = COALESCE($6, check_interval_seconds),
+                timeout_seconds = COALESCE($7, timeout_seconds)
+              WHERE id = $1 AND organization_id = $2 AND archived_at IS NULL RETURNING ` + siteColumns
+
+	return scanSite(s.conn.QueryRow(ctx, query, siteID, orgID,
+		arg.URL, arg.Name, arg.Description, arg.CheckIntervalSeconds, arg.TimeoutSeconds))
+}
+
+// PauseSite menjeda pengecekan site. Mengembalikan ErrRecordNotFound jika site tidak ada atau sudah dijeda.
+func (s *SQLStore) PauseSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET paused_at = now() WHERE id = $1 AND organization_id = $2 AND archived_at IS NULL AND paused_at IS NULL RETURNING ` + siteColumns
+
+	return scanSite(s.conn.QueryRow(ctx, query, siteID, orgID))
+}
+
+// ResumeSite melanjutkan pengecekan site yang dijeda.
+func (s *SQLStore) ResumeSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET paused_at = NULL WHERE id = $1 AND organization_id = $2 AND archived_at IS NULL AND paused_at IS NOT NULL RETURNING ` + siteColumns
+
+	return scanSite(s.conn.QueryRow(ctx, query, siteID, orgID))
+}
+
+// GetSitesByOrganizationID mengembalikan site aktif (belum diarsipkan) milik organisasi.
+func (s *SQLStore) GetSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error) {
+	query := `SELECT ` + siteColumns + ` FROM sites WHERE organization_id = $1 AND archived_at IS NULL ORDER BY created_at DESC`
+	return s.querySites(ctx, query, orgID)
+}
+
+// CountActiveSitesByUserID menghitung site aktif yang dibuat user di semua organisasi.
+func (s *SQLStore) CountActiveSitesByUserID(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := s.conn.QueryRow(ctx, `SELECT count(*) FROM sites WHERE user_id = $1 AND archived_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// Batas jumlah dan panjang tag per site
+const (
+	MaxSiteTags  = 20
+	MaxTagLength = 50
+)
+
+// NormalizeTag merapikan tag agar "EU-Region " dan "eu-region" dianggap sama.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// NormalizeTags merapikan, menghapus duplikat dan mengurutkan daftar tag.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if len(tag) > MaxTagLength {
+			return nil, validationError("tags", fmt.Sprintf("tag %q is longer than %d characters", tag, MaxTagLength))
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	normalized = slices.Compact(normalized)
+	if len(normalized) > MaxSiteTags {
+		return nil, validationError("tags", fmt.Sprintf("a site can have at most %d tags", MaxSiteTags))
+	}
+	return normalized, nil
 }
 
-func (s *Store) DeleteSite(ctx context.Context, siteID int64, userID int64) error {
-	query := `DELETE FROM sites WHERE id = $1 AND user_id = $2`
-	
-	cmdTag, err := s.conn.Exec(ctx, query, siteID, userID)
+// SetSiteTags mengganti seluruh tag milik site.
+func (s *SQLStore) SetSiteTags(ctx context.Context, siteID int64, orgID int64, tags []string) (Site, error) {
+	tx, err := s.conn.Begin(ctx)
 	if err != nil {
-		return err
+		return Site{}, err
 	}
-	if cmdTag.RowsAffected() == 0 {
-		return errors.New("site not found or user not authorized to delete")
+	defer tx.Rollback(ctx)
+
+	// Kunci baris site agar penggantian tag tidak balapan dengan permintaan lain
+	var id int64
+	err = tx.QueryRow(ctx, `SELECT id FROM sites WHERE id = $1 AND organization_id = $2 AND archived_at IS NULL FOR UPDATE`, siteID, orgID).Scan(&id)
+	if err != nil {
+		return Site{}, err
 	}
-	return nil
+	if _, err := tx.Exec(ctx, `DELETE FROM site_tags WHERE site_id = $1`, siteID); err != nil {
+		return Site{}, err
+	}
+	if _, err := tx.Exec(ctx, `INSERT INTO site_tags (site_id, tag) SELECT $1, unnest($2::varchar[])`, siteID, tags); err != nil {
+		return Site{}, err
+	}
+
+	site, err := scanSite(tx.QueryRow(ctx, `SELECT `+siteColumns+` FROM sites WHERE id = $1`, siteID))
+	if err != nil {
+		return Site{}, err
+	}
+	return site, tx.Commit(ctx)
+}
+
+// GetArchivedSitesByOrganizationID mengembalikan site milik organisasi yang sudah diarsipkan.
+func (s *SQLStore) GetArchivedSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error) {
+	query := `SELECT ` + siteColumns + ` FROM sites WHERE organization_id = $1 AND archived_at IS NOT NULL ORDER BY archived_at DESC`
+	return s.querySites(ctx, query, orgID)
+}
+
+// ArchiveSite menandai site sebagai diarsipkan. Riwayat health check tetap disimpan.
+func (s *SQLStore) ArchiveSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET archived_at = now() WHERE id = $1 AND organization_id = $2 AND archived_at IS NULL RETURNING ` + siteColumns
+
+	return scanSite(s.conn.QueryRow(ctx, query, siteID, orgID))
+}
+
+// RestoreSite mengaktifkan kembali site yang sudah diarsipkan.
+func (s *SQLStore) RestoreSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET archived_at = NULL WHERE id = $1 AND organization_id = $2 AND archived_at IS NOT NULL RETURNING ` + siteColumns
+
+	return scanSite(s.conn.QueryRow(ctx, query, siteID, orgID))
+}
+
+// PurgeArchivedSites menghapus permanen site yang diarsipkan sebelum waktu yang diberikan beserta riwayat health check-nya.
+func (s *SQLStore) PurgeArchivedSites(ctx context.Context, archivedBefore time.Time) (int64, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM health_checks WHERE site_id IN (SELECT id FROM sites WHERE archived_at < $1)`, archivedBefore)
+	if err != nil {
+		return 0, err
+	}
+	cmdTag, err := tx.Exec(ctx, `DELETE FROM sites WHERE archived_at < $1`, archivedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return cmdTag.RowsAffected(), tx.Commit(ctx)
 }
 
 // --- HealthCheck ---
@@ -120,23 +333,10 @@ type HealthCheck struct {
 	CheckedAt      time.Time `json:"checked_at"`
 }
 
-func (s *Store) GetAllSites(ctx context.Context) ([]Site, error) {
-	query := `SELECT id, user_id, url, created_at FROM sites`
-	rows, err := s.conn.Query(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	sites := []Site{}
-	for rows.Next() {
-		var site Site
-		if err := rows.Scan(&site.ID, &site.UserID, &site.URL, &site.CreatedAt); err != nil {
-			return nil, err
-		}
-		sites = append(sites, site)
-	}
-	return sites, nil
+// GetAllSites mengembalikan semua site aktif yang perlu diperiksa oleh worker.
+func (s *SQLStore) GetAllSites(ctx context.Context) ([]Site, error) {
+	query := `SELECT ` + siteColumns + ` FROM sites WHERE archived_at IS NULL`
+	return s.querySites(ctx, query)
 }
 
 type CreateHealthCheckParams struct {
@@ -146,7 +346,7 @@ type CreateHealthCheckParams struct {
 	IsUp           bool  `json:"is_up"`
 }
 
-func (s *Store) CreateHealthCheck(ctx context.Context, arg CreateHealthCheckParams) (HealthCheck, error) {
+func (s *SQLStore) CreateHealthCheck(ctx context.Context, arg CreateHealthCheckParams) (HealthCheck, error) {
 	query := `INSERT INTO health_checks (site_id, status_code, response_time_ms, is_up) VALUES ($1, $2, $3, $4) 
               RETURNING id, site_id, status_code, response_time_ms, is_up, checked_at`
 
@@ -155,4 +355,270 @@ func (s *Store) CreateHealthCheck(ctx context.Context, arg CreateHealthCheckPara
 	var hc HealthCheck
 	err := row.Scan(&hc.ID, &hc.SiteID, &hc.StatusCode, &hc.ResponseTimeMs, &hc.IsUp, &hc.CheckedAt)
 	return hc, err
-}
\ No newline at end of file
+}
+
+// CreateHealthChecks menyimpan banyak hasil health check sekaligus menggunakan protokol COPY.
+// Nilai CheckedAt diambil dari masing-masing hasil sehingga tetap sesuai waktu pengecekan.
+func (s *SQLStore) CreateHealthChecks(ctx context.Context, checks []HealthCheck) (int64, error) {
+	columns := []string{"site_id", "status_code", "response_time_ms", "is_up", "checked_at"}
+
+	return s.conn.CopyFrom(ctx, pgx.Identifier{"health_checks"}, columns,
+		pgx.CopyFromSlice(len(checks), func(i int) ([]any, error) {
+			hc := checks[i]
+			return []any{hc.SiteID, hc.StatusCode, hc.ResponseTimeMs, hc.IsUp, hc.CheckedAt}, nil
+		}),
+	)
+}
+
+func (s *SQLStore) GetSite(ctx context.Context, siteID int64) (Site, error) {
+	query := `SELECT ` + siteColumns + ` FROM sites WHERE id = $1 LIMIT 1`
+
+	return scanSite(s.conn.QueryRow(ctx, query, siteID))
+}
+
+// Uptime adalah ringkasan hasil pengecekan sebuah site dalam rentang waktu tertentu.
+// Perhitungannya berbasis jumlah pengecekan, sehingga periode saat site dijeda
+// (tidak ada pengecekan) tidak dihitung sebagai downtime.
+type Uptime struct {
+	SiteID        int64   `json:"site_id"`
+	TotalChecks   int64   `json:"total_checks"`
+	UpChecks      int64   `json:"up_checks"`
+	UptimePercent float64 `json:"uptime_percent"`
+}
+
+func newUptime(siteID, total, up int64) Uptime {
+	u := Uptime{SiteID: siteID, TotalChecks: total, UpChecks: up}
+	if total > 0 {
+		u.UptimePercent = float64(up) / float64(total) * 100
+	}
+	return u
+}
+
+func (s *SQLStore) GetSiteUptime(ctx context.Context, siteID int64, from, to time.Time) (Uptime, error) {
+	query := `SELECT count(*), count(*) FILTER (WHERE is_up) FROM health_checks
+              WHERE site_id = $1 AND checked_at >= $2 AND checked_at < $3`
+
+	var total, up int64
+	if err := s.conn.QueryRow(ctx, query, siteID, from, to).Scan(&total, &up); err != nil {
+		return Uptime{}, err
+	}
+	return newUptime(siteID, total, up), nil
+}
+
+// GetSitesUptime menghitung uptime beberapa site sekaligus dalam satu query. Site tanpa health check dalam
+// rentang tersebut tidak ada di map hasil.
+func (s *SQLStore) GetSitesUptime(ctx context.Context, siteIDs []int64, from, to time.Time) (map[int64]Uptime, error) {
+	query := `SELECT site_id, count(*), count(*) FILTER (WHERE is_up) FROM health_checks
+              WHERE site_id = ANY($1::bigint[]) AND checked_at >= $2 AND checked_at < $3
+              GROUP BY site_id`
+
+	rows, err := s.conn.Query(ctx, query, siteIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uptimes := make(map[int64]Uptime, len(siteIDs))
+	for rows.Next() {
+		var siteID, total, up int64
+		if err := rows.Scan(&siteID, &total, &up); err != nil {
+			return nil, err
+		}
+		uptimes[siteID] = newUptime(siteID, total, up)
+	}
+	return uptimes, rows.Err()
+}
+
+// GetLatestHealthChecks mengembalikan hasil pengecekan terakhir untuk setiap site yang diminta.
+// Site yang belum pernah diperiksa tidak ada di map hasil.
+func (s *SQLStore) GetLatestHealthChecks(ctx context.Context, siteIDs []int64) (map[int64]HealthCheck, error) {
+	query := `SELECT hc.id, hc.site_id, COALESCE(hc.status_code, 0), COALESCE(hc.response_time_ms, 0), hc.is_up, hc.checked_at
+              FROM unnest($1::bigint[]) AS s(id)
+              CROSS JOIN LATERAL (
+                SELECT * FROM health_checks WHERE site_id = s.id ORDER BY checked_at DESC LIMIT 1
+              ) hc`
+
+	rows, err := s.conn.Query(ctx, query, siteIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latest := make(map[int64]HealthCheck, len(siteIDs))
+	for rows.Next() {
+		var hc HealthCheck
+		if err := rows.Scan(&hc.ID, &hc.SiteID, &hc.StatusCode, &hc.ResponseTimeMs, &hc.IsUp, &hc.CheckedAt); err != nil {
+			return nil, err
+		}
+		latest[hc.SiteID] = hc
+	}
+	return latest, rows.Err()
+}
+
+type StreamHealthChecksParams struct {
+	OrganizationID int64
+	// SiteID 0 berarti semua site milik organisasi
+	SiteID int64
+	From   time.Time
+	To     time.Time
+}
+
+// StreamHealthChecks membaca riwayat health check baris demi baris dan memanggil fn untuk setiap baris,
+// sehingga hasil yang besar tidak perlu dimuat seluruhnya ke memori.
+func (s *SQLStore) StreamHealthChecks(ctx context.Context, arg StreamHealthChecksParams, fn func(HealthCheck) error) error {
+	query := `SELECT hc.id, hc.site_id, COALESCE(hc.status_code, 0), COALESCE(hc.response_time_ms, 0), hc.is_up, hc.checked_at
+              FROM health_checks hc JOIN sites s ON s.id = hc.site_id
+              WHERE s.organization_id = $1 AND ($2::bigint = 0 OR hc.site_id = $2) AND hc.checked_at >= $3 AND hc.checked_at < $4
+              ORDER BY hc.checked_at`
+
+	rows, err := s.conn.Query(ctx, query, arg.OrganizationID, arg.SiteID, arg.From, arg.To)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hc HealthCheck
+		if err := rows.Scan(&hc.ID, &hc.SiteID, &hc.StatusCode, &hc.ResponseTimeMs, &hc.IsUp, &hc.CheckedAt); err != nil {
+			return err
+		}
+		if err := fn(hc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// --- SiteGroup ---
+
+// SiteGroup adalah kumpulan site bernama milik organisasi, misalnya "checkout" atau "EU region".
+type SiteGroup struct {
+	ID             int64 `json:"id"`
+	OrganizationID int64 `json:"organization_id"`
+	// UserID adalah user yang membuat grup
+	UserID    int64     `json:"user_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// SiteIDs hanya berisi site yang belum diarsipkan, diurutkan menaik
+	SiteIDs []int64 `json:"site_ids"`
+}
+
+type CreateSiteGroupParams struct {
+	OrganizationID int64   `json:"organization_id"`
+	UserID         int64   `json:"user_id"`
+	Name           string  `json:"name"`
+	SiteIDs        []int64 `json:"site_ids"`
+}
+
+// UpdateSiteGroupParams berisi field yang ingin diubah; field bernilai nil tidak diubah.
+type UpdateSiteGroupParams struct {
+	Name    *string  `json:"name"`
+	SiteIDs *[]int64 `json:"site_ids"`
+}
+
+// Kolom grup yang dipilih oleh semua query, urutannya sesuai dengan scanSiteGroup
+const siteGroupColumns = `id, organization_id, user_id, name, created_at,
+    COALESCE((SELECT array_agg(m.site_id ORDER BY m.site_id) FROM site_group_members m JOIN sites ON sites.id = m.site_id
+              WHERE m.group_id = site_groups.id AND sites.archived_at IS NULL), '{}')`
+
+func scanSiteGroup(row pgx.Row) (SiteGroup, error) {
+	var g SiteGroup
+	err := row.Scan(&g.ID, &g.OrganizationID, &g.UserID, &g.Name, &g.CreatedAt, &g.SiteIDs)
+	return g, err
+}
+
+// setSiteGroupMembers mengganti anggota grup. Site milik organisasi lain diabaikan.
+func setSiteGroupMembers(ctx context.Context, tx pgx.Tx, groupID int64, orgID int64, siteIDs []int64) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM site_group_members WHERE group_id = $1`, groupID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `INSERT INTO site_group_members (group_id, site_id)
+                            SELECT $1, id FROM sites WHERE id = ANY($2::bigint[]) AND organization_id = $3`, groupID, siteIDs, orgID)
+	return err
+}
+
+func (s *SQLStore) CreateSiteGroup(ctx context.Context, arg CreateSiteGroupParams) (SiteGroup, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var groupID int64
+	err = tx.QueryRow(ctx, `INSERT INTO site_groups (organization_id, user_id, name) VALUES ($1, $2, $3) RETURNING id`,
+		arg.OrganizationID, arg.UserID, arg.Name).Scan(&groupID)
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	if err := setSiteGroupMembers(ctx, tx, groupID, arg.OrganizationID, arg.SiteIDs); err != nil {
+		return SiteGroup{}, err
+	}
+
+	g, err := scanSiteGroup(tx.QueryRow(ctx, `SELECT `+siteGroupColumns+` FROM site_groups WHERE id = $1`, groupID))
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	return g, tx.Commit(ctx)
+}
+
+func (s *SQLStore) GetSiteGroup(ctx context.Context, groupID int64, orgID int64) (SiteGroup, error) {
+	query := `SELECT ` + siteGroupColumns + ` FROM site_groups WHERE id = $1 AND organization_id = $2`
+	return scanSiteGroup(s.conn.QueryRow(ctx, query, groupID, orgID))
+}
+
+func (s *SQLStore) ListSiteGroups(ctx context.Context, orgID int64) ([]SiteGroup, error) {
+	query := `SELECT ` + siteGroupColumns + ` FROM site_groups WHERE organization_id = $1 ORDER BY name, id`
+
+	rows, err := s.conn.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []SiteGroup{}
+	for rows.Next() {
+		g, err := scanSiteGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+func (s *SQLStore) UpdateSiteGroup(ctx context.Context, groupID int64, orgID int64, arg UpdateSiteGroupParams) (SiteGroup, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `UPDATE site_groups SET name = COALESCE($3, name) WHERE id = $1 AND organization_id = $2 RETURNING id`,
+		groupID, orgID, arg.Name).Scan(&id)
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	if arg.SiteIDs != nil {
+		if err := setSiteGroupMembers(ctx, tx, groupID, orgID, *arg.SiteIDs); err != nil {
+			return SiteGroup{}, err
+		}
+	}
+
+	g, err := scanSiteGroup(tx.QueryRow(ctx, `SELECT `+siteGroupColumns+` FROM site_groups WHERE id = $1`, groupID))
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	return g, tx.Commit(ctx)
+}
+
+func (s *SQLStore) DeleteSiteGroup(ctx context.Context, groupID int64, orgID int64) error {
+	cmdTag, err := s.conn.Exec(ctx, `DELETE FROM site_groups WHERE id = $1 AND organization_id = $2`, groupID, orgID)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Jenis error domain yang dikembalikan Store selain ErrRecordNotFound. Cek dengan errors.Is; pesan *Error
+// aman ditampilkan ke client.
+var (
+	// ErrConflict: data bentrok dengan data yang sudah ada, misalnya email yang sudah terdaftar
+	ErrConflict = errors.New("conflict")
+	// ErrValidation: input tidak valid, misalnya tag terlalu panjang atau data yang dirujuk tidak ada
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error adalah error domain dari Store. errors.Is(err, e.Kind) bernilai true.
+type Error struct {
+	Kind error
+	// Field adalah nama field input yang menyebabkan error, jika ada
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func conflictError(field, message string) error {
+	return &Error{Kind: ErrConflict, Field: field, Message: message}
+}
+
+func validationError(field, message string) error {
+	return &Error{Kind: ErrValidation, Field: field, Message: message}
+}
+
+// uniqueViolation mengembalikan ErrConflict untuk pelanggaran constraint UNIQUE di tabel tersebut.
+func uniqueViolation(table string) error {
+	switch table {
+	case "users":
+		return conflictError("email", "email already registered")
+	case "user_identities":
+		return conflictError("", "identity is already linked to a user")
+	case "organization_members":
+		return conflictError("user_id", "user is already a member of the organization")
+	case "teams":
+		return conflictError("name", "team name already exists")
+	}
+	return conflictError("", "record already exists")
+}
+
+// errReferenceNotFound dikembalikan jika data yang dirujuk (foreign key) tidak ada.
+var errReferenceNotFound = validationError("", "referenced record does not exist")
+
+// pgError menerjemahkan pelanggaran constraint PostgreSQL menjadi error domain. Dipakai oleh pgConn untuk
+// semua query SQLStore.
+func pgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch pgErr.Code {
+	case "23505": // unique_violation
+		return uniqueViolation(pgErr.TableName)
+	case "23503": // foreign_key_violation
+		return errReferenceNotFound
+	}
+	return err
+}
+
+// sqliteConstraintError menerjemahkan pelanggaran constraint SQLite menjadi error domain.
+func sqliteConstraintError(err error) error {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return err
+	}
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		// Pesan SQLite diakhiri "UNIQUE constraint failed: <tabel>.<kolom>, ... (<kode>)"
+		msg := sqliteErr.Error()
+		table, _, _ := strings.Cut(msg[strings.LastIndex(msg, "failed: ")+len("failed: "):], ".")
+		return uniqueViolation(table)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return errReferenceNotFound
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// failingConn adalah DBTX yang setiap operasinya gagal dengan err.
+type failingConn struct {
+	err error
+}
+
+func (c failingConn) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, c.err
+}
+
+func (c failingConn) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, c.err
+}
+
+func (c failingConn) QueryRow(context.Context, string, ...any) pgx.Row {
+	return failingRow(c)
+}
+
+func (c failingConn) CopyFrom(context.Context, pgx.Identifier, []string, pgx.CopyFromSource) (int64, error) {
+	return 0, c.err
+}
+
+func (c failingConn) Begin(context.Context) (pgx.Tx, error) {
+	return failingTx{failingConn: c}, nil
+}
+
+type failingRow failingConn
+
+func (r failingRow) Scan(...any) error {
+	return r.err
+}
+
+type failingTx struct {
+	pgx.Tx
+	failingConn
+}
+
+func (t failingTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.failingConn.Exec(ctx, sql, args...)
+}
+
+func (t failingTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.failingConn.Query(ctx, sql, args...)
+}
+
+func (t failingTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.failingConn.QueryRow(ctx, sql, args...)
+}
+
+func (t failingTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return t.failingConn.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+func (t failingTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return t.failingConn.Begin(ctx)
+}
+
+func (t failingTx) Commit(context.Context) error {
+	return t.err
+}
+
+func (t failingTx) Rollback(context.Context) error {
+	return nil
+}
+
+func TestPgConnTranslatesConstraintErrors(t *testing.T) {
+	ctx := context.Background()
+	unique := &pgconn.PgError{Code: "23505", TableName: "users"}
+	foreignKey := &pgconn.PgError{Code: "23503", TableName: "sites"}
+
+	cases := []struct {
+		name string
+		run  func(DBTX) error
+	}{
+		{"exec", func(c DBTX) error { _, err := c.Exec(ctx, ""); return err }},
+		{"query", func(c DBTX) error { _, err := c.Query(ctx, ""); return err }},
+		{"query row", func(c DBTX) error { return c.QueryRow(ctx, "").Scan() }},
+		{"copy from", func(c DBTX) error { _, err := c.CopyFrom(ctx, nil, nil, nil); return err }},
+		{"tx query row", func(c DBTX) error {
+			tx, err := c.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			return tx.QueryRow(ctx, "").Scan()
+		}},
+		{"tx commit", func(c DBTX) error {
+			tx, err := c.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			return tx.Commit(ctx)
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.run(pgConn{failingConn{err: unique}})
+			var domainErr *Error
+			if !errors.Is(err, ErrConflict) || !errors.As(err, &domainErr) || domainErr.Field != "email" {
+				t.Fatalf("unique violation: got %v, want email conflict", err)
+			}
+			if err := tc.run(pgConn{failingConn{err: foreignKey}}); !errors.Is(err, ErrValidation) {
+				t.Fatalf("foreign key violation: got %v, want ErrValidation", err)
+			}
+		})
+	}
+}
+
+func TestPgConnKeepsOtherErrors(t *testing.T) {
+	row := pgConn{failingConn{err: pgx.ErrNoRows}}.QueryRow(context.Background(), "")
+	if err := row.Scan(); !errors.Is(err, ErrRecordNotFound) {
+		t.Fatalf("got %v, want ErrRecordNotFound", err)
+	}
+}
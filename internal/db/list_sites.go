@@ -0,0 +1,263 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status site berdasarkan hasil pengecekan terakhir
+const (
+	SiteStatusUp      = "up"
+	SiteStatusDown    = "down"
+	SiteStatusPaused  = "paused"
+	SiteStatusUnknown = "unknown"
+)
+
+// Kolom pengurutan yang didukung ListSites
+const (
+	SiteSortName         = "name"
+	SiteSortCreatedAt    = "created_at"
+	SiteSortResponseTime = "response_time"
+	SiteSortUptime       = "uptime"
+)
+
+// Batas jumlah site per halaman
+const (
+	DefaultSitePageSize = 50
+	MaxSitePageSize     = 200
+)
+
+// ErrInvalidCursor dikembalikan jika cursor rusak atau dibuat untuk pengurutan yang berbeda.
+var ErrInvalidCursor = validationError("cursor", "invalid cursor")
+
+type ListSitesParams struct {
+	OrganizationID int64
+	// Status bernilai kosong, SiteStatusUp, SiteStatusDown atau SiteStatusPaused
+	Status string
+	Tag    string
+	Type   string
+	// Search mencari substring pada URL atau nama (tidak peka huruf besar/kecil)
+	Search     string
+	SortBy     string
+	Descending bool
+	// UptimeSince adalah awal rentang perhitungan uptime yang ditampilkan dan dipakai untuk pengurutan
+	UptimeSince time.Time
+	// Limit 0 berarti semua site dikembalikan dalam satu halaman
+	Limit  int
+	Cursor string
+}
+
+// SiteListItem adalah site beserta ringkasan hasil pengecekannya.
+type SiteListItem struct {
+	Site
+	Status             string     `json:"status"`
+	LastResponseTimeMs *int       `json:"last_response_time_ms"`
+	LastCheckedAt      *time.Time `json:"last_checked_at"`
+	// UptimePercent bernilai nil jika belum ada pengecekan dalam rentang waktu
+	UptimePercent *float64 `json:"uptime_percent"`
+}
+
+type ListSitesResult struct {
+	Sites []SiteListItem `json:"sites"`
+	// Total adalah jumlah semua site yang cocok dengan filter, bukan hanya di halaman ini
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// SiteStatusOf menentukan status site dari hasil pengecekan terakhirnya; lastIsUp nil berarti belum pernah diperiksa.
+func SiteStatusOf(site Site, lastIsUp *bool) string {
+	switch {
+	case site.PausedAt != nil:
+		return SiteStatusPaused
+	case lastIsUp == nil:
+		return SiteStatusUnknown
+	case *lastIsUp:
+		return SiteStatusUp
+	default:
+		return SiteStatusDown
+	}
+}
+
+func (arg ListSitesParams) withDefaults() ListSitesParams {
+	if arg.SortBy == "" {
+		arg.SortBy = SiteSortCreatedAt
+	}
+	if arg.Limit > MaxSitePageSize {
+		arg.Limit = MaxSitePageSize
+	}
+	return arg
+}
+
+// likePattern membuat pola LIKE untuk pencarian substring dengan karakter wildcard di-escape.
+func likePattern(search string) string {
+	if search == "" {
+		return ""
+	}
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(search) + "%"
+}
+
+// siteCursor menyimpan posisi baris terakhir di halaman sebelumnya. Nilai kunci urut disimpan
+// sebagai teks persis seperti yang dikembalikan database agar perbandingan berikutnya konsisten.
+type siteCursor struct {
+	SortBy     string `json:"s"`
+	Descending bool   `json:"d"`
+	Value      string `json:"v"`
+	ID         int64  `json:"id"`
+}
+
+func encodeSiteCursor(arg ListSitesParams, value string, id int64) string {
+	data, _ := json.Marshal(siteCursor{SortBy: arg.SortBy, Descending: arg.Descending, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSiteCursor mengembalikan nil jika arg.Cursor kosong (halaman pertama).
+func decodeSiteCursor(arg ListSitesParams) (*siteCursor, error) {
+	if arg.Cursor == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(arg.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c siteCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if c.SortBy != arg.SortBy || c.Descending != arg.Descending {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// siteStatusCondition mengembalikan kondisi SQL untuk filter status; isUp dan isDown berbeda antar dialek.
+func siteStatusCondition(status, isUp, isDown string) (string, error) {
+	switch status {
+	case "":
+		return "TRUE", nil
+	case SiteStatusPaused:
+		return "f.site_paused_at IS NOT NULL", nil
+	case SiteStatusUp:
+		return "f.site_paused_at IS NULL AND " + isUp, nil
+	case SiteStatusDown:
+		return "f.site_paused_at IS NULL AND " + isDown, nil
+	default:
+		return "", validationError("status", fmt.Sprintf("unknown status filter %q", status))
+	}
+}
+
+// ListSites mengembalikan satu halaman site aktif milik organisasi sesuai filter, pencarian dan pengurutan.
+func (s *SQLStore) ListSites(ctx context.Context, arg ListSitesParams) (ListSitesResult, error) {
+	arg = arg.withDefaults()
+	cursor, err := decodeSiteCursor(arg)
+	if err != nil {
+		return ListSitesResult{}, err
+	}
+
+	var sortExpr, sortType string
+	switch arg.SortBy {
+	case SiteSortName:
+		sortExpr, sortType = `lower(COALESCE(NULLIF(sites.name, ''), sites.url))`, "text"
+	case SiteSortCreatedAt:
+		sortExpr, sortType = `sites.created_at`, "timestamptz"
+	case SiteSortResponseTime:
+		sortExpr, sortType = `COALESCE(f.last_response_time_ms, -1)::float8`, "float8"
+	case SiteSortUptime:
+		sortExpr, sortType = `COALESCE(f.uptime_percent, -1)`, "float8"
+	default:
+		return ListSitesResult{}, validationError("sort", fmt.Sprintf("unknown sort %q", arg.SortBy))
+	}
+	statusCond, err := siteStatusCondition(arg.Status, "f.last_is_up", "NOT f.last_is_up")
+	if err != nil {
+		return ListSitesResult{}, err
+	}
+
+	// Hasil pengecekan terakhir dan uptime dihitung per site sebelum filter status diterapkan
+	filtered := `WITH filtered AS (
+        SELECT * FROM (
+          SELECT sites.id AS site_id, sites.paused_at AS site_paused_at,
+            lc.is_up AS last_is_up, lc.response_time_ms AS last_response_time_ms, lc.checked_at AS last_checked_at,
+            up.uptime_percent
+          FROM sites
+          LEFT JOIN LATERAL (
+            SELECT is_up, response_time_ms, checked_at FROM health_checks
+            WHERE site_id = sites.id ORDER BY checked_at DESC LIMIT 1
+          ) lc ON TRUE
+          LEFT JOIN LATERAL (
+            SELECT round(avg(CASE WHEN is_up THEN 100 ELSE 0 END), 4)::float8 AS uptime_percent FROM health_checks
+            WHERE site_id = sites.id AND checked_at >= $2
+          ) up ON TRUE
+          WHERE sites.organization_id = $1 AND sites.archived_at IS NULL
+            AND ($3::varchar = '' OR sites.check_type = $3)
+            AND ($4::varchar = '' OR EXISTS (SELECT 1 FROM site_tags t WHERE t.site_id = sites.id AND t.tag = $4))
+            AND ($5::varchar = '' OR sites.url ILIKE $5 ESCAPE '\' OR sites.name ILIKE $5 ESCAPE '\')
+        ) f WHERE ` + statusCond + `
+      )`
+	args := []any{arg.OrganizationID, arg.UptimeSince, arg.Type, arg.Tag, likePattern(arg.Search)}
+
+	var result ListSitesResult
+	if err := s.conn.QueryRow(ctx, filtered+` SELECT count(*) FROM filtered`, args...).Scan(&result.Total); err != nil {
+		return ListSitesResult{}, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if arg.Descending {
+		dir, cmp = "DESC", "<"
+	}
+	cursorCond := "TRUE"
+	if cursor != nil {
+		cursorCond = fmt.Sprintf(`(%s, sites.id) %s ($6::text::%s, $7::bigint)`, sortExpr, cmp, sortType)
+		args = append(args, cursor.Value, cursor.ID)
+	}
+	// LIMIT NULL berarti tanpa batas
+	var limit any
+	if arg.Limit > 0 {
+		limit = arg.Limit + 1
+	}
+	args = append(args, limit)
+
+	query := filtered + ` SELECT ` + siteColumns + `, f.last_is_up, f.last_response_time_ms, f.last_checked_at, f.uptime_percent, (` + sortExpr + `)::text
+              FROM filtered f JOIN sites ON sites.id = f.site_id
+              WHERE ` + cursorCond + `
+              ORDER BY ` + sortExpr + ` ` + dir + `, sites.id ` + dir + `
+              LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := s.conn.Query(ctx, query, args...)
+	if err != nil {
+		return ListSitesResult{}, err
+	}
+	defer rows.Close()
+
+	result.Sites = []SiteListItem{}
+	sortKeys := []string{}
+	for rows.Next() {
+		var item SiteListItem
+		var lastIsUp *bool
+		var sortKey string
+		item.Site, err = scanSite(rows, &lastIsUp, &item.LastResponseTimeMs, &item.LastCheckedAt, &item.UptimePercent, &sortKey)
+		if err != nil {
+			return ListSitesResult{}, err
+		}
+		item.Status = SiteStatusOf(item.Site, lastIsUp)
+		result.Sites = append(result.Sites, item)
+		sortKeys = append(sortKeys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		return ListSitesResult{}, err
+	}
+	return paginateSites(arg, result, sortKeys), nil
+}
+
+// paginateSites membuang baris tambahan (Limit+1) dan membuat cursor halaman berikutnya jika baris tersebut ada.
+func paginateSites(arg ListSitesParams, result ListSitesResult, sortKeys []string) ListSitesResult {
+	if arg.Limit > 0 && len(result.Sites) > arg.Limit {
+		result.Sites = result.Sites[:arg.Limit]
+		last := result.Sites[arg.Limit-1]
+		result.NextCursor = encodeSiteCursor(arg, sortKeys[arg.Limit-1], last.ID)
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// LoginThrottle adalah hitungan login gagal berturut-turut untuk satu email. Email yang tidak terdaftar
+// juga dihitung agar penguncian tidak membocorkan email mana yang memiliki akun.
+type LoginThrottle struct {
+	Email         string     `json:"email"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+// Locked bernilai true jika login untuk email ini masih dikunci pada waktu now.
+func (t LoginThrottle) Locked(now time.Time) bool {
+	return t.LockedUntil != nil && t.LockedUntil.After(now)
+}
+
+type RecordLoginFailureParams struct {
+	Email string
+	// Hitungan dimulai ulang dari 1 jika kegagalan terakhir terjadi sebelum ResetBefore
+	ResetBefore time.Time
+}
+
+const loginThrottleColumns = `email, failures, last_failure_at, locked_until`
+
+func (s *SQLStore) GetLoginThrottle(ctx context.Context, email string) (LoginThrottle, error) {
+	var t LoginThrottle
+	err := s.conn.QueryRow(ctx, `SELECT `+loginThrottleColumns+` FROM login_throttles WHERE email = $1`, email).
+		Scan(&t.Email, &t.Failures, &t.LastFailureAt, &t.LockedUntil)
+	return t, err
+}
+
+// RecordLoginFailure menambah hitungan login gagal untuk email dan mengembalikan hitungan terbarunya.
+func (s *SQLStore) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error) {
+	query := `INSERT INTO login_throttles (email, failures) VALUES ($1, 1)
+              ON CONFLICT (email) DO UPDATE SET
+                failures = CASE WHEN login_throttles.last_failure_at < $2 THEN 1 ELSE login_throttles.failures + 1 END,
+                last_failure_at = now()
+              RETURNING ` + loginThrottleColumns
+
+	var t LoginThrottle
+	err := s.conn.QueryRow(ctx, query, arg.Email, arg.ResetBefore).
+		Scan(&t.Email, &t.Failures, &t.LastFailureAt, &t.LockedUntil)
+	return t, err
+}
+
+// LockLogin menolak login untuk email sampai waktu until.
+func (s *SQLStore) LockLogin(ctx context.Context, email string, until time.Time) error {
+	_, err := s.conn.Exec(ctx, `UPDATE login_throttles SET locked_until = $2 WHERE email = $1`, email, until)
+	return err
+}
+
+// ResetLoginFailures menghapus hitungan login gagal, misalnya setelah login berhasil atau password direset.
+func (s *SQLStore) ResetLoginFailures(ctx context.Context, email string) error {
+	_, err := s.conn.Exec(ctx, `DELETE FROM login_throttles WHERE email = $1`, email)
+	return err
+}
+
+// --- SQLite ---
+
+func scanSQLiteLoginThrottle(row sqliteScanner) (LoginThrottle, error) {
+	var t LoginThrottle
+	var lastFailureAt string
+	var lockedUntil sql.NullString
+	if err := row.Scan(&t.Email, &t.Failures, &lastFailureAt, &lockedUntil); err != nil {
+		return LoginThrottle{}, err
+	}
+	var err error
+	if t.LastFailureAt, err = parseSQLiteTime(lastFailureAt); err != nil {
+		return LoginThrottle{}, err
+	}
+	t.LockedUntil, err = parseNullSQLiteTime(lockedUntil)
+	return t, err
+}
+
+func (s *SQLiteStore) GetLoginThrottle(ctx context.Context, email string) (LoginThrottle, error) {
+	t, err := scanSQLiteLoginThrottle(s.conn.QueryRowContext(ctx, `SELECT `+loginThrottleColumns+` FROM login_throttles WHERE email = ?`, email))
+	return t, sqliteError(err)
+}
+
+func (s *SQLiteStore) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error) {
+	query := `INSERT INTO login_throttles (email, failures, last_failure_at) VALUES (?1, 1, ?2)
+              ON CONFLICT (email) DO UPDATE SET
+                failures = CASE WHEN login_throttles.last_failure_at < ?3 THEN 1 ELSE login_throttles.failures + 1 END,
+                last_failure_at = excluded.last_failure_at
+              RETURNING ` + loginThrottleColumns
+
+	t, err := scanSQLiteLoginThrottle(s.conn.QueryRowContext(ctx, query, arg.Email, sqliteTime(time.Now()), sqliteTime(arg.ResetBefore)))
+	return t, sqliteError(err)
+}
+
+func (s *SQLiteStore) LockLogin(ctx context.Context, email string, until time.Time) error {
+	_, err := s.conn.ExecContext(ctx, `UPDATE login_throttles SET locked_until = ? WHERE email = ?`, sqliteTime(until), email)
+	return err
+}
+
+func (s *SQLiteStore) ResetLoginFailures(ctx context.Context, email string) error {
+	_, err := s.conn.ExecContext(ctx, `DELETE FROM login_throttles WHERE email = ?`, email)
+	return err
+}
+
+// --- Memory ---
+
+func (s *MemoryStore) GetLoginThrottle(ctx context.Context, email string) (LoginThrottle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.loginThrottles[email]
+	if !ok {
+		return LoginThrottle{}, ErrRecordNotFound
+	}
+	return t, nil
+}
+
+func (s *MemoryStore) RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.loginThrottles[arg.Email]
+	if !ok || t.LastFailureAt.Before(arg.ResetBefore) {
+		t.Email = arg.Email
+		t.Failures = 0
+	}
+	t.Failures++
+	t.LastFailureAt = time.Now()
+	setEntry(s, s.loginThrottles, arg.Email, t)
+	return t, nil
+}
+
+func (s *MemoryStore) LockLogin(ctx context.Context, email string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.loginThrottles[email]; ok {
+		t.LockedUntil = &until
+		setEntry(s, s.loginThrottles, email, t)
+	}
+	return nil
+}
+
+func (s *MemoryStore) ResetLoginFailures(ctx context.Context, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleteEntry(s, s.loginThrottles, email)
+	return nil
+}
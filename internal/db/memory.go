@@ -0,0 +1,790 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore adalah implementasi Store yang menyimpan semua data di memori.
+// Data hilang saat proses berhenti, sehingga hanya cocok untuk mode demo dan testing.
+type MemoryStore struct {
+	// mu melindungi memoryData. Store di dalam ExecTx memakai memoryNoLock karena transaksi sudah memegang
+	// lock store asalnya sampai selesai.
+	mu memoryLocker
+	*memoryData
+	// undo terisi di dalam ExecTx dan mencatat cara membatalkan setiap penulisan transaksi tersebut
+	undo *memoryUndoLog
+}
+
+// memoryData adalah seluruh data MemoryStore. Penulisan harus lewat setEntry, deleteEntry, appendEntry atau
+// replaceEntries agar dapat dibatalkan jika transaksi gagal.
+type memoryData struct {
+	users        map[int64]User
+	sites        map[int64]Site
+	groups       map[int64]SiteGroup
+	healthChecks []HealthCheck
+	sessions     map[int64]Session
+	// usedRefreshTokens memetakan hash refresh token yang sudah dirotasi ke session-nya
+	usedRefreshTokens map[string]int64
+	apiKeys           map[int64]APIKey
+	// passwordResetTokens menyimpan token reset password, termasuk yang sudah dipakai
+	passwordResetTokens map[int64]PasswordResetToken
+	totps               map[int64]UserTOTP
+	recoveryCodes       map[int64]memoryRecoveryCode
+	userIdentities      map[int64]UserIdentity
+	organizations       map[int64]Organization
+	organizationMembers map[organizationMemberKey]OrganizationMember
+	teams               map[int64]Team
+	// auditEvents berurutan menurut ID
+	auditEvents []AuditEvent
+	// loginThrottles dikunci dengan email yang sudah dinormalisasi
+	loginThrottles map[string]LoginThrottle
+
+	nextUserID        int64
+	nextSiteID        int64
+	nextGroupID       int64
+	nextHealthCheckID int64
+	nextSessionID     int64
+	nextAPIKeyID      int64
+
+	nextPasswordResetTokenID int64
+	nextRecoveryCodeID       int64
+	nextUserIdentityID       int64
+	nextOrganizationID       int64
+	nextTeamID               int64
+	nextAuditEventID         int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{mu: &sync.RWMutex{}, memoryData: &memoryData{
+		users:             make(map[int64]User),
+		sites:             make(map[int64]Site),
+		groups:            make(map[int64]SiteGroup),
+		sessions:          make(map[int64]Session),
+		usedRefreshTokens: make(map[string]int64),
+		apiKeys:           make(map[int64]APIKey),
+
+		passwordResetTokens: make(map[int64]PasswordResetToken),
+		totps:               make(map[int64]UserTOTP),
+		recoveryCodes:       make(map[int64]memoryRecoveryCode),
+		userIdentities:      make(map[int64]UserIdentity),
+		organizations:       make(map[int64]Organization),
+		organizationMembers: make(map[organizationMemberKey]OrganizationMember),
+		teams:               make(map[int64]Team),
+		loginThrottles:      make(map[string]LoginThrottle),
+	}}
+}
+
+// --- User ---
+
+func (s *MemoryStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Email == arg.Email {
+			return User{}, uniqueViolation("users")
+		}
+	}
+
+	s.nextUserID++
+	u := User{
+		ID:           s.nextUserID,
+		Username:     arg.Username,
+		Email:        arg.Email,
+		PasswordHash: arg.PasswordHash,
+		CreatedAt:    time.Now(),
+	}
+	setEntry(s, s.users, u.ID, u)
+	return u, nil
+}
+
+func (s *MemoryStore) GetUser(ctx context.Context, userID int64) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return User{}, ErrRecordNotFound
+	}
+	return u, nil
+}
+
+func (s *MemoryStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, u := range s.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return User{}, ErrRecordNotFound
+}
+
+func (s *MemoryStore) VerifyUserEmail(ctx context.Context, userID int64, email string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok || u.Email != email {
+		return User{}, ErrRecordNotFound
+	}
+	if u.EmailVerifiedAt == nil {
+		now := time.Now()
+		u.EmailVerifiedAt = &now
+		setEntry(s, s.users, userID, u)
+	}
+	return u, nil
+}
+
+// --- Site ---
+
+func (s *MemoryStore) CreateSite(ctx context.Context, arg CreateSiteParams) (Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return Site{}, errReferenceNotFound
+	}
+	if _, ok := s.organizations[arg.OrganizationID]; !ok {
+		return Site{}, errReferenceNotFound
+	}
+
+	arg = arg.withDefaults()
+	s.nextSiteID++
+	site := Site{
+		ID:                   s.nextSiteID,
+		OrganizationID:       arg.OrganizationID,
+		UserID:               arg.UserID,
+		URL:                  arg.URL,
+		Name:                 arg.Name,
+		Description:          arg.Description,
+		CheckType:            arg.CheckType,
+		CheckIntervalSeconds: arg.CheckIntervalSeconds,
+		TimeoutSeconds:       arg.TimeoutSeconds,
+		CreatedAt:            time.Now(),
+		Tags:                 []string{},
+	}
+	setEntry(s, s.sites, site.ID, site)
+	return site, nil
+}
+
+// activeSite mengembalikan site milik organisasi yang belum diarsipkan. Pemanggil harus memegang s.mu.
+func (s *MemoryStore) activeSite(siteID int64, orgID int64) (Site, bool) {
+	site, ok := s.sites[siteID]
+	if !ok || site.OrganizationID != orgID || site.ArchivedAt != nil {
+		return Site{}, false
+	}
+	return site, true
+}
+
+func (s *MemoryStore) UpdateSite(ctx context.Context, siteID int64, orgID int64, arg UpdateSiteParams) (Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.activeSite(siteID, orgID)
+	if !ok {
+		return Site{}, ErrRecordNotFound
+	}
+	if arg.URL != nil {
+		site.URL = *arg.URL
+	}
+	if arg.Name != nil {
+		site.Name = *arg.Name
+	}
+	if arg.Description != nil {
+		site.Description = *arg.Description
+	}
+	if arg.CheckIntervalSeconds != nil {
+		site.CheckIntervalSeconds = *arg.CheckIntervalSeconds
+	}
+	if arg.TimeoutSeconds != nil {
+		site.TimeoutSeconds = *arg.TimeoutSeconds
+	}
+	setEntry(s, s.sites, siteID, site)
+	return site, nil
+}
+
+func (s *MemoryStore) PauseSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.activeSite(siteID, orgID)
+	if !ok || site.PausedAt != nil {
+		return Site{}, ErrRecordNotFound
+	}
+	now := time.Now()
+	site.PausedAt = &now
+	setEntry(s, s.sites, siteID, site)
+	return site, nil
+}
+
+func (s *MemoryStore) ResumeSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.activeSite(siteID, orgID)
+	if !ok || site.PausedAt == nil {
+		return Site{}, ErrRecordNotFound
+	}
+	site.PausedAt = nil
+	setEntry(s, s.sites, siteID, site)
+	return site, nil
+}
+
+func (s *MemoryStore) SetSiteTags(ctx context.Context, siteID int64, orgID int64, tags []string) (Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.activeSite(siteID, orgID)
+	if !ok {
+		return Site{}, ErrRecordNotFound
+	}
+	// Salin dan urutkan agar sama dengan hasil SQLStore
+	site.Tags = append([]string{}, tags...)
+	sort.Strings(site.Tags)
+	site.Tags = slices.Compact(site.Tags)
+	setEntry(s, s.sites, siteID, site)
+	return site, nil
+}
+
+func (s *MemoryStore) GetSite(ctx context.Context, siteID int64) (Site, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	site, ok := s.sites[siteID]
+	if !ok {
+		return Site{}, ErrRecordNotFound
+	}
+	return site, nil
+}
+
+func (s *MemoryStore) GetSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sites := []Site{}
+	for _, site := range s.sites {
+		if site.OrganizationID == orgID && site.ArchivedAt == nil {
+			sites = append(sites, site)
+		}
+	}
+	// Samakan urutan dengan SQLStore: terbaru lebih dulu
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].CreatedAt.Equal(sites[j].CreatedAt) {
+			return sites[i].ID > sites[j].ID
+		}
+		return sites[i].CreatedAt.After(sites[j].CreatedAt)
+	})
+	return sites, nil
+}
+
+func (s *MemoryStore) CountActiveSitesByUserID(ctx context.Context, userID int64) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, site := range s.sites {
+		if site.UserID == userID && site.ArchivedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// memorySortKey adalah nilai kunci urut sebuah site; hanya satu field yang dipakai sesuai SortBy.
+type memorySortKey struct {
+	text   string
+	time   time.Time
+	number float64
+}
+
+func (k memorySortKey) compare(other memorySortKey) int {
+	if c := strings.Compare(k.text, other.text); c != 0 {
+		return c
+	}
+	if c := k.time.Compare(other.time); c != 0 {
+		return c
+	}
+	return cmp.Compare(k.number, other.number)
+}
+
+// String menghasilkan nilai yang disimpan di cursor dan dapat dibaca kembali oleh parseMemorySortKey.
+func (k memorySortKey) String(sortBy string) string {
+	switch sortBy {
+	case SiteSortName:
+		return k.text
+	case SiteSortCreatedAt:
+		return k.time.Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatFloat(k.number, 'g', -1, 64)
+	}
+}
+
+func parseMemorySortKey(sortBy, value string) (memorySortKey, error) {
+	switch sortBy {
+	case SiteSortName:
+		return memorySortKey{text: value}, nil
+	case SiteSortCreatedAt:
+		t, err := time.Parse(time.RFC3339Nano, value)
+		return memorySortKey{time: t}, err
+	default:
+		n, err := strconv.ParseFloat(value, 64)
+		return memorySortKey{number: n}, err
+	}
+}
+
+func (s *MemoryStore) ListSites(ctx context.Context, arg ListSitesParams) (ListSitesResult, error) {
+	arg = arg.withDefaults()
+	cursor, err := decodeSiteCursor(arg)
+	if err != nil {
+		return ListSitesResult{}, err
+	}
+	switch arg.SortBy {
+	case SiteSortName, SiteSortCreatedAt, SiteSortResponseTime, SiteSortUptime:
+	default:
+		return ListSitesResult{}, validationError("sort", fmt.Sprintf("unknown sort %q", arg.SortBy))
+	}
+	if _, err := siteStatusCondition(arg.Status, "", ""); err != nil {
+		return ListSitesResult{}, err
+	}
+
+	sites, _ := s.GetSitesByOrganizationID(ctx, arg.OrganizationID)
+
+	s.mu.RLock()
+	latest := map[int64]HealthCheck{}
+	upChecks, totalChecks := map[int64]int{}, map[int64]int{}
+	for _, hc := range s.healthChecks {
+		if prev, ok := latest[hc.SiteID]; !ok || hc.CheckedAt.After(prev.CheckedAt) {
+			latest[hc.SiteID] = hc
+		}
+		if !hc.CheckedAt.Before(arg.UptimeSince) {
+			totalChecks[hc.SiteID]++
+			if hc.IsUp {
+				upChecks[hc.SiteID]++
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	search := strings.ToLower(arg.Search)
+	items := []SiteListItem{}
+	keys := map[int64]memorySortKey{}
+	for _, site := range sites {
+		if arg.Type != "" && site.CheckType != arg.Type {
+			continue
+		}
+		if arg.Tag != "" && !slices.Contains(site.Tags, arg.Tag) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(site.URL), search) && !strings.Contains(strings.ToLower(site.Name), search) {
+			continue
+		}
+
+		item := SiteListItem{Site: site}
+		var lastIsUp *bool
+		if hc, ok := latest[site.ID]; ok {
+			isUp, responseTime, checkedAt := hc.IsUp, hc.ResponseTimeMs, hc.CheckedAt
+			lastIsUp, item.LastResponseTimeMs, item.LastCheckedAt = &isUp, &responseTime, &checkedAt
+		}
+		if total := totalChecks[site.ID]; total > 0 {
+			uptime := math.Round(float64(upChecks[site.ID])/float64(total)*100*1e4) / 1e4
+			item.UptimePercent = &uptime
+		}
+		item.Status = SiteStatusOf(site, lastIsUp)
+		if arg.Status != "" && item.Status != arg.Status {
+			continue
+		}
+
+		var key memorySortKey
+		switch arg.SortBy {
+		case SiteSortName:
+			name := site.Name
+			if name == "" {
+				name = site.URL
+			}
+			key.text = strings.ToLower(name)
+		case SiteSortCreatedAt:
+			key.time = site.CreatedAt
+		case SiteSortResponseTime:
+			key.number = -1
+			if item.LastResponseTimeMs != nil {
+				key.number = float64(*item.LastResponseTimeMs)
+			}
+		case SiteSortUptime:
+			key.number = -1
+			if item.UptimePercent != nil {
+				key.number = *item.UptimePercent
+			}
+		}
+		keys[site.ID] = key
+		items = append(items, item)
+	}
+
+	// Urutkan berdasarkan (kunci, id) seperti ORDER BY pada SQLStore
+	compareKeys := func(aKey memorySortKey, aID int64, bKey memorySortKey, bID int64) int {
+		c := aKey.compare(bKey)
+		if c == 0 {
+			c = cmp.Compare(aID, bID)
+		}
+		if arg.Descending {
+			return -c
+		}
+		return c
+	}
+	slices.SortFunc(items, func(a, b SiteListItem) int { return compareKeys(keys[a.ID], a.ID, keys[b.ID], b.ID) })
+
+	var cursorKey memorySortKey
+	if cursor != nil {
+		if cursorKey, err = parseMemorySortKey(arg.SortBy, cursor.Value); err != nil {
+			return ListSitesResult{}, ErrInvalidCursor
+		}
+	}
+
+	result := ListSitesResult{Total: int64(len(items)), Sites: []SiteListItem{}}
+	sortKeys := []string{}
+	for _, item := range items {
+		if cursor != nil && compareKeys(keys[item.ID], item.ID, cursorKey, cursor.ID) <= 0 {
+			continue
+		}
+		result.Sites = append(result.Sites, item)
+		sortKeys = append(sortKeys, keys[item.ID].String(arg.SortBy))
+		if arg.Limit > 0 && len(result.Sites) > arg.Limit {
+			break
+		}
+	}
+	return paginateSites(arg, result, sortKeys), nil
+}
+
+func (s *MemoryStore) GetAllSites(ctx context.Context) ([]Site, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sites := make([]Site, 0, len(s.sites))
+	for _, site := range s.sites {
+		if site.ArchivedAt == nil {
+			sites = append(sites, site)
+		}
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].ID < sites[j].ID })
+	return sites, nil
+}
+
+func (s *MemoryStore) GetArchivedSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sites := []Site{}
+	for _, site := range s.sites {
+		if site.OrganizationID == orgID && site.ArchivedAt != nil {
+			sites = append(sites, site)
+		}
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].ArchivedAt.After(*sites[j].ArchivedAt) })
+	return sites, nil
+}
+
+func (s *MemoryStore) ArchiveSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.activeSite(siteID, orgID)
+	if !ok {
+		return Site{}, ErrRecordNotFound
+	}
+	now := time.Now()
+	site.ArchivedAt = &now
+	setEntry(s, s.sites, siteID, site)
+	return site, nil
+}
+
+func (s *MemoryStore) RestoreSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	site, ok := s.sites[siteID]
+	if !ok || site.OrganizationID != orgID || site.ArchivedAt == nil {
+		return Site{}, ErrRecordNotFound
+	}
+	site.ArchivedAt = nil
+	setEntry(s, s.sites, siteID, site)
+	return site, nil
+}
+
+func (s *MemoryStore) PurgeArchivedSites(ctx context.Context, archivedBefore time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := map[int64]bool{}
+	for id, site := range s.sites {
+		if site.ArchivedAt != nil && site.ArchivedAt.Before(archivedBefore) {
+			purged[id] = true
+			deleteEntry(s, s.sites, id)
+		}
+	}
+
+	for id, g := range s.groups {
+		g.SiteIDs = slices.DeleteFunc(slices.Clone(g.SiteIDs), func(siteID int64) bool { return purged[siteID] })
+		setEntry(s, s.groups, id, g)
+	}
+
+	kept := make([]HealthCheck, 0, len(s.healthChecks))
+	for _, hc := range s.healthChecks {
+		if !purged[hc.SiteID] {
+			kept = append(kept, hc)
+		}
+	}
+	replaceEntries(s, &s.healthChecks, kept)
+	return int64(len(purged)), nil
+}
+
+// --- HealthCheck ---
+
+func (s *MemoryStore) CreateHealthCheck(ctx context.Context, arg CreateHealthCheckParams) (HealthCheck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hc := HealthCheck{
+		SiteID:         arg.SiteID,
+		StatusCode:     arg.StatusCode,
+		ResponseTimeMs: arg.ResponseTimeMs,
+		IsUp:           arg.IsUp,
+		CheckedAt:      time.Now(),
+	}
+	return s.insertHealthCheck(hc)
+}
+
+func (s *MemoryStore) CreateHealthChecks(ctx context.Context, checks []HealthCheck) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Validasi semua baris lebih dulu agar perilakunya sama dengan COPY: semua atau tidak sama sekali
+	for _, hc := range checks {
+		if _, ok := s.sites[hc.SiteID]; !ok {
+			return 0, errReferenceNotFound
+		}
+	}
+	for _, hc := range checks {
+		if _, err := s.insertHealthCheck(hc); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(checks)), nil
+}
+
+// insertHealthCheck menyimpan satu health check. Pemanggil harus memegang s.mu.
+func (s *MemoryStore) insertHealthCheck(hc HealthCheck) (HealthCheck, error) {
+	if _, ok := s.sites[hc.SiteID]; !ok {
+		return HealthCheck{}, errReferenceNotFound
+	}
+	s.nextHealthCheckID++
+	hc.ID = s.nextHealthCheckID
+	appendEntry(s, &s.healthChecks, hc)
+	return hc, nil
+}
+
+func (s *MemoryStore) StreamHealthChecks(ctx context.Context, arg StreamHealthChecksParams, fn func(HealthCheck) error) error {
+	// Salin baris yang cocok terlebih dulu agar fn tidak dipanggil sambil memegang lock
+	s.mu.RLock()
+	matches := []HealthCheck{}
+	for _, hc := range s.healthChecks {
+		site, ok := s.sites[hc.SiteID]
+		if !ok || site.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		if arg.SiteID != 0 && hc.SiteID != arg.SiteID {
+			continue
+		}
+		if hc.CheckedAt.Before(arg.From) || !hc.CheckedAt.Before(arg.To) {
+			continue
+		}
+		matches = append(matches, hc)
+	}
+	s.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].CheckedAt.Before(matches[j].CheckedAt) })
+	for _, hc := range matches {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(hc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) GetSiteUptime(ctx context.Context, siteID int64, from, to time.Time) (Uptime, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var total, up int64
+	for _, hc := range s.healthChecks {
+		if hc.SiteID != siteID || hc.CheckedAt.Before(from) || !hc.CheckedAt.Before(to) {
+			continue
+		}
+		total++
+		if hc.IsUp {
+			up++
+		}
+	}
+	return newUptime(siteID, total, up), nil
+}
+
+func (s *MemoryStore) GetSitesUptime(ctx context.Context, siteIDs []int64, from, to time.Time) (map[int64]Uptime, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[int64][2]int64{}
+	for _, hc := range s.healthChecks {
+		if !slices.Contains(siteIDs, hc.SiteID) || hc.CheckedAt.Before(from) || !hc.CheckedAt.Before(to) {
+			continue
+		}
+		c := counts[hc.SiteID]
+		c[0]++
+		if hc.IsUp {
+			c[1]++
+		}
+		counts[hc.SiteID] = c
+	}
+	uptimes := make(map[int64]Uptime, len(counts))
+	for siteID, c := range counts {
+		uptimes[siteID] = newUptime(siteID, c[0], c[1])
+	}
+	return uptimes, nil
+}
+
+func (s *MemoryStore) GetLatestHealthChecks(ctx context.Context, siteIDs []int64) (map[int64]HealthCheck, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	latest := make(map[int64]HealthCheck, len(siteIDs))
+	for _, hc := range s.healthChecks {
+		if !slices.Contains(siteIDs, hc.SiteID) {
+			continue
+		}
+		if prev, ok := latest[hc.SiteID]; !ok || hc.CheckedAt.After(prev.CheckedAt) {
+			latest[hc.SiteID] = hc
+		}
+	}
+	return latest, nil
+}
+
+// --- SiteGroup ---
+
+// visibleGroup mengembalikan salinan grup dengan hanya site yang belum diarsipkan. Pemanggil harus memegang s.mu.
+func (s *MemoryStore) visibleGroup(g SiteGroup) SiteGroup {
+	ids := []int64{}
+	for _, id := range g.SiteIDs {
+		if site, ok := s.sites[id]; ok && site.ArchivedAt == nil {
+			ids = append(ids, id)
+		}
+	}
+	g.SiteIDs = ids
+	return g
+}
+
+// ownedSiteIDs menyaring site yang bukan milik organisasi, lalu mengurutkan dan menghapus duplikat. Pemanggil harus memegang s.mu.
+func (s *MemoryStore) ownedSiteIDs(orgID int64, siteIDs []int64) []int64 {
+	ids := []int64{}
+	for _, id := range siteIDs {
+		if site, ok := s.sites[id]; ok && site.OrganizationID == orgID {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+func (s *MemoryStore) CreateSiteGroup(ctx context.Context, arg CreateSiteGroupParams) (SiteGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return SiteGroup{}, errReferenceNotFound
+	}
+	if _, ok := s.organizations[arg.OrganizationID]; !ok {
+		return SiteGroup{}, errReferenceNotFound
+	}
+
+	s.nextGroupID++
+	g := SiteGroup{
+		ID:             s.nextGroupID,
+		OrganizationID: arg.OrganizationID,
+		UserID:         arg.UserID,
+		Name:           arg.Name,
+		CreatedAt:      time.Now(),
+		SiteIDs:        s.ownedSiteIDs(arg.OrganizationID, arg.SiteIDs),
+	}
+	setEntry(s, s.groups, g.ID, g)
+	return s.visibleGroup(g), nil
+}
+
+func (s *MemoryStore) GetSiteGroup(ctx context.Context, groupID int64, orgID int64) (SiteGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	g, ok := s.groups[groupID]
+	if !ok || g.OrganizationID != orgID {
+		return SiteGroup{}, ErrRecordNotFound
+	}
+	return s.visibleGroup(g), nil
+}
+
+func (s *MemoryStore) ListSiteGroups(ctx context.Context, orgID int64) ([]SiteGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groups := []SiteGroup{}
+	for _, g := range s.groups {
+		if g.OrganizationID == orgID {
+			groups = append(groups, s.visibleGroup(g))
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Name == groups[j].Name {
+			return groups[i].ID < groups[j].ID
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups, nil
+}
+
+func (s *MemoryStore) UpdateSiteGroup(ctx context.Context, groupID int64, orgID int64, arg UpdateSiteGroupParams) (SiteGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.groups[groupID]
+	if !ok || g.OrganizationID != orgID {
+		return SiteGroup{}, ErrRecordNotFound
+	}
+	if arg.Name != nil {
+		g.Name = *arg.Name
+	}
+	if arg.SiteIDs != nil {
+		g.SiteIDs = s.ownedSiteIDs(orgID, *arg.SiteIDs)
+	}
+	setEntry(s, s.groups, groupID, g)
+	return s.visibleGroup(g), nil
+}
+
+func (s *MemoryStore) DeleteSiteGroup(ctx context.Context, groupID int64, orgID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.groups[groupID]
+	if !ok || g.OrganizationID != orgID {
+		return ErrRecordNotFound
+	}
+	deleteEntry(s, s.groups, groupID)
+	return nil
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Migrasi ---
+
+// Migration adalah satu versi skema beserta SQL untuk menerapkan dan membatalkannya.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus menunjukkan apakah sebuah migrasi sudah diterapkan.
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// LoadMigrations membaca pasangan file <versi>_<nama>.up.sql / .down.sql dari dir dan mengurutkannya berdasarkan versi.
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, file := range files {
+		base := path.Base(file)
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s must end with .up.sql or .down.sql", base)
+		}
+
+		versionPart, name, ok := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s must be named <version>_<name>", base)
+		}
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", base, err)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// migrationDriver berisi operasi yang berbeda untuk tiap backend database.
+type migrationDriver interface {
+	// lock mengambil lock eksklusif agar dua instance tidak migrasi bersamaan.
+	lock(ctx context.Context) (unlock func(), err error)
+	// ensureVersionTable membuat tabel schema_migrations jika belum ada.
+	ensureVersionTable(ctx context.Context) error
+	appliedMigrations(ctx context.Context) (map[int64]time.Time, error)
+	// apply menjalankan sql dan mencatat (up) atau menghapus (down) versinya dalam satu transaksi.
+	apply(ctx context.Context, m Migration, up bool) error
+}
+
+// Migrator menerapkan migrasi yang di-embed dan mencatatnya di tabel schema_migrations.
+type Migrator struct {
+	driver     migrationDriver
+	migrations []Migration
+}
+
+// Migratable diimplementasikan oleh store yang skemanya dikelola oleh Migrator.
+type Migratable interface {
+	Migrator() (*Migrator, error)
+}
+
+// Up menerapkan semua migrasi yang belum dijalankan dan mengembalikan jumlahnya.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	unlock, err := m.driver.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.prepare(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		log.Printf("Applying migration %d_%s", migration.Version, migration.Name)
+		if err := m.driver.apply(ctx, migration, true); err != nil {
+			return count, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down membatalkan sejumlah steps migrasi terakhir yang sudah diterapkan.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	unlock, err := m.driver.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.prepare(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == "" {
+			return count, fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+		}
+		log.Printf("Reverting migration %d_%s", migration.Version, migration.Name)
+		if err := m.driver.apply(ctx, migration, false); err != nil {
+			return count, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Status mengembalikan semua migrasi yang dikenal beserta waktu penerapannya.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) prepare(ctx context.Context) (map[int64]time.Time, error) {
+	if err := m.driver.ensureVersionTable(ctx); err != nil {
+		return nil, err
+	}
+	return m.driver.appliedMigrations(ctx)
+}
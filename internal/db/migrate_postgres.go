@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tajri15/go-pulse-monitoring/db/migration"
+)
+
+// Kunci advisory lock yang dipakai bersama oleh semua instance saat migrasi
+const migrationLockKey int64 = 0x676f70756c7365 // "gopulse"
+
+// Migrator mengembalikan Migrator untuk migrasi PostgreSQL yang di-embed.
+func (s *SQLStore) Migrator() (*Migrator, error) {
+	migrations, err := LoadMigrations(migration.Postgres, "postgres")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{driver: &postgresMigrationDriver{pool: s.pool}, migrations: migrations}, nil
+}
+
+// postgresMigrationDriver menjalankan semua langkah migrasi pada satu koneksi yang memegang advisory lock.
+type postgresMigrationDriver struct {
+	pool *pgxpool.Pool
+	conn *pgxpool.Conn
+}
+
+func (d *postgresMigrationDriver) lock(ctx context.Context) (func(), error) {
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Menunggu sampai instance lain selesai migrasi
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	d.conn = conn
+
+	return func() {
+		conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+		conn.Release()
+		d.conn = nil
+	}, nil
+}
+
+// querier mengembalikan koneksi yang memegang lock, atau pool jika lock tidak diambil (misalnya untuk status).
+func (d *postgresMigrationDriver) querier() interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+} {
+	if d.conn != nil {
+		return d.conn
+	}
+	return d.pool
+}
+
+func (d *postgresMigrationDriver) ensureVersionTable(ctx context.Context) error {
+	tx, err := d.querier().Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `CREATE TABLE schema_migrations (
+		version bigint PRIMARY KEY,
+		name varchar NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT (now())
+	)`)
+	if err != nil {
+		return err
+	}
+
+	// Database lama dibuat lewat docker-entrypoint-initdb.d tanpa tabel schema_migrations.
+	// Tandai migrasi yang skemanya sudah ada agar tidak dijalankan ulang.
+	var hasUsers, isPartitioned bool
+	err = tx.QueryRow(ctx, `SELECT to_regclass('users') IS NOT NULL,
+		EXISTS (SELECT 1 FROM pg_partitioned_table WHERE partrelid = to_regclass('health_checks'))`,
+	).Scan(&hasUsers, &isPartitioned)
+	if err != nil {
+		return err
+	}
+	if hasUsers {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES (1, 'init_schema')`); err != nil {
+			return err
+		}
+	}
+	if isPartitioned {
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES (2, 'partition_health_checks')`); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (d *postgresMigrationDriver) appliedMigrations(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.querier().Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func (d *postgresMigrationDriver) apply(ctx context.Context, m Migration, up bool) error {
+	if d.conn == nil {
+		return errors.New("migration lock is not held")
+	}
+
+	tx, err := d.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if up {
+		if _, err := tx.Exec(ctx, m.Up); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tx.Exec(ctx, m.Down); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
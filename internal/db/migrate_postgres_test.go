@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tajri15/go-pulse-monitoring/db/migration"
+)
+
+// newTestPostgresPool membuka pool ke TEST_DATABASE_URL dengan search_path pada schema baru yang dihapus
+// setelah test selesai. Test dilewati jika TEST_DATABASE_URL tidak diset.
+func newTestPostgresPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+
+	admin, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	schema := pgx.Identifier{fmt.Sprintf("pulse_test_%d", time.Now().UnixNano())}.Sanitize()
+	if _, err := admin.Exec(ctx, `CREATE SCHEMA `+schema); err != nil {
+		admin.Close(ctx)
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec(context.Background(), `DROP SCHEMA `+schema+` CASCADE`)
+		admin.Close(context.Background())
+	})
+
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+// newMigratedPostgresStore membuka SQLStore di schema baru dengan semua migrasi diterapkan.
+func newMigratedPostgresStore(t *testing.T) *SQLStore {
+	t.Helper()
+	store := NewStore(newTestPostgresPool(t)).(*SQLStore)
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return store
+}
+
+func TestPostgresMigrateUp(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(newTestPostgresPool(t)).(*SQLStore)
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if n != len(migrator.migrations) {
+		t.Errorf("Up applied %d migrations, want %d", n, len(migrator.migrations))
+	}
+	requireApplied(t, migrator, migrationVersions(migrator.migrations)...)
+	if n, err := migrator.Up(ctx); err != nil || n != 0 {
+		t.Errorf("second Up = %d, %v, want 0", n, err)
+	}
+}
+
+func TestPostgresMigrateBackfillsLegacySchema(t *testing.T) {
+	ctx := context.Background()
+	pool := newTestPostgresPool(t)
+	migrations, err := LoadMigrations(migration.Postgres, "postgres")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Database lama dibuat lewat docker-entrypoint-initdb.d: skema awal dan partisi tanpa schema_migrations
+	for _, m := range migrations[:2] {
+		if _, err := pool.Exec(ctx, m.Up); err != nil {
+			t.Fatalf("migration %d: %v", m.Version, err)
+		}
+	}
+
+	migrator, err := NewStore(pool).(*SQLStore).Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if n != len(migrations)-2 {
+		t.Errorf("Up applied %d migrations, want %d", n, len(migrations)-2)
+	}
+	requireApplied(t, migrator, migrationVersions(migrations)...)
+}
+
+func TestPostgresFailedMigrationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedPostgresStore(t)
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := migrationVersions(migrator.migrations)
+
+	migrator.migrations = append(migrator.migrations, Migration{
+		Version: 9999,
+		Name:    "broken",
+		Up:      `CREATE TABLE "half_done" ("id" int); SELECT * FROM "missing_table";`,
+	})
+	if _, err := migrator.Up(ctx); err == nil {
+		t.Fatal("Up succeeded with a broken migration")
+	}
+	requireApplied(t, migrator, versions...)
+
+	var exists bool
+	if err := store.pool.QueryRow(ctx, `SELECT to_regclass('half_done') IS NOT NULL`).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("the broken migration's table was not rolled back")
+	}
+}
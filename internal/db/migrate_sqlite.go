@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/db/migration"
+)
+
+// Migrator mengembalikan Migrator untuk migrasi SQLite yang di-embed.
+func (s *SQLiteStore) Migrator() (*Migrator, error) {
+	migrations, err := LoadMigrations(migration.SQLite, "sqlite")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{driver: &sqliteMigrationDriver{db: s.db}, migrations: migrations}, nil
+}
+
+// sqliteMigrationDriver menjalankan migrasi pada satu koneksi. SQLite tidak punya advisory lock,
+// jadi setiap migrasi dijalankan dalam BEGIN IMMEDIATE yang mengunci database untuk penulis lain.
+type sqliteMigrationDriver struct {
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+func (d *sqliteMigrationDriver) lock(ctx context.Context) (func(), error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.conn = conn
+
+	return func() {
+		conn.Close()
+		d.conn = nil
+	}, nil
+}
+
+func (d *sqliteMigrationDriver) ensureVersionTable(ctx context.Context) error {
+	var exists bool
+	err := d.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')`).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+
+	// Versi sebelumnya mencatat migrasi di PRAGMA user_version; tandai migrasi tersebut sebagai sudah diterapkan.
+	var userVersion int
+	if err := tx.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&userVersion); err != nil {
+		return err
+	}
+	if userVersion >= 1 {
+		_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO schema_migrations (version, name, applied_at) VALUES (1, 'init_schema', ?)`, sqliteTime(time.Now()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (d *sqliteMigrationDriver) appliedMigrations(ctx context.Context) (map[int64]time.Time, error) {
+	rows, err := d.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]time.Time{}
+	for rows.Next() {
+		var version int64
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		if applied[version], err = parseSQLiteTime(appliedAt); err != nil {
+			return nil, err
+		}
+	}
+	return applied, rows.Err()
+}
+
+func (d *sqliteMigrationDriver) apply(ctx context.Context, m Migration, up bool) (err error) {
+	if d.conn == nil {
+		return errors.New("migration lock is not held")
+	}
+
+	if _, err := d.conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			d.conn.ExecContext(context.Background(), `ROLLBACK`)
+		}
+	}()
+
+	// Catat versi lebih dulu: jika instance lain sudah menerapkannya, primary key akan menolak dan transaksi dibatalkan
+	if up {
+		_, err = d.conn.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`, m.Version, m.Name, sqliteTime(time.Now()))
+		if err != nil {
+			return err
+		}
+		if _, err = d.conn.ExecContext(ctx, m.Up); err != nil {
+			return err
+		}
+	} else {
+		if _, err = d.conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+			return err
+		}
+		if _, err = d.conn.ExecContext(ctx, m.Down); err != nil {
+			return err
+		}
+	}
+
+	_, err = d.conn.ExecContext(ctx, `COMMIT`)
+	return err
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/tajri15/go-pulse-monitoring/db/migration"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/10_later.up.sql":   {Data: []byte("SELECT 10")},
+		"m/10_later.down.sql": {Data: []byte("SELECT -10")},
+		"m/9_first.up.sql":    {Data: []byte("SELECT 9")},
+	}
+	migrations, err := LoadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 9 || migrations[1].Version != 10 {
+		t.Fatalf("migrations = %+v, want versions 9 and 10 in order", migrations)
+	}
+	if migrations[1].Name != "later" || migrations[1].Down != "SELECT -10" || migrations[0].Down != "" {
+		t.Errorf("migrations = %+v", migrations)
+	}
+
+	fsys["m/11_no_up.down.sql"] = &fstest.MapFile{Data: []byte("SELECT 11")}
+	if _, err := LoadMigrations(fsys, "m"); err == nil {
+		t.Error("LoadMigrations accepted a migration without an up file")
+	}
+}
+
+// requireApplied memastikan tepat versi yang diberikan tercatat sudah diterapkan.
+func requireApplied(t *testing.T, m *Migrator, want ...int64) {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	applied := map[int64]bool{}
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			applied[s.Version] = true
+		}
+	}
+	if len(applied) != len(want) {
+		t.Fatalf("%d migrations applied, want %d", len(applied), len(want))
+	}
+	for _, v := range want {
+		if !applied[v] {
+			t.Errorf("migration %d is not applied", v)
+		}
+	}
+}
+
+func migrationVersions(migrations []Migration) []int64 {
+	versions := make([]int64, len(migrations))
+	for i, m := range migrations {
+		versions[i] = m.Version
+	}
+	return versions
+}
+
+func TestSQLiteMigrateUp(t *testing.T) {
+	ctx := context.Background()
+	store := newTestSQLiteStore(t)
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if n != len(migrator.migrations) {
+		t.Errorf("Up applied %d migrations, want %d", n, len(migrator.migrations))
+	}
+	requireApplied(t, migrator, migrationVersions(migrator.migrations)...)
+
+	// Menjalankan ulang tidak mengubah apa pun
+	if n, err := migrator.Up(ctx); err != nil || n != 0 {
+		t.Errorf("second Up = %d, %v, want 0", n, err)
+	}
+}
+
+func TestSQLiteMigrateDown(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedSQLiteStore(t)
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := migrationVersions(migrator.migrations)
+
+	if n, err := migrator.Down(ctx, 1); err != nil || n != 1 {
+		t.Fatalf("Down = %d, %v, want 1", n, err)
+	}
+	requireApplied(t, migrator, versions[:len(versions)-1]...)
+	if n, err := migrator.Up(ctx); err != nil || n != 1 {
+		t.Fatalf("Up after Down = %d, %v, want 1", n, err)
+	}
+	requireApplied(t, migrator, versions...)
+}
+
+func TestSQLiteMigrateBackfillsUserVersion(t *testing.T) {
+	ctx := context.Background()
+	store := newTestSQLiteStore(t)
+	migrations, err := LoadMigrations(migration.SQLite, "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Database dari versi lama: skema awal sudah ada dan dicatat di PRAGMA user_version, tanpa schema_migrations
+	if _, err := store.db.ExecContext(ctx, migrations[0].Up); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.ExecContext(ctx, `PRAGMA user_version = 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if n != len(migrations)-1 {
+		t.Errorf("Up applied %d migrations, want %d", n, len(migrations)-1)
+	}
+	requireApplied(t, migrator, migrationVersions(migrations)...)
+}
+
+func TestSQLiteFailedMigrationRollsBack(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedSQLiteStore(t)
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := migrationVersions(migrator.migrations)
+
+	migrator.migrations = append(migrator.migrations, Migration{
+		Version: 9999,
+		Name:    "broken",
+		Up:      `CREATE TABLE "half_done" ("id" INTEGER); SELECT * FROM "missing_table";`,
+	})
+	if _, err := migrator.Up(ctx); err == nil {
+		t.Fatal("Up succeeded with a broken migration")
+	}
+	requireApplied(t, migrator, versions...)
+
+	var exists bool
+	err = store.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE name = 'half_done')`).Scan(&exists)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("the broken migration's table was not rolled back")
+	}
+}
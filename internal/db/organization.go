@@ -0,0 +1,988 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Role anggota organisasi, dari yang paling terbatas:
+// viewer hanya dapat membaca, editor dapat mengubah site dan grup, admin dapat mengelola anggota dan tim,
+// owner dapat melakukan semuanya termasuk menghapus organisasi.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+	RoleOwner  = "owner"
+)
+
+var roleRanks = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleAdmin: 3, RoleOwner: 4}
+
+// ValidRole bernilai true jika role dikenal.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAtLeast bernilai true jika role sama dengan atau lebih tinggi dari required.
+func RoleAtLeast(role, required string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+// ErrOrganizationHasSites dikembalikan DeleteOrganization jika organisasi masih memiliki site, termasuk yang diarsipkan.
+var ErrOrganizationHasSites = conflictError("", "organization still has sites")
+
+// Organization memiliki site dan grup site; user mengaksesnya sesuai role keanggotaannya.
+type Organization struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserOrganization adalah organisasi beserta role user di dalamnya.
+type UserOrganization struct {
+	Organization
+	Role string `json:"role"`
+}
+
+type OrganizationMember struct {
+	OrganizationID int64     `json:"organization_id"`
+	UserID         int64     `json:"user_id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type AddOrganizationMemberParams struct {
+	OrganizationID int64
+	UserID         int64
+	Role           string
+}
+
+// Team adalah kumpulan anggota organisasi bernama, misalnya "on-call" atau "frontend".
+type Team struct {
+	ID             int64     `json:"id"`
+	OrganizationID int64     `json:"organization_id"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"created_at"`
+	// UserIDs hanya berisi anggota organisasi, diurutkan menaik
+	UserIDs []int64 `json:"user_ids"`
+}
+
+type CreateTeamParams struct {
+	OrganizationID int64
+	Name           string
+	UserIDs        []int64
+}
+
+// UpdateTeamParams berisi field yang ingin diubah; field bernilai nil tidak diubah.
+type UpdateTeamParams struct {
+	Name    *string
+	UserIDs *[]int64
+}
+
+const organizationColumns = `id, name, created_at`
+
+func scanOrganization(row interface{ Scan(...any) error }) (Organization, error) {
+	var o Organization
+	err := row.Scan(&o.ID, &o.Name, &o.CreatedAt)
+	return o, err
+}
+
+// Kolom anggota dari organization_members m JOIN users u, urutannya sesuai dengan scanOrganizationMember
+const organizationMemberColumns = `m.organization_id, m.user_id, u.username, u.email, m.role, m.created_at`
+
+func scanOrganizationMember(row interface{ Scan(...any) error }) (OrganizationMember, error) {
+	var m OrganizationMember
+	err := row.Scan(&m.OrganizationID, &m.UserID, &m.Username, &m.Email, &m.Role, &m.CreatedAt)
+	return m, err
+}
+
+// Kolom tim yang dipilih oleh semua query, urutannya sesuai dengan scanTeam
+const teamColumns = `id, organization_id, name, created_at,
+    COALESCE((SELECT array_agg(tm.user_id ORDER BY tm.user_id) FROM team_members tm WHERE tm.team_id = teams.id), '{}')`
+
+func scanTeam(row pgx.Row) (Team, error) {
+	var t Team
+	err := row.Scan(&t.ID, &t.OrganizationID, &t.Name, &t.CreatedAt, &t.UserIDs)
+	return t, err
+}
+
+// CreateOrganization membuat organisasi dengan ownerID sebagai owner pertamanya.
+func (s *SQLStore) CreateOrganization(ctx context.Context, name string, ownerID int64) (Organization, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return Organization{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	o, err := scanOrganization(tx.QueryRow(ctx, `INSERT INTO organizations (name) VALUES ($1) RETURNING `+organizationColumns, name))
+	if err != nil {
+		return Organization{}, err
+	}
+	_, err = tx.Exec(ctx, `INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3)`, o.ID, ownerID, RoleOwner)
+	if err != nil {
+		return Organization{}, err
+	}
+	return o, tx.Commit(ctx)
+}
+
+func (s *SQLStore) GetOrganization(ctx context.Context, orgID int64) (Organization, error) {
+	return scanOrganization(s.conn.QueryRow(ctx, `SELECT `+organizationColumns+` FROM organizations WHERE id = $1`, orgID))
+}
+
+// ListUserOrganizations mengembalikan organisasi tempat user menjadi anggota, diurutkan dari yang paling lama
+// diikuti, sehingga organisasi pribadi user berada di urutan pertama.
+func (s *SQLStore) ListUserOrganizations(ctx context.Context, userID int64) ([]UserOrganization, error) {
+	query := `SELECT o.id, o.name, o.created_at, m.role FROM organizations o
+              JOIN organization_members m ON m.organization_id = o.id
+              WHERE m.user_id = $1 ORDER BY m.created_at, o.id`
+
+	rows, err := s.conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []UserOrganization{}
+	for rows.Next() {
+		var o UserOrganization
+		if err := rows.Scan(&o.ID, &o.Name, &o.CreatedAt, &o.Role); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, o)
+	}
+	return orgs, rows.Err()
+}
+
+func (s *SQLStore) UpdateOrganization(ctx context.Context, orgID int64, name string) (Organization, error) {
+	query := `UPDATE organizations SET name = $2 WHERE id = $1 RETURNING ` + organizationColumns
+	return scanOrganization(s.conn.QueryRow(ctx, query, orgID, name))
+}
+
+// DeleteOrganization menghapus organisasi beserta grup, tim dan keanggotaannya. Organisasi yang masih
+// memiliki site tidak dapat dihapus (ErrOrganizationHasSites).
+func (s *SQLStore) DeleteOrganization(ctx context.Context, orgID int64) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Kunci baris organisasi agar tidak ada site baru yang dibuat selama penghapusan
+	var id int64
+	if err := tx.QueryRow(ctx, `SELECT id FROM organizations WHERE id = $1 FOR UPDATE`, orgID).Scan(&id); err != nil {
+		return err
+	}
+	var hasSites bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM sites WHERE organization_id = $1)`, orgID).Scan(&hasSites); err != nil {
+		return err
+	}
+	if hasSites {
+		return ErrOrganizationHasSites
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM site_groups WHERE organization_id = $1`, orgID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM organizations WHERE id = $1`, orgID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (s *SQLStore) GetOrganizationMember(ctx context.Context, orgID int64, userID int64) (OrganizationMember, error) {
+	query := `SELECT ` + organizationMemberColumns + ` FROM organization_members m JOIN users u ON u.id = m.user_id
+              WHERE m.organization_id = $1 AND m.user_id = $2`
+
+	return scanOrganizationMember(s.conn.QueryRow(ctx, query, orgID, userID))
+}
+
+func (s *SQLStore) ListOrganizationMembers(ctx context.Context, orgID int64) ([]OrganizationMember, error) {
+	query := `SELECT ` + organizationMemberColumns + ` FROM organization_members m JOIN users u ON u.id = m.user_id
+              WHERE m.organization_id = $1 ORDER BY m.created_at, m.user_id`
+
+	rows, err := s.conn.Query(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []OrganizationMember{}
+	for rows.Next() {
+		m, err := scanOrganizationMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (s *SQLStore) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error) {
+	query := `WITH m AS (
+                INSERT INTO organization_members (organization_id, user_id, role) VALUES ($1, $2, $3) RETURNING *
+              )
+              SELECT ` + organizationMemberColumns + ` FROM m JOIN users u ON u.id = m.user_id`
+
+	return scanOrganizationMember(s.conn.QueryRow(ctx, query, arg.OrganizationID, arg.UserID, arg.Role))
+}
+
+func (s *SQLStore) UpdateOrganizationMemberRole(ctx context.Context, orgID int64, userID int64, role string) (OrganizationMember, error) {
+	query := `WITH m AS (
+                UPDATE organization_members SET role = $3 WHERE organization_id = $1 AND user_id = $2 RETURNING *
+              )
+              SELECT ` + organizationMemberColumns + ` FROM m JOIN users u ON u.id = m.user_id`
+
+	return scanOrganizationMember(s.conn.QueryRow(ctx, query, orgID, userID, role))
+}
+
+// RemoveOrganizationMember mengeluarkan user dari organisasi dan dari semua tim di organisasi tersebut.
+func (s *SQLStore) RemoveOrganizationMember(ctx context.Context, orgID int64, userID int64) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `DELETE FROM team_members WHERE user_id = $2 AND team_id IN (SELECT id FROM teams WHERE organization_id = $1)`, orgID, userID)
+	if err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// setTeamMembers mengganti anggota tim. User yang bukan anggota organisasi diabaikan.
+func setTeamMembers(ctx context.Context, tx pgx.Tx, teamID int64, orgID int64, userIDs []int64) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM team_members WHERE team_id = $1`, teamID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `INSERT INTO team_members (team_id, user_id)
+                            SELECT $1, user_id FROM organization_members WHERE user_id = ANY($2::bigint[]) AND organization_id = $3`, teamID, userIDs, orgID)
+	return err
+}
+
+func (s *SQLStore) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return Team{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var teamID int64
+	err = tx.QueryRow(ctx, `INSERT INTO teams (organization_id, name) VALUES ($1, $2) RETURNING id`, arg.OrganizationID, arg.Name).Scan(&teamID)
+	if err != nil {
+		return Team{}, err
+	}
+	if err := setTeamMembers(ctx, tx, teamID, arg.OrganizationID, arg.UserIDs); err != nil {
+		return Team{}, err
+	}
+
+	t, err := scanTeam(tx.QueryRow(ctx, `SELECT `+teamColumns+` FROM teams WHERE id = $1`, teamID))
+	if err != nil {
+		return Team{}, err
+	}
+	return t, tx.Commit(ctx)
+}
+
+func (s *SQLStore) GetTeam(ctx context.Context, teamID int64, orgID int64) (Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = $1 AND organization_id = $2`
+	return scanTeam(s.conn.QueryRow(ctx, query, teamID, orgID))
+}
+
+func (s *SQLStore) ListTeams(ctx context.Context, orgID int64) ([]Team, error) {
+	rows, err := s.conn.Query(ctx, `SELECT `+teamColumns+` FROM teams WHERE organization_id = $1 ORDER BY name, id`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []Team{}
+	for rows.Next() {
+		t, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+func (s *SQLStore) UpdateTeam(ctx context.Context, teamID int64, orgID int64, arg UpdateTeamParams) (Team, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return Team{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	var id int64
+	err = tx.QueryRow(ctx, `UPDATE teams SET name = COALESCE($3, name) WHERE id = $1 AND organization_id = $2 RETURNING id`,
+		teamID, orgID, arg.Name).Scan(&id)
+	if err != nil {
+		return Team{}, err
+	}
+	if arg.UserIDs != nil {
+		if err := setTeamMembers(ctx, tx, teamID, orgID, *arg.UserIDs); err != nil {
+			return Team{}, err
+		}
+	}
+
+	t, err := scanTeam(tx.QueryRow(ctx, `SELECT `+teamColumns+` FROM teams WHERE id = $1`, teamID))
+	if err != nil {
+		return Team{}, err
+	}
+	return t, tx.Commit(ctx)
+}
+
+func (s *SQLStore) DeleteTeam(ctx context.Context, teamID int64, orgID int64) error {
+	tag, err := s.conn.Exec(ctx, `DELETE FROM teams WHERE id = $1 AND organization_id = $2`, teamID, orgID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// --- SQLite ---
+
+func scanSQLiteOrganization(row sqliteScanner) (Organization, error) {
+	var o Organization
+	var createdAt string
+	if err := row.Scan(&o.ID, &o.Name, &createdAt); err != nil {
+		return Organization{}, err
+	}
+	var err error
+	o.CreatedAt, err = parseSQLiteTime(createdAt)
+	return o, err
+}
+
+func scanSQLiteOrganizationMember(row sqliteScanner) (OrganizationMember, error) {
+	var m OrganizationMember
+	var createdAt string
+	if err := row.Scan(&m.OrganizationID, &m.UserID, &m.Username, &m.Email, &m.Role, &createdAt); err != nil {
+		return OrganizationMember{}, err
+	}
+	var err error
+	m.CreatedAt, err = parseSQLiteTime(createdAt)
+	return m, err
+}
+
+// Kolom tim yang dipilih oleh semua query, urutannya sesuai dengan scanSQLiteTeam
+const sqliteTeamColumns = `id, organization_id, name, created_at,
+    (SELECT json_group_array(user_id) FROM (SELECT user_id FROM team_members WHERE team_id = teams.id ORDER BY user_id))`
+
+func scanSQLiteTeam(row sqliteScanner) (Team, error) {
+	var t Team
+	var createdAt, userIDs string
+	if err := row.Scan(&t.ID, &t.OrganizationID, &t.Name, &createdAt, &userIDs); err != nil {
+		return Team{}, err
+	}
+	var err error
+	if t.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return Team{}, err
+	}
+	err = json.Unmarshal([]byte(userIDs), &t.UserIDs)
+	return t, err
+}
+
+func (s *SQLiteStore) CreateOrganization(ctx context.Context, name string, ownerID int64) (Organization, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return Organization{}, err
+	}
+	defer tx.Rollback()
+
+	o := Organization{Name: name, CreatedAt: time.Now().UTC()}
+	res, err := tx.ExecContext(ctx, `INSERT INTO organizations (name, created_at) VALUES (?, ?)`, o.Name, sqliteTime(o.CreatedAt))
+	if err != nil {
+		return Organization{}, err
+	}
+	if o.ID, err = res.LastInsertId(); err != nil {
+		return Organization{}, err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO organization_members (organization_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		o.ID, ownerID, RoleOwner, sqliteTime(o.CreatedAt))
+	if err != nil {
+		return Organization{}, err
+	}
+	return o, tx.Commit()
+}
+
+func (s *SQLiteStore) GetOrganization(ctx context.Context, orgID int64) (Organization, error) {
+	o, err := scanSQLiteOrganization(s.conn.QueryRowContext(ctx, `SELECT `+organizationColumns+` FROM organizations WHERE id = ?`, orgID))
+	return o, sqliteError(err)
+}
+
+func (s *SQLiteStore) ListUserOrganizations(ctx context.Context, userID int64) ([]UserOrganization, error) {
+	query := `SELECT o.id, o.name, o.created_at, m.role FROM organizations o
+              JOIN organization_members m ON m.organization_id = o.id
+              WHERE m.user_id = ? ORDER BY m.created_at, o.id`
+
+	rows, err := s.conn.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []UserOrganization{}
+	for rows.Next() {
+		var o UserOrganization
+		var createdAt string
+		if err := rows.Scan(&o.ID, &o.Name, &createdAt, &o.Role); err != nil {
+			return nil, err
+		}
+		if o.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, o)
+	}
+	return orgs, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateOrganization(ctx context.Context, orgID int64, name string) (Organization, error) {
+	query := `UPDATE organizations SET name = ? WHERE id = ? RETURNING ` + organizationColumns
+
+	o, err := scanSQLiteOrganization(s.conn.QueryRowContext(ctx, query, name, orgID))
+	return o, sqliteError(err)
+}
+
+func (s *SQLiteStore) DeleteOrganization(ctx context.Context, orgID int64) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var hasSites bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sites WHERE organization_id = ?)`, orgID).Scan(&hasSites); err != nil {
+		return err
+	}
+	if hasSites {
+		return ErrOrganizationHasSites
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM site_groups WHERE organization_id = ?`, orgID); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM organizations WHERE id = ?`, orgID)
+	if err != nil {
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRecordNotFound
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetOrganizationMember(ctx context.Context, orgID int64, userID int64) (OrganizationMember, error) {
+	query := `SELECT ` + organizationMemberColumns + ` FROM organization_members m JOIN users u ON u.id = m.user_id
+              WHERE m.organization_id = ? AND m.user_id = ?`
+
+	m, err := scanSQLiteOrganizationMember(s.conn.QueryRowContext(ctx, query, orgID, userID))
+	return m, sqliteError(err)
+}
+
+func (s *SQLiteStore) ListOrganizationMembers(ctx context.Context, orgID int64) ([]OrganizationMember, error) {
+	query := `SELECT ` + organizationMemberColumns + ` FROM organization_members m JOIN users u ON u.id = m.user_id
+              WHERE m.organization_id = ? ORDER BY m.created_at, m.user_id`
+
+	rows, err := s.conn.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []OrganizationMember{}
+	for rows.Next() {
+		m, err := scanSQLiteOrganizationMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (s *SQLiteStore) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error) {
+	_, err := s.conn.ExecContext(ctx, `INSERT INTO organization_members (organization_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		arg.OrganizationID, arg.UserID, arg.Role, sqliteTime(time.Now()))
+	if err != nil {
+		return OrganizationMember{}, sqliteError(err)
+	}
+	return s.GetOrganizationMember(ctx, arg.OrganizationID, arg.UserID)
+}
+
+func (s *SQLiteStore) UpdateOrganizationMemberRole(ctx context.Context, orgID int64, userID int64, role string) (OrganizationMember, error) {
+	res, err := s.conn.ExecContext(ctx, `UPDATE organization_members SET role = ? WHERE organization_id = ? AND user_id = ?`, role, orgID, userID)
+	if err != nil {
+		return OrganizationMember{}, err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return OrganizationMember{}, err
+	}
+	if updated == 0 {
+		return OrganizationMember{}, ErrRecordNotFound
+	}
+	return s.GetOrganizationMember(ctx, orgID, userID)
+}
+
+func (s *SQLiteStore) RemoveOrganizationMember(ctx context.Context, orgID int64, userID int64) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM team_members WHERE user_id = ? AND team_id IN (SELECT id FROM teams WHERE organization_id = ?)`, userID, orgID)
+	if err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM organization_members WHERE organization_id = ? AND user_id = ?`, orgID, userID)
+	if err != nil {
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRecordNotFound
+	}
+	return tx.Commit()
+}
+
+// setSQLiteTeamMembers mengganti anggota tim. User yang bukan anggota organisasi diabaikan.
+func setSQLiteTeamMembers(ctx context.Context, tx sqliteDBTX, teamID int64, orgID int64, userIDs []int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM team_members WHERE team_id = ?`, teamID); err != nil {
+		return err
+	}
+	ids, err := json.Marshal(userIDs)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO team_members (team_id, user_id)
+                                  SELECT ?, user_id FROM organization_members WHERE user_id IN (SELECT value FROM json_each(?)) AND organization_id = ?`,
+		teamID, string(ids), orgID)
+	return err
+}
+
+func (s *SQLiteStore) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return Team{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO teams (organization_id, name, created_at) VALUES (?, ?, ?)`,
+		arg.OrganizationID, arg.Name, sqliteTime(time.Now()))
+	if err != nil {
+		return Team{}, sqliteError(err)
+	}
+	teamID, err := res.LastInsertId()
+	if err != nil {
+		return Team{}, err
+	}
+	if err := setSQLiteTeamMembers(ctx, tx, teamID, arg.OrganizationID, arg.UserIDs); err != nil {
+		return Team{}, err
+	}
+
+	t, err := scanSQLiteTeam(tx.QueryRowContext(ctx, `SELECT `+sqliteTeamColumns+` FROM teams WHERE id = ?`, teamID))
+	if err != nil {
+		return Team{}, err
+	}
+	return t, tx.Commit()
+}
+
+func (s *SQLiteStore) GetTeam(ctx context.Context, teamID int64, orgID int64) (Team, error) {
+	query := `SELECT ` + sqliteTeamColumns + ` FROM teams WHERE id = ? AND organization_id = ?`
+
+	t, err := scanSQLiteTeam(s.conn.QueryRowContext(ctx, query, teamID, orgID))
+	return t, sqliteError(err)
+}
+
+func (s *SQLiteStore) ListTeams(ctx context.Context, orgID int64) ([]Team, error) {
+	rows, err := s.conn.QueryContext(ctx, `SELECT `+sqliteTeamColumns+` FROM teams WHERE organization_id = ? ORDER BY name, id`, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []Team{}
+	for rows.Next() {
+		t, err := scanSQLiteTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateTeam(ctx context.Context, teamID int64, orgID int64, arg UpdateTeamParams) (Team, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return Team{}, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `UPDATE teams SET name = COALESCE(?, name) WHERE id = ? AND organization_id = ? RETURNING id`,
+		arg.Name, teamID, orgID).Scan(&id)
+	if err != nil {
+		return Team{}, sqliteError(err)
+	}
+	if arg.UserIDs != nil {
+		if err := setSQLiteTeamMembers(ctx, tx, teamID, orgID, *arg.UserIDs); err != nil {
+			return Team{}, err
+		}
+	}
+
+	t, err := scanSQLiteTeam(tx.QueryRowContext(ctx, `SELECT `+sqliteTeamColumns+` FROM teams WHERE id = ?`, teamID))
+	if err != nil {
+		return Team{}, err
+	}
+	return t, tx.Commit()
+}
+
+func (s *SQLiteStore) DeleteTeam(ctx context.Context, teamID int64, orgID int64) error {
+	res, err := s.conn.ExecContext(ctx, `DELETE FROM teams WHERE id = ? AND organization_id = ?`, teamID, orgID)
+	if err != nil {
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// --- Memory ---
+
+type organizationMemberKey struct {
+	organizationID int64
+	userID         int64
+}
+
+// organizationMember melengkapi data keanggotaan dengan username dan email user. Pemanggil harus memegang s.mu.
+func (s *MemoryStore) organizationMember(key organizationMemberKey) (OrganizationMember, bool) {
+	m, ok := s.organizationMembers[key]
+	if !ok {
+		return OrganizationMember{}, false
+	}
+	u := s.users[key.userID]
+	m.Username, m.Email = u.Username, u.Email
+	return m, true
+}
+
+func (s *MemoryStore) CreateOrganization(ctx context.Context, name string, ownerID int64) (Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[ownerID]; !ok {
+		return Organization{}, errReferenceNotFound
+	}
+
+	s.nextOrganizationID++
+	o := Organization{ID: s.nextOrganizationID, Name: name, CreatedAt: time.Now()}
+	setEntry(s, s.organizations, o.ID, o)
+	setEntry(s, s.organizationMembers, organizationMemberKey{o.ID, ownerID}, OrganizationMember{
+		OrganizationID: o.ID,
+		UserID:         ownerID,
+		Role:           RoleOwner,
+		CreatedAt:      o.CreatedAt,
+	})
+	return o, nil
+}
+
+func (s *MemoryStore) GetOrganization(ctx context.Context, orgID int64) (Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.organizations[orgID]
+	if !ok {
+		return Organization{}, ErrRecordNotFound
+	}
+	return o, nil
+}
+
+func (s *MemoryStore) ListUserOrganizations(ctx context.Context, userID int64) ([]UserOrganization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orgs := []UserOrganization{}
+	joinedAt := map[int64]time.Time{}
+	for key, m := range s.organizationMembers {
+		if key.userID == userID {
+			orgs = append(orgs, UserOrganization{Organization: s.organizations[key.organizationID], Role: m.Role})
+			joinedAt[key.organizationID] = m.CreatedAt
+		}
+	}
+	sort.Slice(orgs, func(i, j int) bool {
+		a, b := joinedAt[orgs[i].ID], joinedAt[orgs[j].ID]
+		if !a.Equal(b) {
+			return a.Before(b)
+		}
+		return orgs[i].ID < orgs[j].ID
+	})
+	return orgs, nil
+}
+
+func (s *MemoryStore) UpdateOrganization(ctx context.Context, orgID int64, name string) (Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.organizations[orgID]
+	if !ok {
+		return Organization{}, ErrRecordNotFound
+	}
+	o.Name = name
+	setEntry(s, s.organizations, orgID, o)
+	return o, nil
+}
+
+func (s *MemoryStore) DeleteOrganization(ctx context.Context, orgID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.organizations[orgID]; !ok {
+		return ErrRecordNotFound
+	}
+	for _, site := range s.sites {
+		if site.OrganizationID == orgID {
+			return ErrOrganizationHasSites
+		}
+	}
+	for id, g := range s.groups {
+		if g.OrganizationID == orgID {
+			deleteEntry(s, s.groups, id)
+		}
+	}
+	for id, t := range s.teams {
+		if t.OrganizationID == orgID {
+			deleteEntry(s, s.teams, id)
+		}
+	}
+	for key := range s.organizationMembers {
+		if key.organizationID == orgID {
+			deleteEntry(s, s.organizationMembers, key)
+		}
+	}
+	deleteEntry(s, s.organizations, orgID)
+	return nil
+}
+
+func (s *MemoryStore) GetOrganizationMember(ctx context.Context, orgID int64, userID int64) (OrganizationMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m, ok := s.organizationMember(organizationMemberKey{orgID, userID})
+	if !ok {
+		return OrganizationMember{}, ErrRecordNotFound
+	}
+	return m, nil
+}
+
+func (s *MemoryStore) ListOrganizationMembers(ctx context.Context, orgID int64) ([]OrganizationMember, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	members := []OrganizationMember{}
+	for key := range s.organizationMembers {
+		if key.organizationID == orgID {
+			m, _ := s.organizationMember(key)
+			members = append(members, m)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].CreatedAt.Equal(members[j].CreatedAt) {
+			return members[i].UserID < members[j].UserID
+		}
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	return members, nil
+}
+
+func (s *MemoryStore) AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.organizations[arg.OrganizationID]; !ok {
+		return OrganizationMember{}, errReferenceNotFound
+	}
+	if _, ok := s.users[arg.UserID]; !ok {
+		return OrganizationMember{}, errReferenceNotFound
+	}
+	key := organizationMemberKey{arg.OrganizationID, arg.UserID}
+	if _, ok := s.organizationMembers[key]; ok {
+		return OrganizationMember{}, uniqueViolation("organization_members")
+	}
+	setEntry(s, s.organizationMembers, key, OrganizationMember{
+		OrganizationID: arg.OrganizationID,
+		UserID:         arg.UserID,
+		Role:           arg.Role,
+		CreatedAt:      time.Now(),
+	})
+	m, _ := s.organizationMember(key)
+	return m, nil
+}
+
+func (s *MemoryStore) UpdateOrganizationMemberRole(ctx context.Context, orgID int64, userID int64, role string) (OrganizationMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := organizationMemberKey{orgID, userID}
+	m, ok := s.organizationMembers[key]
+	if !ok {
+		return OrganizationMember{}, ErrRecordNotFound
+	}
+	m.Role = role
+	setEntry(s, s.organizationMembers, key, m)
+	m, _ = s.organizationMember(key)
+	return m, nil
+}
+
+func (s *MemoryStore) RemoveOrganizationMember(ctx context.Context, orgID int64, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := organizationMemberKey{orgID, userID}
+	if _, ok := s.organizationMembers[key]; !ok {
+		return ErrRecordNotFound
+	}
+	deleteEntry(s, s.organizationMembers, key)
+	for id, t := range s.teams {
+		if t.OrganizationID == orgID {
+			t.UserIDs = slices.DeleteFunc(slices.Clone(t.UserIDs), func(memberID int64) bool { return memberID == userID })
+			setEntry(s, s.teams, id, t)
+		}
+	}
+	return nil
+}
+
+// memberUserIDs menyaring user yang bukan anggota organisasi, lalu mengurutkan dan menghapus duplikat. Pemanggil harus memegang s.mu.
+func (s *MemoryStore) memberUserIDs(orgID int64, userIDs []int64) []int64 {
+	ids := []int64{}
+	for _, id := range userIDs {
+		if _, ok := s.organizationMembers[organizationMemberKey{orgID, id}]; ok {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return slices.Compact(ids)
+}
+
+// teamNameTaken bernilai true jika organisasi sudah memiliki tim lain dengan nama tersebut. Pemanggil harus memegang s.mu.
+func (s *MemoryStore) teamNameTaken(orgID int64, name string, exceptID int64) bool {
+	for _, t := range s.teams {
+		if t.OrganizationID == orgID && t.Name == name && t.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.organizations[arg.OrganizationID]; !ok {
+		return Team{}, errReferenceNotFound
+	}
+	if s.teamNameTaken(arg.OrganizationID, arg.Name, 0) {
+		return Team{}, uniqueViolation("teams")
+	}
+
+	s.nextTeamID++
+	t := Team{
+		ID:             s.nextTeamID,
+		OrganizationID: arg.OrganizationID,
+		Name:           arg.Name,
+		CreatedAt:      time.Now(),
+		UserIDs:        s.memberUserIDs(arg.OrganizationID, arg.UserIDs),
+	}
+	setEntry(s, s.teams, t.ID, t)
+	return t, nil
+}
+
+func (s *MemoryStore) GetTeam(ctx context.Context, teamID int64, orgID int64) (Team, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.teams[teamID]
+	if !ok || t.OrganizationID != orgID {
+		return Team{}, ErrRecordNotFound
+	}
+	return t, nil
+}
+
+func (s *MemoryStore) ListTeams(ctx context.Context, orgID int64) ([]Team, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	teams := []Team{}
+	for _, t := range s.teams {
+		if t.OrganizationID == orgID {
+			teams = append(teams, t)
+		}
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].Name == teams[j].Name {
+			return teams[i].ID < teams[j].ID
+		}
+		return teams[i].Name < teams[j].Name
+	})
+	return teams, nil
+}
+
+func (s *MemoryStore) UpdateTeam(ctx context.Context, teamID int64, orgID int64, arg UpdateTeamParams) (Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamID]
+	if !ok || t.OrganizationID != orgID {
+		return Team{}, ErrRecordNotFound
+	}
+	if arg.Name != nil {
+		if s.teamNameTaken(orgID, *arg.Name, teamID) {
+			return Team{}, uniqueViolation("teams")
+		}
+		t.Name = *arg.Name
+	}
+	if arg.UserIDs != nil {
+		t.UserIDs = s.memberUserIDs(orgID, *arg.UserIDs)
+	}
+	setEntry(s, s.teams, teamID, t)
+	return t, nil
+}
+
+func (s *MemoryStore) DeleteTeam(ctx context.Context, teamID int64, orgID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.teams[teamID]
+	if !ok || t.OrganizationID != orgID {
+		return ErrRecordNotFound
+	}
+	deleteEntry(s, s.teams, teamID)
+	return nil
+}
@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// --- Partisi HealthCheck ---
+
+// Format nama partisi bulanan, contoh: health_checks_y2025m01
+const healthCheckPartitionLayout = "health_checks_y2006m01"
+
+// monthStart mengembalikan awal bulan (UTC) dari waktu yang diberikan.
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// HealthCheckPartitionName mengembalikan nama partisi untuk bulan dari waktu t.
+func HealthCheckPartitionName(t time.Time) string {
+	return monthStart(t).Format(healthCheckPartitionLayout)
+}
+
+// CreateHealthCheckPartition membuat partisi bulanan untuk bulan dari waktu t jika belum ada. Baris bulan
+// tersebut yang terlanjur masuk ke partisi DEFAULT (karena maintenance terlambat) dipindahkan ke partisi baru.
+func (s *SQLStore) CreateHealthCheckPartition(ctx context.Context, t time.Time) error {
+	from := monthStart(t)
+	to := from.AddDate(0, 1, 0)
+	name := pgx.Identifier{HealthCheckPartitionName(from)}.Sanitize()
+
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	// DDL tidak mendukung parameter, jadi nama dan batas partisi disusun langsung.
+	// Keduanya berasal dari time.Time sehingga aman dari injeksi.
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`CREATE TABLE %s (LIKE health_checks INCLUDING DEFAULTS)`, name)); err != nil {
+		return err
+	}
+	moveQuery := fmt.Sprintf(`WITH moved AS (
+                                DELETE FROM health_checks_default WHERE checked_at >= $1 AND checked_at < $2 RETURNING *
+                              )
+                              INSERT INTO %s SELECT * FROM moved`, name)
+	if _, err := tx.Exec(ctx, moveQuery, from, to); err != nil {
+		return err
+	}
+	attachQuery := fmt.Sprintf(`ALTER TABLE health_checks ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`,
+		name, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	if _, err := tx.Exec(ctx, attachQuery); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListHealthCheckPartitions mengembalikan awal bulan dari setiap partisi health_checks yang ada.
+func (s *SQLStore) ListHealthCheckPartitions(ctx context.Context) ([]time.Time, error) {
+	query := `SELECT c.relname FROM pg_inherits i
+              JOIN pg_class c ON c.oid = i.inhrelid
+              WHERE i.inhparent = 'health_checks'::regclass
+              ORDER BY c.relname`
+
+	rows, err := s.conn.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	months := []time.Time{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		// Lewati partisi yang tidak mengikuti format penamaan (misalnya dibuat manual)
+		month, err := time.Parse(healthCheckPartitionLayout, name)
+		if err != nil {
+			continue
+		}
+		months = append(months, month)
+	}
+	return months, rows.Err()
+}
+
+// DropHealthCheckPartition menghapus partisi bulanan untuk bulan dari waktu t beserta seluruh isinya.
+func (s *SQLStore) DropHealthCheckPartition(ctx context.Context, t time.Time) error {
+	query := fmt.Sprintf(`DROP TABLE IF EXISTS %s`, pgx.Identifier{HealthCheckPartitionName(t)}.Sanitize())
+
+	_, err := s.conn.Exec(ctx, query)
+	return err
+}
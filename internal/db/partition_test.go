@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHealthCheckPartitionName(t *testing.T) {
+	tests := map[time.Time]string{
+		time.Date(2025, 1, 31, 23, 0, 0, 0, time.UTC): "health_checks_y2025m01",
+		time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC):  "health_checks_y2025m12",
+		// Waktu lokal dikonversi ke UTC lebih dulu
+		time.Date(2025, 3, 1, 1, 0, 0, 0, time.FixedZone("WIB", 7*60*60)): "health_checks_y2025m02",
+	}
+	for at, want := range tests {
+		if got := HealthCheckPartitionName(at); got != want {
+			t.Errorf("HealthCheckPartitionName(%v) = %q, want %q", at, got, want)
+		}
+	}
+}
+
+// countRows menghitung baris di satu tabel partisi.
+func countRows(t *testing.T, store *SQLStore, table string) int {
+	t.Helper()
+	var n int
+	if err := store.pool.QueryRow(context.Background(), `SELECT count(*) FROM `+table).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestPostgresCreateHealthCheckPartitionIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedPostgresStore(t)
+	month := time.Date(2090, 1, 31, 12, 0, 0, 0, time.UTC)
+
+	for range 2 {
+		if err := store.CreateHealthCheckPartition(ctx, month); err != nil {
+			t.Fatalf("CreateHealthCheckPartition: %v", err)
+		}
+	}
+	partitions, err := store.ListHealthCheckPartitions(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2090, 1, 1, 0, 0, 0, 0, time.UTC)
+	count := 0
+	for _, p := range partitions {
+		if p.Equal(want) {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("partitions = %v, want %v exactly once", partitions, want)
+	}
+
+	if err := store.DropHealthCheckPartition(ctx, month); err != nil {
+		t.Fatalf("DropHealthCheckPartition: %v", err)
+	}
+	if partitions, _ = store.ListHealthCheckPartitions(ctx); slices.ContainsFunc(partitions, want.Equal) {
+		t.Errorf("partition %v still exists after drop", want)
+	}
+}
+
+func TestPostgresDefaultPartitionCatchesUnpartitionedRows(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedPostgresStore(t)
+	user, err := store.CreateUser(ctx, CreateUserParams{Username: "alice", Email: "alice@example.com", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := store.CreateOrganization(ctx, "alice", user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	site, err := store.CreateSite(ctx, CreateSiteParams{OrganizationID: org.ID, UserID: user.ID, URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Belum ada partisi untuk Mei 2090, jadi baris masuk ke partisi DEFAULT
+	checkedAt := time.Date(2090, 5, 10, 0, 0, 0, 0, time.UTC)
+	if _, err := store.CreateHealthChecks(ctx, []HealthCheck{{SiteID: site.ID, StatusCode: 200, IsUp: true, CheckedAt: checkedAt}}); err != nil {
+		t.Fatalf("CreateHealthChecks: %v", err)
+	}
+	if n := countRows(t, store, "health_checks_default"); n != 1 {
+		t.Fatalf("default partition has %d rows, want 1", n)
+	}
+
+	// Partisi baru mengambil alih baris bulannya dari partisi DEFAULT
+	if err := store.CreateHealthCheckPartition(ctx, checkedAt); err != nil {
+		t.Fatalf("CreateHealthCheckPartition: %v", err)
+	}
+	if n := countRows(t, store, "health_checks_default"); n != 0 {
+		t.Errorf("default partition has %d rows after creating the month partition, want 0", n)
+	}
+	if n := countRows(t, store, HealthCheckPartitionName(checkedAt)); n != 1 {
+		t.Errorf("month partition has %d rows, want 1", n)
+	}
+
+	var streamed int
+	err = store.StreamHealthChecks(ctx, StreamHealthChecksParams{OrganizationID: org.ID, From: checkedAt.Add(-time.Hour), To: checkedAt.Add(time.Hour)},
+		func(HealthCheck) error {
+			streamed++
+			return nil
+		})
+	if err != nil || streamed != 1 {
+		t.Errorf("StreamHealthChecks returned %d rows (%v), want 1", streamed, err)
+	}
+}
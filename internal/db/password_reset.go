@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// PasswordResetToken adalah token sekali pakai untuk mengganti password lewat email.
+// Token utuh hanya dikirim ke user; yang disimpan hanya hash SHA-256-nya.
+type PasswordResetToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	TokenHash string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+type CreatePasswordResetTokenParams struct {
+	UserID    int64
+	TokenHash string
+	ExpiresAt time.Time
+}
+
+type ResetPasswordParams struct {
+	TokenHash    string
+	PasswordHash string
+}
+
+const passwordResetTokenColumns = `id, user_id, token_hash, created_at, expires_at, used_at`
+
+func (s *SQLStore) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error) {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, expires_at) VALUES ($1, $2, $3) RETURNING ` + passwordResetTokenColumns
+
+	var t PasswordResetToken
+	err := s.conn.QueryRow(ctx, query, arg.UserID, arg.TokenHash, arg.ExpiresAt).
+		Scan(&t.ID, &t.UserID, &t.TokenHash, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt)
+	return t, err
+}
+
+// ResetPassword memakai token reset yang masih berlaku untuk mengganti password pemiliknya.
+// Semua token reset lain milik user ikut tidak berlaku dan semua session-nya dicabut.
+// ErrRecordNotFound dikembalikan jika token tidak dikenal, sudah dipakai, atau kedaluwarsa.
+func (s *SQLStore) ResetPassword(ctx context.Context, arg ResetPasswordParams) (int64, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int64
+	err = tx.QueryRow(ctx, `UPDATE password_reset_tokens SET used_at = now()
+                            WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now() RETURNING user_id`, arg.TokenHash).Scan(&userID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1`, userID, arg.PasswordHash); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE password_reset_tokens SET used_at = now() WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return 0, err
+	}
+	if _, err := (&SQLStore{conn: tx, pool: s.pool}).RevokeUserSessions(ctx, userID, 0); err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit(ctx)
+}
+
+// --- SQLite ---
+
+func (s *SQLiteStore) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error) {
+	query := `INSERT INTO password_reset_tokens (user_id, token_hash, created_at, expires_at) VALUES (?, ?, ?, ?) RETURNING ` + passwordResetTokenColumns
+
+	var t PasswordResetToken
+	var createdAt, expiresAt string
+	var usedAt sql.NullString
+	err := s.conn.QueryRowContext(ctx, query, arg.UserID, arg.TokenHash, sqliteTime(time.Now()), sqliteTime(arg.ExpiresAt)).
+		Scan(&t.ID, &t.UserID, &t.TokenHash, &createdAt, &expiresAt, &usedAt)
+	if err != nil {
+		return PasswordResetToken{}, sqliteError(err)
+	}
+	if t.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return PasswordResetToken{}, err
+	}
+	if t.ExpiresAt, err = parseSQLiteTime(expiresAt); err != nil {
+		return PasswordResetToken{}, err
+	}
+	t.UsedAt, err = parseNullSQLiteTime(usedAt)
+	return t, err
+}
+
+func (s *SQLiteStore) ResetPassword(ctx context.Context, arg ResetPasswordParams) (int64, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	now := sqliteTime(time.Now())
+	var userID int64
+	err = tx.QueryRowContext(ctx, `UPDATE password_reset_tokens SET used_at = ?
+                                   WHERE token_hash = ? AND used_at IS NULL AND expires_at > ? RETURNING user_id`, now, arg.TokenHash, now).Scan(&userID)
+	if err != nil {
+		return 0, sqliteError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, arg.PasswordHash, userID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE password_reset_tokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`, now, userID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`, now, userID); err != nil {
+		return 0, err
+	}
+	return userID, tx.Commit()
+}
+
+// --- Memory ---
+
+func (s *MemoryStore) CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return PasswordResetToken{}, ErrRecordNotFound
+	}
+	s.nextPasswordResetTokenID++
+	t := PasswordResetToken{
+		ID:        s.nextPasswordResetTokenID,
+		UserID:    arg.UserID,
+		TokenHash: arg.TokenHash,
+		CreatedAt: time.Now(),
+		ExpiresAt: arg.ExpiresAt,
+	}
+	setEntry(s, s.passwordResetTokens, t.ID, t)
+	return t, nil
+}
+
+func (s *MemoryStore) ResetPassword(ctx context.Context, arg ResetPasswordParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var userID int64
+	for _, t := range s.passwordResetTokens {
+		if t.TokenHash == arg.TokenHash && t.UsedAt == nil && now.Before(t.ExpiresAt) {
+			userID = t.UserID
+			break
+		}
+	}
+	u, ok := s.users[userID]
+	if !ok {
+		return 0, ErrRecordNotFound
+	}
+	u.PasswordHash = arg.PasswordHash
+	setEntry(s, s.users, userID, u)
+
+	for id, t := range s.passwordResetTokens {
+		if t.UserID == userID && t.UsedAt == nil {
+			t.UsedAt = &now
+			setEntry(s, s.passwordResetTokens, id, t)
+		}
+	}
+	for id, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			setEntry(s, s.sessions, id, session)
+		}
+	}
+	return userID, nil
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPurgeArchivedSites(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+		"sqlite": func(t *testing.T) Store { return newMigratedSQLiteStore(t) },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newStore(t)
+			user, org := createSQLiteOrganization(t, store, "alice")
+
+			var sites []Site
+			for _, url := range []string{"https://old.example.com", "https://recent.example.com", "https://active.example.com"} {
+				site, err := store.CreateSite(ctx, CreateSiteParams{OrganizationID: org.ID, UserID: user.ID, URL: url, Name: url})
+				if err != nil {
+					t.Fatalf("CreateSite: %v", err)
+				}
+				if _, err := store.CreateHealthCheck(ctx, CreateHealthCheckParams{SiteID: site.ID, StatusCode: 200, ResponseTimeMs: 10, IsUp: true}); err != nil {
+					t.Fatalf("CreateHealthCheck: %v", err)
+				}
+				sites = append(sites, site)
+			}
+			old, recent, active := sites[0], sites[1], sites[2]
+			group, err := store.CreateSiteGroup(ctx, CreateSiteGroupParams{OrganizationID: org.ID, UserID: user.ID, Name: "all", SiteIDs: []int64{old.ID, recent.ID, active.ID}})
+			if err != nil {
+				t.Fatalf("CreateSiteGroup: %v", err)
+			}
+
+			// Hanya site yang diarsipkan sebelum batas waktu yang dihapus
+			if _, err := store.ArchiveSite(ctx, old.ID, org.ID); err != nil {
+				t.Fatalf("ArchiveSite: %v", err)
+			}
+			time.Sleep(time.Millisecond)
+			cutoff := time.Now()
+			time.Sleep(time.Millisecond)
+			if _, err := store.ArchiveSite(ctx, recent.ID, org.ID); err != nil {
+				t.Fatalf("ArchiveSite: %v", err)
+			}
+
+			purged, err := store.PurgeArchivedSites(ctx, cutoff)
+			if err != nil {
+				t.Fatalf("PurgeArchivedSites: %v", err)
+			}
+			if purged != 1 {
+				t.Errorf("purged = %d, want 1", purged)
+			}
+
+			if _, err := store.GetSite(ctx, old.ID); !errors.Is(err, ErrRecordNotFound) {
+				t.Errorf("GetSite of the purged site: err = %v, want ErrRecordNotFound", err)
+			}
+			for _, site := range []Site{recent, active} {
+				if _, err := store.GetSite(ctx, site.ID); err != nil {
+					t.Errorf("GetSite(%d): %v", site.ID, err)
+				}
+			}
+
+			latest, err := store.GetLatestHealthChecks(ctx, []int64{old.ID, recent.ID, active.ID})
+			if err != nil {
+				t.Fatalf("GetLatestHealthChecks: %v", err)
+			}
+			if _, ok := latest[old.ID]; ok || len(latest) != 2 {
+				t.Errorf("health checks left for sites %v, want only %d and %d", latest, recent.ID, active.ID)
+			}
+
+			group, err = store.GetSiteGroup(ctx, group.ID, org.ID)
+			if err != nil {
+				t.Fatalf("GetSiteGroup: %v", err)
+			}
+			// Site yang diarsipkan tidak ditampilkan di group
+			if !slices.Equal(group.SiteIDs, []int64{active.ID}) {
+				t.Errorf("group sites = %v, want [%d]", group.SiteIDs, active.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,392 @@
+package db
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+)
+
+// ErrRefreshTokenReused dikembalikan RotateSession jika refresh token yang sudah dirotasi dipakai lagi.
+// Session pemilik token tersebut langsung dicabut karena token kemungkinan besar sudah dicuri.
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+// Session adalah satu login user. Refresh token tidak disimpan, hanya hash SHA-256-nya.
+type Session struct {
+	ID               int64      `json:"id"`
+	UserID           int64      `json:"user_id"`
+	RefreshTokenHash string     `json:"-"`
+	UserAgent        string     `json:"user_agent"`
+	IPAddress        string     `json:"ip_address"`
+	CreatedAt        time.Time  `json:"created_at"`
+	LastUsedAt       time.Time  `json:"last_used_at"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active bernilai true jika session belum dicabut dan belum kedaluwarsa.
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+type CreateSessionParams struct {
+	UserID           int64
+	RefreshTokenHash string
+	UserAgent        string
+	IPAddress        string
+	ExpiresAt        time.Time
+}
+
+type RotateSessionParams struct {
+	OldTokenHash string
+	NewTokenHash string
+	ExpiresAt    time.Time
+}
+
+const sessionColumns = `id, user_id, refresh_token_hash, user_agent, ip_address, created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(...any) error }) (Session, error) {
+	var s Session
+	err := row.Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &s.UserAgent, &s.IPAddress, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt)
+	return s, err
+}
+
+func (s *SQLStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	query := `INSERT INTO sessions (user_id, refresh_token_hash, user_agent, ip_address, expires_at)
+              VALUES ($1, $2, $3, $4, $5) RETURNING ` + sessionColumns
+
+	return scanSession(s.conn.QueryRow(ctx, query, arg.UserID, arg.RefreshTokenHash, arg.UserAgent, arg.IPAddress, arg.ExpiresAt))
+}
+
+func (s *SQLStore) GetSession(ctx context.Context, sessionID int64) (Session, error) {
+	return scanSession(s.conn.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = $1`, sessionID))
+}
+
+func (s *SQLStore) GetSessionByRefreshToken(ctx context.Context, tokenHash string) (Session, error) {
+	return scanSession(s.conn.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE refresh_token_hash = $1`, tokenHash))
+}
+
+// RotateSession mengganti refresh token session yang aktif. Jika OldTokenHash adalah token yang sudah
+// pernah dirotasi, session dicabut dan ErrRefreshTokenReused dikembalikan bersama ID dan UserID session tersebut.
+func (s *SQLStore) RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error) {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return Session{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE sessions SET refresh_token_hash = $2, last_used_at = now(), expires_at = $3
+              WHERE refresh_token_hash = $1 AND revoked_at IS NULL AND expires_at > now() RETURNING ` + sessionColumns
+	session, err := scanSession(tx.QueryRow(ctx, query, arg.OldTokenHash, arg.NewTokenHash, arg.ExpiresAt))
+	if errors.Is(err, ErrRecordNotFound) {
+		var revoked Session
+		err := tx.QueryRow(ctx, `SELECT s.id, s.user_id FROM used_refresh_tokens u JOIN sessions s ON s.id = u.session_id
+                                 WHERE u.token_hash = $1`, arg.OldTokenHash).Scan(&revoked.ID, &revoked.UserID)
+		if err != nil {
+			return Session{}, err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, revoked.ID); err != nil {
+			return Session{}, err
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return Session{}, err
+		}
+		return revoked, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return Session{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO used_refresh_tokens (token_hash, session_id) VALUES ($1, $2)`, arg.OldTokenHash, session.ID); err != nil {
+		return Session{}, err
+	}
+	return session, tx.Commit(ctx)
+}
+
+// ListSessions mengembalikan session aktif milik user, yang terakhir dipakai lebih dulu.
+func (s *SQLStore) ListSessions(ctx context.Context, userID int64) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+              WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > now() ORDER BY last_used_at DESC, id DESC`
+
+	rows, err := s.conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession mencabut session aktif milik user.
+func (s *SQLStore) RevokeSession(ctx context.Context, sessionID int64, userID int64) error {
+	tag, err := s.conn.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions mencabut semua session aktif milik user kecuali exceptSessionID (0 berarti semua).
+func (s *SQLStore) RevokeUserSessions(ctx context.Context, userID int64, exceptSessionID int64) (int64, error) {
+	tag, err := s.conn.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`, userID, exceptSessionID)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// --- SQLite ---
+
+func scanSQLiteSession(row sqliteScanner) (Session, error) {
+	var s Session
+	var createdAt, lastUsedAt, expiresAt string
+	var revokedAt sql.NullString
+	if err := row.Scan(&s.ID, &s.UserID, &s.RefreshTokenHash, &s.UserAgent, &s.IPAddress, &createdAt, &lastUsedAt, &expiresAt, &revokedAt); err != nil {
+		return Session{}, err
+	}
+	var err error
+	if s.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return Session{}, err
+	}
+	if s.LastUsedAt, err = parseSQLiteTime(lastUsedAt); err != nil {
+		return Session{}, err
+	}
+	if s.ExpiresAt, err = parseSQLiteTime(expiresAt); err != nil {
+		return Session{}, err
+	}
+	s.RevokedAt, err = parseNullSQLiteTime(revokedAt)
+	return s, err
+}
+
+func (s *SQLiteStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	now := sqliteTime(time.Now())
+	query := `INSERT INTO sessions (user_id, refresh_token_hash, user_agent, ip_address, created_at, last_used_at, expires_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING ` + sessionColumns
+
+	session, err := scanSQLiteSession(s.conn.QueryRowContext(ctx, query, arg.UserID, arg.RefreshTokenHash, arg.UserAgent, arg.IPAddress, now, now, sqliteTime(arg.ExpiresAt)))
+	return session, sqliteError(err)
+}
+
+func (s *SQLiteStore) GetSession(ctx context.Context, sessionID int64) (Session, error) {
+	session, err := scanSQLiteSession(s.conn.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE id = ?`, sessionID))
+	return session, sqliteError(err)
+}
+
+func (s *SQLiteStore) GetSessionByRefreshToken(ctx context.Context, tokenHash string) (Session, error) {
+	session, err := scanSQLiteSession(s.conn.QueryRowContext(ctx, `SELECT `+sessionColumns+` FROM sessions WHERE refresh_token_hash = ?`, tokenHash))
+	return session, sqliteError(err)
+}
+
+func (s *SQLiteStore) RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return Session{}, err
+	}
+	defer tx.Rollback()
+
+	now := sqliteTime(time.Now())
+	query := `UPDATE sessions SET refresh_token_hash = ?, last_used_at = ?, expires_at = ?
+              WHERE refresh_token_hash = ? AND revoked_at IS NULL AND expires_at > ? RETURNING ` + sessionColumns
+	session, err := scanSQLiteSession(tx.QueryRowContext(ctx, query, arg.NewTokenHash, now, sqliteTime(arg.ExpiresAt), arg.OldTokenHash, now))
+	if errors.Is(err, sql.ErrNoRows) {
+		var revoked Session
+		err := tx.QueryRowContext(ctx, `SELECT s.id, s.user_id FROM used_refresh_tokens u JOIN sessions s ON s.id = u.session_id
+                                        WHERE u.token_hash = ?`, arg.OldTokenHash).Scan(&revoked.ID, &revoked.UserID)
+		if err != nil {
+			return Session{}, sqliteError(err)
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, now, revoked.ID); err != nil {
+			return Session{}, err
+		}
+		if err := tx.Commit(); err != nil {
+			return Session{}, err
+		}
+		return revoked, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return Session{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO used_refresh_tokens (token_hash, session_id, used_at) VALUES (?, ?, ?)`, arg.OldTokenHash, session.ID, now); err != nil {
+		return Session{}, err
+	}
+	return session, tx.Commit()
+}
+
+func (s *SQLiteStore) ListSessions(ctx context.Context, userID int64) ([]Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions
+              WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY last_used_at DESC, id DESC`
+
+	rows, err := s.conn.QueryContext(ctx, query, userID, sqliteTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		session, err := scanSQLiteSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *SQLiteStore) RevokeSession(ctx context.Context, sessionID int64, userID int64) error {
+	res, err := s.conn.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`, sqliteTime(time.Now()), sessionID, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrRecordNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteStore) RevokeUserSessions(ctx context.Context, userID int64, exceptSessionID int64) (int64, error) {
+	res, err := s.conn.ExecContext(ctx, `UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND id <> ? AND revoked_at IS NULL`, sqliteTime(time.Now()), userID, exceptSessionID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// --- Memory ---
+
+func (s *MemoryStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.nextSessionID++
+	session := Session{
+		ID:               s.nextSessionID,
+		UserID:           arg.UserID,
+		RefreshTokenHash: arg.RefreshTokenHash,
+		UserAgent:        arg.UserAgent,
+		IPAddress:        arg.IPAddress,
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		ExpiresAt:        arg.ExpiresAt,
+	}
+	setEntry(s, s.sessions, session.ID, session)
+	return session, nil
+}
+
+func (s *MemoryStore) GetSession(ctx context.Context, sessionID int64) (Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		return Session{}, ErrRecordNotFound
+	}
+	return session, nil
+}
+
+func (s *MemoryStore) GetSessionByRefreshToken(ctx context.Context, tokenHash string) (Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, session := range s.sessions {
+		if session.RefreshTokenHash == tokenHash {
+			return session, nil
+		}
+	}
+	return Session{}, ErrRecordNotFound
+}
+
+func (s *MemoryStore) RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if sessionID, ok := s.usedRefreshTokens[arg.OldTokenHash]; ok {
+		session := s.sessions[sessionID]
+		if session.RevokedAt == nil {
+			session.RevokedAt = &now
+			setEntry(s, s.sessions, sessionID, session)
+		}
+		return Session{ID: session.ID, UserID: session.UserID}, ErrRefreshTokenReused
+	}
+	for id, session := range s.sessions {
+		if session.RefreshTokenHash != arg.OldTokenHash || !session.Active(now) {
+			continue
+		}
+		session.RefreshTokenHash = arg.NewTokenHash
+		session.LastUsedAt = now
+		session.ExpiresAt = arg.ExpiresAt
+		setEntry(s, s.sessions, id, session)
+		setEntry(s, s.usedRefreshTokens, arg.OldTokenHash, id)
+		return session, nil
+	}
+	return Session{}, ErrRecordNotFound
+}
+
+func (s *MemoryStore) ListSessions(ctx context.Context, userID int64) ([]Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	sessions := []Session{}
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.Active(now) {
+			sessions = append(sessions, session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b Session) int {
+		if c := b.LastUsedAt.Compare(a.LastUsedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	return sessions, nil
+}
+
+func (s *MemoryStore) RevokeSession(ctx context.Context, sessionID int64, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[sessionID]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return ErrRecordNotFound
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	setEntry(s, s.sessions, sessionID, session)
+	return nil
+}
+
+func (s *MemoryStore) RevokeUserSessions(ctx context.Context, userID int64, exceptSessionID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var revoked int64
+	for id, session := range s.sessions {
+		if session.UserID == userID && id != exceptSessionID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+			setEntry(s, s.sessions, id, session)
+			revoked++
+		}
+	}
+	return revoked, nil
+}
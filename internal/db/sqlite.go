@@ -0,0 +1,680 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Format waktu dengan panjang tetap agar kolom teks dapat dibandingkan secara leksikografis
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func parseSQLiteTime(s string) (time.Time, error) {
+	return time.Parse(sqliteTimeLayout, s)
+}
+
+func parseNullSQLiteTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseSQLiteTime(s.String)
+	return &t, err
+}
+
+// SQLiteStore adalah implementasi Store yang menggunakan SQLite, untuk instalasi kecil tanpa PostgreSQL.
+type SQLiteStore struct {
+	db *sql.DB
+	// conn adalah db, atau transaksi jika store dibuat oleh ExecTx
+	conn sqliteDBTX
+	tx   *sql.Tx
+}
+
+// OpenSQLite membuka (atau membuat) database SQLite pada path yang diberikan.
+// Skema dibuat oleh Migrator, lihat SQLiteStore.Migrator.
+func OpenSQLite(ctx context.Context, path string) (*SQLiteStore, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path)
+	conn, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: conn, conn: conn}, nil
+}
+
+// Close menutup koneksi database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// --- User ---
+
+func scanSQLiteUser(row sqliteScanner) (User, error) {
+	var u User
+	var createdAt string
+	var emailVerifiedAt sql.NullString
+	if err := row.Scan(&u.ID, &u.Username, &u.Email, &u.PasswordHash, &createdAt, &emailVerifiedAt); err != nil {
+		return User{}, err
+	}
+	var err error
+	if u.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return User{}, err
+	}
+	u.EmailVerifiedAt, err = parseNullSQLiteTime(emailVerifiedAt)
+	return u, err
+}
+
+func (s *SQLiteStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	u := User{
+		Username:     arg.Username,
+		Email:        arg.Email,
+		PasswordHash: arg.PasswordHash,
+		CreatedAt:    time.Now().UTC(),
+	}
+
+	query := `INSERT INTO users (username, email, password_hash, created_at) VALUES (?, ?, ?, ?)`
+	res, err := s.conn.ExecContext(ctx, query, u.Username, u.Email, u.PasswordHash, sqliteTime(u.CreatedAt))
+	if err != nil {
+		return User{}, sqliteError(err)
+	}
+	u.ID, err = res.LastInsertId()
+	return u, err
+}
+
+func (s *SQLiteStore) GetUser(ctx context.Context, userID int64) (User, error) {
+	u, err := scanSQLiteUser(s.conn.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = ?`, userID))
+	return u, sqliteError(err)
+}
+
+func (s *SQLiteStore) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ? LIMIT 1`
+
+	u, err := scanSQLiteUser(s.conn.QueryRowContext(ctx, query, email))
+	return u, sqliteError(err)
+}
+
+func (s *SQLiteStore) VerifyUserEmail(ctx context.Context, userID int64, email string) (User, error) {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, ?)
+              WHERE id = ? AND email = ? RETURNING ` + userColumns
+
+	u, err := scanSQLiteUser(s.conn.QueryRowContext(ctx, query, sqliteTime(time.Now()), userID, email))
+	return u, sqliteError(err)
+}
+
+// --- Site ---
+
+// Kolom site yang dipilih oleh semua query, urutannya sesuai dengan scanSQLiteSite
+const sqliteSiteColumns = `id, organization_id, user_id, url, name, description, check_type, check_interval_seconds, timeout_seconds, created_at, paused_at, archived_at,
+    (SELECT json_group_array(tag) FROM (SELECT tag FROM site_tags WHERE site_tags.site_id = sites.id ORDER BY tag))`
+
+func (s *SQLiteStore) CreateSite(ctx context.Context, arg CreateSiteParams) (Site, error) {
+	arg = arg.withDefaults()
+	query := `INSERT INTO sites (organization_id, user_id, url, name, description, check_type, check_interval_seconds, timeout_seconds, created_at)
+              VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING ` + sqliteSiteColumns
+
+	site, err := scanSQLiteSite(s.conn.QueryRowContext(ctx, query, arg.OrganizationID, arg.UserID, arg.URL, arg.Name, arg.Description, arg.CheckType,
+		arg.CheckIntervalSeconds, arg.TimeoutSeconds, sqliteTime(time.Now())))
+	return site, sqliteError(err)
+}
+
+func (s *SQLiteStore) UpdateSite(ctx context.Context, siteID int64, orgID int64, arg UpdateSiteParams) (Site, error) {
+	query := `UPDATE sites SET
+                url = COALESCE(?, url),
+                name = COALESCE(?, name),
+                description = COALESCE(?, description),
+                check_interval_seconds = COALESCE(?, check_interval_seconds),
+                timeout_seconds = COALESCE(?, timeout_seconds)
+              WHERE id = ? AND organization_id = ? AND archived_at IS NULL RETURNING ` + sqliteSiteColumns
+
+	site, err := scanSQLiteSite(s.conn.QueryRowContext(ctx, query,
+		arg.URL, arg.Name, arg.Description, arg.CheckIntervalSeconds, arg.TimeoutSeconds, siteID, orgID))
+	return site, sqliteError(err)
+}
+
+func (s *SQLiteStore) PauseSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET paused_at = ? WHERE id = ? AND organization_id = ? AND archived_at IS NULL AND paused_at IS NULL RETURNING ` + sqliteSiteColumns
+
+	site, err := scanSQLiteSite(s.conn.QueryRowContext(ctx, query, sqliteTime(time.Now()), siteID, orgID))
+	return site, sqliteError(err)
+}
+
+func (s *SQLiteStore) ResumeSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET paused_at = NULL WHERE id = ? AND organization_id = ? AND archived_at IS NULL AND paused_at IS NOT NULL RETURNING ` + sqliteSiteColumns
+
+	site, err := scanSQLiteSite(s.conn.QueryRowContext(ctx, query, siteID, orgID))
+	return site, sqliteError(err)
+}
+
+func (s *SQLiteStore) GetSite(ctx context.Context, siteID int64) (Site, error) {
+	query := `SELECT ` + sqliteSiteColumns + ` FROM sites WHERE id = ? LIMIT 1`
+
+	site, err := scanSQLiteSite(s.conn.QueryRowContext(ctx, query, siteID))
+	return site, sqliteError(err)
+}
+
+func (s *SQLiteStore) GetSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error) {
+	query := `SELECT ` + sqliteSiteColumns + ` FROM sites WHERE organization_id = ? AND archived_at IS NULL ORDER BY created_at DESC`
+	return s.querySites(ctx, query, orgID)
+}
+
+func (s *SQLiteStore) CountActiveSitesByUserID(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := s.conn.QueryRowContext(ctx, `SELECT count(*) FROM sites WHERE user_id = ? AND archived_at IS NULL`, userID).Scan(&count)
+	return count, err
+}
+
+// ListSites adalah padanan SQLStore.ListSites untuk SQLite.
+func (s *SQLiteStore) ListSites(ctx context.Context, arg ListSitesParams) (ListSitesResult, error) {
+	arg = arg.withDefaults()
+	cursor, err := decodeSiteCursor(arg)
+	if err != nil {
+		return ListSitesResult{}, err
+	}
+
+	var sortExpr, cursorValue string
+	switch arg.SortBy {
+	case SiteSortName:
+		sortExpr, cursorValue = `lower(COALESCE(NULLIF(sites.name, ''), sites.url))`, `?6`
+	case SiteSortCreatedAt:
+		sortExpr, cursorValue = `sites.created_at`, `?6`
+	case SiteSortResponseTime:
+		sortExpr, cursorValue = `CAST(COALESCE(f.last_response_time_ms, -1) AS REAL)`, `CAST(?6 AS REAL)`
+	case SiteSortUptime:
+		sortExpr, cursorValue = `COALESCE(f.uptime_percent, -1)`, `CAST(?6 AS REAL)`
+	default:
+		return ListSitesResult{}, validationError("sort", fmt.Sprintf("unknown sort %q", arg.SortBy))
+	}
+	statusCond, err := siteStatusCondition(arg.Status, "f.last_is_up = 1", "f.last_is_up = 0")
+	if err != nil {
+		return ListSitesResult{}, err
+	}
+
+	filtered := `WITH filtered AS (
+        SELECT * FROM (
+          SELECT sites.id AS site_id, sites.paused_at AS site_paused_at,
+            lc.is_up AS last_is_up, lc.response_time_ms AS last_response_time_ms, lc.checked_at AS last_checked_at,
+            (SELECT ROUND(AVG(CASE WHEN is_up THEN 100.0 ELSE 0 END), 4) FROM health_checks
+              WHERE site_id = sites.id AND checked_at >= ?2) AS uptime_percent
+          FROM sites
+          -- SQLite tidak mendukung LATERAL, jadi pengecekan terakhir di-join lewat ID-nya
+          LEFT JOIN health_checks lc ON lc.id = (
+            SELECT id FROM health_checks WHERE site_id = sites.id ORDER BY checked_at DESC LIMIT 1)
+          WHERE sites.organization_id = ?1 AND sites.archived_at IS NULL
+            AND (?3 = '' OR sites.check_type = ?3)
+            AND (?4 = '' OR EXISTS (SELECT 1 FROM site_tags t WHERE t.site_id = sites.id AND t.tag = ?4))
+            AND (?5 = '' OR sites.url LIKE ?5 ESCAPE '\' OR sites.name LIKE ?5 ESCAPE '\')
+        ) f WHERE ` + statusCond + `
+      )`
+	args := []any{arg.OrganizationID, sqliteTime(arg.UptimeSince), arg.Type, arg.Tag, likePattern(arg.Search)}
+
+	var result ListSitesResult
+	if err := s.conn.QueryRowContext(ctx, filtered+` SELECT count(*) FROM filtered`, args...).Scan(&result.Total); err != nil {
+		return ListSitesResult{}, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if arg.Descending {
+		dir, cmp = "DESC", "<"
+	}
+	cursorCond := "1"
+	if cursor != nil {
+		cursorCond = fmt.Sprintf(`(%s, sites.id) %s (%s, ?7)`, sortExpr, cmp, cursorValue)
+		args = append(args, cursor.Value, cursor.ID)
+	}
+	// LIMIT -1 berarti tanpa batas
+	limit := -1
+	if arg.Limit > 0 {
+		limit = arg.Limit + 1
+	}
+	args = append(args, limit)
+
+	query := filtered + ` SELECT ` + sqliteSiteColumns + `, f.last_is_up, f.last_response_time_ms, f.last_checked_at, f.uptime_percent,
+                CAST(` + sortExpr + ` AS TEXT)
+              FROM filtered f JOIN sites ON sites.id = f.site_id
+              WHERE ` + cursorCond + `
+              ORDER BY ` + sortExpr + ` ` + dir + `, sites.id ` + dir + `
+              LIMIT ?` + fmt.Sprint(len(args))
+
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return ListSitesResult{}, err
+	}
+	defer rows.Close()
+
+	result.Sites = []SiteListItem{}
+	sortKeys := []string{}
+	for rows.Next() {
+		var item SiteListItem
+		var lastIsUp *bool
+		var lastCheckedAt sql.NullString
+		var sortKey string
+		item.Site, err = scanSQLiteSite(rows, &lastIsUp, &item.LastResponseTimeMs, &lastCheckedAt, &item.UptimePercent, &sortKey)
+		if err != nil {
+			return ListSitesResult{}, err
+		}
+		if item.LastCheckedAt, err = parseNullSQLiteTime(lastCheckedAt); err != nil {
+			return ListSitesResult{}, err
+		}
+		item.Status = SiteStatusOf(item.Site, lastIsUp)
+		result.Sites = append(result.Sites, item)
+		sortKeys = append(sortKeys, sortKey)
+	}
+	if err := rows.Err(); err != nil {
+		return ListSitesResult{}, err
+	}
+	return paginateSites(arg, result, sortKeys), nil
+}
+
+func (s *SQLiteStore) SetSiteTags(ctx context.Context, siteID int64, orgID int64, tags []string) (Site, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return Site{}, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `SELECT id FROM sites WHERE id = ? AND organization_id = ? AND archived_at IS NULL`, siteID, orgID).Scan(&id)
+	if err != nil {
+		return Site{}, sqliteError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM site_tags WHERE site_id = ?`, siteID); err != nil {
+		return Site{}, err
+	}
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO site_tags (site_id, tag) VALUES (?, ?)`, siteID, tag); err != nil {
+			return Site{}, err
+		}
+	}
+
+	site, err := scanSQLiteSite(tx.QueryRowContext(ctx, `SELECT `+sqliteSiteColumns+` FROM sites WHERE id = ?`, siteID))
+	if err != nil {
+		return Site{}, err
+	}
+	return site, tx.Commit()
+}
+
+func (s *SQLiteStore) GetArchivedSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error) {
+	query := `SELECT ` + sqliteSiteColumns + ` FROM sites WHERE organization_id = ? AND archived_at IS NOT NULL ORDER BY archived_at DESC`
+	return s.querySites(ctx, query, orgID)
+}
+
+func (s *SQLiteStore) GetAllSites(ctx context.Context) ([]Site, error) {
+	query := `SELECT ` + sqliteSiteColumns + ` FROM sites WHERE archived_at IS NULL`
+	return s.querySites(ctx, query)
+}
+
+func (s *SQLiteStore) querySites(ctx context.Context, query string, args ...any) ([]Site, error) {
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sites := []Site{}
+	for rows.Next() {
+		site, err := scanSQLiteSite(rows)
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, site)
+	}
+	return sites, rows.Err()
+}
+
+func (s *SQLiteStore) ArchiveSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET archived_at = ? WHERE id = ? AND organization_id = ? AND archived_at IS NULL RETURNING ` + sqliteSiteColumns
+
+	site, err := scanSQLiteSite(s.conn.QueryRowContext(ctx, query, sqliteTime(time.Now()), siteID, orgID))
+	return site, sqliteError(err)
+}
+
+func (s *SQLiteStore) RestoreSite(ctx context.Context, siteID int64, orgID int64) (Site, error) {
+	query := `UPDATE sites SET archived_at = NULL WHERE id = ? AND organization_id = ? AND archived_at IS NOT NULL RETURNING ` + sqliteSiteColumns
+
+	site, err := scanSQLiteSite(s.conn.QueryRowContext(ctx, query, siteID, orgID))
+	return site, sqliteError(err)
+}
+
+func (s *SQLiteStore) PurgeArchivedSites(ctx context.Context, archivedBefore time.Time) (int64, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	before := sqliteTime(archivedBefore)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM health_checks WHERE site_id IN (SELECT id FROM sites WHERE archived_at < ?)`, before); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM sites WHERE archived_at < ?`, before)
+	if err != nil {
+		return 0, err
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return purged, tx.Commit()
+}
+
+// --- HealthCheck ---
+
+func (s *SQLiteStore) CreateHealthCheck(ctx context.Context, arg CreateHealthCheckParams) (HealthCheck, error) {
+	hc := HealthCheck{
+		SiteID:         arg.SiteID,
+		StatusCode:     arg.StatusCode,
+		ResponseTimeMs: arg.ResponseTimeMs,
+		IsUp:           arg.IsUp,
+		CheckedAt:      time.Now().UTC(),
+	}
+
+	query := `INSERT INTO health_checks (site_id, status_code, response_time_ms, is_up, checked_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := s.conn.ExecContext(ctx, query, hc.SiteID, hc.StatusCode, hc.ResponseTimeMs, hc.IsUp, sqliteTime(hc.CheckedAt))
+	if err != nil {
+		return HealthCheck{}, sqliteError(err)
+	}
+	hc.ID, err = res.LastInsertId()
+	return hc, err
+}
+
+// CreateHealthChecks menyimpan banyak hasil health check dalam satu transaksi.
+func (s *SQLiteStore) CreateHealthChecks(ctx context.Context, checks []HealthCheck) (int64, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO health_checks (site_id, status_code, response_time_ms, is_up, checked_at) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	for _, hc := range checks {
+		if _, err := stmt.ExecContext(ctx, hc.SiteID, hc.StatusCode, hc.ResponseTimeMs, hc.IsUp, sqliteTime(hc.CheckedAt)); err != nil {
+			return 0, sqliteError(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int64(len(checks)), nil
+}
+
+func (s *SQLiteStore) StreamHealthChecks(ctx context.Context, arg StreamHealthChecksParams, fn func(HealthCheck) error) error {
+	query := `SELECT hc.id, hc.site_id, COALESCE(hc.status_code, 0), COALESCE(hc.response_time_ms, 0), hc.is_up, hc.checked_at
+              FROM health_checks hc JOIN sites s ON s.id = hc.site_id
+              WHERE s.organization_id = ? AND (? = 0 OR hc.site_id = ?) AND hc.checked_at >= ? AND hc.checked_at < ?
+              ORDER BY hc.checked_at`
+
+	rows, err := s.conn.QueryContext(ctx, query, arg.OrganizationID, arg.SiteID, arg.SiteID, sqliteTime(arg.From), sqliteTime(arg.To))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hc HealthCheck
+		var checkedAt string
+		if err := rows.Scan(&hc.ID, &hc.SiteID, &hc.StatusCode, &hc.ResponseTimeMs, &hc.IsUp, &checkedAt); err != nil {
+			return err
+		}
+		if hc.CheckedAt, err = parseSQLiteTime(checkedAt); err != nil {
+			return err
+		}
+		if err := fn(hc); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteStore) GetSiteUptime(ctx context.Context, siteID int64, from, to time.Time) (Uptime, error) {
+	query := `SELECT count(*), COALESCE(SUM(is_up), 0) FROM health_checks
+              WHERE site_id = ? AND checked_at >= ? AND checked_at < ?`
+
+	var total, up int64
+	if err := s.conn.QueryRowContext(ctx, query, siteID, sqliteTime(from), sqliteTime(to)).Scan(&total, &up); err != nil {
+		return Uptime{}, err
+	}
+	return newUptime(siteID, total, up), nil
+}
+
+func (s *SQLiteStore) GetSitesUptime(ctx context.Context, siteIDs []int64, from, to time.Time) (map[int64]Uptime, error) {
+	ids, err := json.Marshal(siteIDs)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT site_id, count(*), COALESCE(SUM(is_up), 0) FROM health_checks
+              WHERE site_id IN (SELECT value FROM json_each(?)) AND checked_at >= ? AND checked_at < ?
+              GROUP BY site_id`
+
+	rows, err := s.conn.QueryContext(ctx, query, string(ids), sqliteTime(from), sqliteTime(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	uptimes := make(map[int64]Uptime, len(siteIDs))
+	for rows.Next() {
+		var siteID, total, up int64
+		if err := rows.Scan(&siteID, &total, &up); err != nil {
+			return nil, err
+		}
+		uptimes[siteID] = newUptime(siteID, total, up)
+	}
+	return uptimes, rows.Err()
+}
+
+func (s *SQLiteStore) GetLatestHealthChecks(ctx context.Context, siteIDs []int64) (map[int64]HealthCheck, error) {
+	ids, err := json.Marshal(siteIDs)
+	if err != nil {
+		return nil, err
+	}
+	query := `SELECT id, site_id, COALESCE(status_code, 0), COALESCE(response_time_ms, 0), is_up, checked_at FROM (
+                SELECT *, row_number() OVER (PARTITION BY site_id ORDER BY checked_at DESC) AS rn FROM health_checks
+                WHERE site_id IN (SELECT value FROM json_each(?))
+              ) WHERE rn = 1`
+
+	rows, err := s.conn.QueryContext(ctx, query, string(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	latest := make(map[int64]HealthCheck, len(siteIDs))
+	for rows.Next() {
+		var hc HealthCheck
+		var checkedAt string
+		if err := rows.Scan(&hc.ID, &hc.SiteID, &hc.StatusCode, &hc.ResponseTimeMs, &hc.IsUp, &checkedAt); err != nil {
+			return nil, err
+		}
+		if hc.CheckedAt, err = parseSQLiteTime(checkedAt); err != nil {
+			return nil, err
+		}
+		latest[hc.SiteID] = hc
+	}
+	return latest, rows.Err()
+}
+
+// --- SiteGroup ---
+
+// Kolom grup yang dipilih oleh semua query, urutannya sesuai dengan scanSQLiteSiteGroup
+const sqliteSiteGroupColumns = `id, organization_id, user_id, name, created_at,
+    (SELECT json_group_array(site_id) FROM (
+        SELECT m.site_id FROM site_group_members m JOIN sites ON sites.id = m.site_id
+        WHERE m.group_id = site_groups.id AND sites.archived_at IS NULL ORDER BY m.site_id))`
+
+func scanSQLiteSiteGroup(row sqliteScanner) (SiteGroup, error) {
+	var g SiteGroup
+	var createdAt, siteIDs string
+	if err := row.Scan(&g.ID, &g.OrganizationID, &g.UserID, &g.Name, &createdAt, &siteIDs); err != nil {
+		return SiteGroup{}, err
+	}
+	var err error
+	if g.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return SiteGroup{}, err
+	}
+	err = json.Unmarshal([]byte(siteIDs), &g.SiteIDs)
+	return g, err
+}
+
+// setSQLiteSiteGroupMembers mengganti anggota grup. Site milik organisasi lain diabaikan.
+func setSQLiteSiteGroupMembers(ctx context.Context, tx sqliteDBTX, groupID int64, orgID int64, siteIDs []int64) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM site_group_members WHERE group_id = ?`, groupID); err != nil {
+		return err
+	}
+	ids, err := json.Marshal(siteIDs)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT OR IGNORE INTO site_group_members (group_id, site_id)
+                                  SELECT ?, id FROM sites WHERE id IN (SELECT value FROM json_each(?)) AND organization_id = ?`, groupID, string(ids), orgID)
+	return err
+}
+
+func (s *SQLiteStore) CreateSiteGroup(ctx context.Context, arg CreateSiteGroupParams) (SiteGroup, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `INSERT INTO site_groups (organization_id, user_id, name, created_at) VALUES (?, ?, ?, ?)`,
+		arg.OrganizationID, arg.UserID, arg.Name, sqliteTime(time.Now()))
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	groupID, err := res.LastInsertId()
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	if err := setSQLiteSiteGroupMembers(ctx, tx, groupID, arg.OrganizationID, arg.SiteIDs); err != nil {
+		return SiteGroup{}, err
+	}
+
+	g, err := scanSQLiteSiteGroup(tx.QueryRowContext(ctx, `SELECT `+sqliteSiteGroupColumns+` FROM site_groups WHERE id = ?`, groupID))
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	return g, tx.Commit()
+}
+
+func (s *SQLiteStore) GetSiteGroup(ctx context.Context, groupID int64, orgID int64) (SiteGroup, error) {
+	query := `SELECT ` + sqliteSiteGroupColumns + ` FROM site_groups WHERE id = ? AND organization_id = ?`
+
+	g, err := scanSQLiteSiteGroup(s.conn.QueryRowContext(ctx, query, groupID, orgID))
+	return g, sqliteError(err)
+}
+
+func (s *SQLiteStore) ListSiteGroups(ctx context.Context, orgID int64) ([]SiteGroup, error) {
+	query := `SELECT ` + sqliteSiteGroupColumns + ` FROM site_groups WHERE organization_id = ? ORDER BY name, id`
+
+	rows, err := s.conn.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []SiteGroup{}
+	for rows.Next() {
+		g, err := scanSQLiteSiteGroup(rows)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+func (s *SQLiteStore) UpdateSiteGroup(ctx context.Context, groupID int64, orgID int64, arg UpdateSiteGroupParams) (SiteGroup, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `UPDATE site_groups SET name = COALESCE(?, name) WHERE id = ? AND organization_id = ? RETURNING id`,
+		arg.Name, groupID, orgID).Scan(&id)
+	if err != nil {
+		return SiteGroup{}, sqliteError(err)
+	}
+	if arg.SiteIDs != nil {
+		if err := setSQLiteSiteGroupMembers(ctx, tx, groupID, orgID, *arg.SiteIDs); err != nil {
+			return SiteGroup{}, err
+		}
+	}
+
+	g, err := scanSQLiteSiteGroup(tx.QueryRowContext(ctx, `SELECT `+sqliteSiteGroupColumns+` FROM site_groups WHERE id = ?`, groupID))
+	if err != nil {
+		return SiteGroup{}, err
+	}
+	return g, tx.Commit()
+}
+
+func (s *SQLiteStore) DeleteSiteGroup(ctx context.Context, groupID int64, orgID int64) error {
+	res, err := s.conn.ExecContext(ctx, `DELETE FROM site_groups WHERE id = ? AND organization_id = ?`, groupID, orgID)
+	if err != nil {
+		return err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// sqliteScanner adalah *sql.Row atau *sql.Rows.
+type sqliteScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSQLiteSite membaca kolom sqliteSiteColumns, diikuti kolom tambahan (jika ada) ke dalam extra.
+func scanSQLiteSite(row sqliteScanner, extra ...any) (Site, error) {
+	var site Site
+	var createdAt string
+	var pausedAt, archivedAt sql.NullString
+	var tags string
+	dest := []any{&site.ID, &site.OrganizationID, &site.UserID, &site.URL, &site.Name, &site.Description, &site.CheckType,
+		&site.CheckIntervalSeconds, &site.TimeoutSeconds, &createdAt, &pausedAt, &archivedAt, &tags}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return Site{}, err
+	}
+	if site.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return Site{}, err
+	}
+	if site.PausedAt, err = parseNullSQLiteTime(pausedAt); err != nil {
+		return Site{}, err
+	}
+	if site.ArchivedAt, err = parseNullSQLiteTime(archivedAt); err != nil {
+		return Site{}, err
+	}
+	err = json.Unmarshal([]byte(tags), &site.Tags)
+	return site, err
+}
+
+// sqliteError menerjemahkan sql.ErrNoRows menjadi ErrRecordNotFound dan pelanggaran constraint menjadi error domain
+// agar handler tidak perlu tahu backend yang dipakai.
+func sqliteError(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRecordNotFound
+	}
+	return sqliteConstraintError(err)
+}
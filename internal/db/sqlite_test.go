@@ -0,0 +1,191 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestSQLiteStore membuka database SQLite baru di direktori sementara tanpa menjalankan migrasi.
+func newTestSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "pulse.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// newMigratedSQLiteStore membuka database SQLite baru dengan semua migrasi diterapkan.
+func newMigratedSQLiteStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store := newTestSQLiteStore(t)
+	migrator, err := store.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	return store
+}
+
+// createSQLiteOrganization membuat user beserta organisasinya.
+func createSQLiteOrganization(t *testing.T, store Store, username string) (User, Organization) {
+	t.Helper()
+	ctx := context.Background()
+	user, err := store.CreateUser(ctx, CreateUserParams{Username: username, Email: username + "@example.com", PasswordHash: "hash"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	org, err := store.CreateOrganization(ctx, username, user.ID)
+	if err != nil {
+		t.Fatalf("CreateOrganization: %v", err)
+	}
+	return user, org
+}
+
+func TestSQLiteSiteRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedSQLiteStore(t)
+	user, org := createSQLiteOrganization(t, store, "alice")
+
+	site, err := store.CreateSite(ctx, CreateSiteParams{OrganizationID: org.ID, UserID: user.ID, URL: "https://example.com", Name: "Example"})
+	if err != nil {
+		t.Fatalf("CreateSite: %v", err)
+	}
+	if site.CheckIntervalSeconds != DefaultCheckIntervalSeconds || site.CheckType != CheckTypeHTTP || len(site.Tags) != 0 {
+		t.Errorf("created site = %+v, want defaults", site)
+	}
+	if site, err = store.SetSiteTags(ctx, site.ID, org.ID, []string{"eu", "prod"}); err != nil {
+		t.Fatalf("SetSiteTags: %v", err)
+	}
+
+	got, err := store.GetSite(ctx, site.ID)
+	if err != nil {
+		t.Fatalf("GetSite: %v", err)
+	}
+	if got.URL != site.URL || got.Name != "Example" || len(got.Tags) != 2 || !got.CreatedAt.Equal(site.CreatedAt) {
+		t.Errorf("GetSite = %+v, want %+v", got, site)
+	}
+
+	if _, err := store.GetSite(ctx, site.ID+1); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("GetSite of a missing site: err = %v, want ErrRecordNotFound", err)
+	}
+	if _, err := store.ArchiveSite(ctx, site.ID, org.ID); err != nil {
+		t.Fatalf("ArchiveSite: %v", err)
+	}
+	if sites, err := store.GetSitesByOrganizationID(ctx, org.ID); err != nil || len(sites) != 0 {
+		t.Errorf("active sites after archive = %v (%v), want none", sites, err)
+	}
+}
+
+func TestSQLiteConstraintErrors(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedSQLiteStore(t)
+	user, org := createSQLiteOrganization(t, store, "alice")
+
+	_, err := store.CreateUser(ctx, CreateUserParams{Username: "other", Email: user.Email, PasswordHash: "hash"})
+	var domainErr *Error
+	if !errors.Is(err, ErrConflict) || !errors.As(err, &domainErr) || domainErr.Field != "email" {
+		t.Errorf("CreateUser with a taken email: err = %v, want email conflict", err)
+	}
+
+	_, err = store.CreateSite(ctx, CreateSiteParams{OrganizationID: org.ID, UserID: user.ID + 100, URL: "https://example.com"})
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("CreateSite for a missing user: err = %v, want ErrValidation", err)
+	}
+
+	_, err = store.AddOrganizationMember(ctx, AddOrganizationMemberParams{OrganizationID: org.ID, UserID: user.ID, Role: RoleViewer})
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("AddOrganizationMember twice: err = %v, want ErrConflict", err)
+	}
+}
+
+var errTestSQLiteRollback = errors.New("rollback")
+
+func TestSQLiteExecTx(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedSQLiteStore(t)
+	user, org := createSQLiteOrganization(t, store, "alice")
+	createSite := func(s Store, url string) error {
+		_, err := s.CreateSite(ctx, CreateSiteParams{OrganizationID: org.ID, UserID: user.ID, URL: url})
+		return err
+	}
+
+	err := store.ExecTx(ctx, func(tx Store) error {
+		if err := createSite(tx, "https://rolled-back.example.com"); err != nil {
+			return err
+		}
+		return errTestSQLiteRollback
+	})
+	if !errors.Is(err, errTestSQLiteRollback) {
+		t.Fatalf("ExecTx: err = %v", err)
+	}
+
+	// Transaksi bersarang yang gagal hanya membatalkan penulisannya sendiri
+	err = store.ExecTx(ctx, func(tx Store) error {
+		if err := createSite(tx, "https://kept.example.com"); err != nil {
+			return err
+		}
+		err := tx.ExecTx(ctx, func(nested Store) error {
+			if err := createSite(nested, "https://nested.example.com"); err != nil {
+				return err
+			}
+			return errTestSQLiteRollback
+		})
+		if !errors.Is(err, errTestSQLiteRollback) {
+			t.Errorf("nested ExecTx: err = %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExecTx: %v", err)
+	}
+
+	sites, err := store.GetSitesByOrganizationID(ctx, org.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 1 || sites[0].URL != "https://kept.example.com" {
+		t.Errorf("sites = %+v, want only the site from the committed transaction", sites)
+	}
+}
+
+func TestSQLiteStreamHealthChecks(t *testing.T) {
+	ctx := context.Background()
+	store := newMigratedSQLiteStore(t)
+	user, org := createSQLiteOrganization(t, store, "alice")
+	site, err := store.CreateSite(ctx, CreateSiteParams{OrganizationID: org.ID, UserID: user.ID, URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	checks := []HealthCheck{
+		{SiteID: site.ID, StatusCode: 200, ResponseTimeMs: 100, IsUp: true, CheckedAt: now.Add(-2 * time.Hour)},
+		{SiteID: site.ID, StatusCode: 500, ResponseTimeMs: 50, IsUp: false, CheckedAt: now.Add(-time.Hour)},
+	}
+	if _, err := store.CreateHealthChecks(ctx, checks); err != nil {
+		t.Fatalf("CreateHealthChecks: %v", err)
+	}
+	if _, err := store.CreateHealthChecks(ctx, []HealthCheck{{SiteID: site.ID + 100, CheckedAt: now}}); !errors.Is(err, ErrValidation) {
+		t.Errorf("CreateHealthChecks for a missing site: err = %v, want ErrValidation", err)
+	}
+
+	var got []HealthCheck
+	err = store.StreamHealthChecks(ctx, StreamHealthChecksParams{OrganizationID: org.ID, From: now.Add(-90 * time.Minute), To: now},
+		func(hc HealthCheck) error {
+			got = append(got, hc)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("StreamHealthChecks: %v", err)
+	}
+	if len(got) != 1 || got[0].StatusCode != 500 || !got[0].CheckedAt.Equal(checks[1].CheckedAt) {
+		t.Errorf("streamed checks = %+v, want only the check inside the range", got)
+	}
+}
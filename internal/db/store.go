@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrRecordNotFound dikembalikan oleh semua implementasi Store ketika data yang dicari tidak ada.
+var ErrRecordNotFound = pgx.ErrNoRows
+
+// Store mendefinisikan semua operasi database yang dibutuhkan API dan worker.
+// Implementasinya: SQLStore (PostgreSQL), SQLiteStore (SQLite) dan MemoryStore (in-memory, untuk demo dan testing).
+type Store interface {
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	GetUser(ctx context.Context, userID int64) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	VerifyUserEmail(ctx context.Context, userID int64, email string) (User, error)
+	GetUserByIdentity(ctx context.Context, issuer, subject string) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
+
+	CreateOrganization(ctx context.Context, name string, ownerID int64) (Organization, error)
+	GetOrganization(ctx context.Context, orgID int64) (Organization, error)
+	ListUserOrganizations(ctx context.Context, userID int64) ([]UserOrganization, error)
+	UpdateOrganization(ctx context.Context, orgID int64, name string) (Organization, error)
+	DeleteOrganization(ctx context.Context, orgID int64) error
+	GetOrganizationMember(ctx context.Context, orgID int64, userID int64) (OrganizationMember, error)
+	ListOrganizationMembers(ctx context.Context, orgID int64) ([]OrganizationMember, error)
+	AddOrganizationMember(ctx context.Context, arg AddOrganizationMemberParams) (OrganizationMember, error)
+	UpdateOrganizationMemberRole(ctx context.Context, orgID int64, userID int64, role string) (OrganizationMember, error)
+	RemoveOrganizationMember(ctx context.Context, orgID int64, userID int64) error
+
+	CreateTeam(ctx context.Context, arg CreateTeamParams) (Team, error)
+	GetTeam(ctx context.Context, teamID int64, orgID int64) (Team, error)
+	ListTeams(ctx context.Context, orgID int64) ([]Team, error)
+	UpdateTeam(ctx context.Context, teamID int64, orgID int64, arg UpdateTeamParams) (Team, error)
+	DeleteTeam(ctx context.Context, teamID int64, orgID int64) error
+
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) (ListAuditEventsResult, error)
+
+	CreateSite(ctx context.Context, arg CreateSiteParams) (Site, error)
+	GetSite(ctx context.Context, siteID int64) (Site, error)
+	GetSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error)
+	CountActiveSitesByUserID(ctx context.Context, userID int64) (int, error)
+	ListSites(ctx context.Context, arg ListSitesParams) (ListSitesResult, error)
+	GetArchivedSitesByOrganizationID(ctx context.Context, orgID int64) ([]Site, error)
+	GetAllSites(ctx context.Context) ([]Site, error)
+	UpdateSite(ctx context.Context, siteID int64, orgID int64, arg UpdateSiteParams) (Site, error)
+	PauseSite(ctx context.Context, siteID int64, orgID int64) (Site, error)
+	ResumeSite(ctx context.Context, siteID int64, orgID int64) (Site, error)
+	SetSiteTags(ctx context.Context, siteID int64, orgID int64, tags []string) (Site, error)
+	ArchiveSite(ctx context.Context, siteID int64, orgID int64) (Site, error)
+	RestoreSite(ctx context.Context, siteID int64, orgID int64) (Site, error)
+	PurgeArchivedSites(ctx context.Context, archivedBefore time.Time) (int64, error)
+
+	CreateHealthCheck(ctx context.Context, arg CreateHealthCheckParams) (HealthCheck, error)
+	CreateHealthChecks(ctx context.Context, checks []HealthCheck) (int64, error)
+	StreamHealthChecks(ctx context.Context, arg StreamHealthChecksParams, fn func(HealthCheck) error) error
+	GetSiteUptime(ctx context.Context, siteID int64, from, to time.Time) (Uptime, error)
+	GetSitesUptime(ctx context.Context, siteIDs []int64, from, to time.Time) (map[int64]Uptime, error)
+	GetLatestHealthChecks(ctx context.Context, siteIDs []int64) (map[int64]HealthCheck, error)
+
+	CreateSiteGroup(ctx context.Context, arg CreateSiteGroupParams) (SiteGroup, error)
+	GetSiteGroup(ctx context.Context, groupID int64, orgID int64) (SiteGroup, error)
+	ListSiteGroups(ctx context.Context, orgID int64) ([]SiteGroup, error)
+	UpdateSiteGroup(ctx context.Context, groupID int64, orgID int64, arg UpdateSiteGroupParams) (SiteGroup, error)
+	DeleteSiteGroup(ctx context.Context, groupID int64, orgID int64) error
+
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	GetSession(ctx context.Context, sessionID int64) (Session, error)
+	GetSessionByRefreshToken(ctx context.Context, tokenHash string) (Session, error)
+	RotateSession(ctx context.Context, arg RotateSessionParams) (Session, error)
+	ListSessions(ctx context.Context, userID int64) ([]Session, error)
+	RevokeSession(ctx context.Context, sessionID int64, userID int64) error
+	RevokeUserSessions(ctx context.Context, userID int64, exceptSessionID int64) (int64, error)
+
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error)
+	ListAPIKeys(ctx context.Context, userID int64) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID int64, userID int64) error
+	TouchAPIKey(ctx context.Context, keyID int64) error
+
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	ResetPassword(ctx context.Context, arg ResetPasswordParams) (int64, error)
+
+	CreateTOTPEnrollment(ctx context.Context, userID int64, secret string) (UserTOTP, error)
+	GetTOTP(ctx context.Context, userID int64) (UserTOTP, error)
+	ConfirmTOTP(ctx context.Context, arg ConfirmTOTPParams) error
+	UseTOTPStep(ctx context.Context, userID int64, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error
+	DeleteTOTP(ctx context.Context, userID int64) error
+
+	GetLoginThrottle(ctx context.Context, email string) (LoginThrottle, error)
+	RecordLoginFailure(ctx context.Context, arg RecordLoginFailureParams) (LoginThrottle, error)
+	LockLogin(ctx context.Context, email string, until time.Time) error
+	ResetLoginFailures(ctx context.Context, email string) error
+
+	// ExecTx menjalankan fn dalam satu transaksi; semua perubahan lewat Store yang diberikan ke fn
+	// dibatalkan jika fn mengembalikan error.
+	ExecTx(ctx context.Context, fn func(Store) error) error
+}
+
+// PartitionStore diimplementasikan oleh store yang menyimpan health_checks dalam partisi bulanan.
+type PartitionStore interface {
+	CreateHealthCheckPartition(ctx context.Context, t time.Time) error
+	ListHealthCheckPartitions(ctx context.Context) ([]time.Time, error)
+	DropHealthCheckPartition(ctx context.Context, t time.Time) error
+}
+
+var (
+	_ Store          = (*SQLStore)(nil)
+	_ PartitionStore = (*SQLStore)(nil)
+	_ Migratable     = (*SQLStore)(nil)
+	_ Store          = (*SQLiteStore)(nil)
+	_ Migratable     = (*SQLiteStore)(nil)
+	_ Store          = (*MemoryStore)(nil)
+)
@@ -0,0 +1,347 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UserTOTP adalah secret TOTP milik user. 2FA baru aktif setelah enrolment dikonfirmasi dengan kode pertama.
+type UserTOTP struct {
+	UserID       int64      `json:"user_id"`
+	Secret       string     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	LastUsedStep int64      `json:"-"`
+}
+
+// Enabled bernilai true jika enrolment sudah dikonfirmasi sehingga login memerlukan kode 2FA.
+func (t UserTOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+type ConfirmTOTPParams struct {
+	UserID int64
+	// Step adalah periode TOTP dari kode yang dipakai untuk konfirmasi
+	Step int64
+	// RecoveryCodeHashes menggantikan semua recovery code lama milik user
+	RecoveryCodeHashes []string
+}
+
+const userTOTPColumns = `user_id, secret, created_at, confirmed_at, last_used_step`
+
+func scanUserTOTP(row interface{ Scan(...any) error }) (UserTOTP, error) {
+	var t UserTOTP
+	err := row.Scan(&t.UserID, &t.Secret, &t.CreatedAt, &t.ConfirmedAt, &t.LastUsedStep)
+	return t, err
+}
+
+// CreateTOTPEnrollment menyimpan secret baru yang belum dikonfirmasi, menggantikan enrolment sebelumnya
+// yang juga belum dikonfirmasi. ErrRecordNotFound dikembalikan jika 2FA user sudah aktif.
+func (s *SQLStore) CreateTOTPEnrollment(ctx context.Context, userID int64, secret string) (UserTOTP, error) {
+	query := `INSERT INTO user_totp (user_id, secret) VALUES ($1, $2)
+              ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, created_at = now(), last_used_step = 0
+              WHERE user_totp.confirmed_at IS NULL
+              RETURNING ` + userTOTPColumns
+
+	return scanUserTOTP(s.conn.QueryRow(ctx, query, userID, secret))
+}
+
+func (s *SQLStore) GetTOTP(ctx context.Context, userID int64) (UserTOTP, error) {
+	return scanUserTOTP(s.conn.QueryRow(ctx, `SELECT `+userTOTPColumns+` FROM user_totp WHERE user_id = $1`, userID))
+}
+
+// ConfirmTOTP mengaktifkan 2FA dan menyimpan recovery code baru. ErrRecordNotFound dikembalikan jika
+// tidak ada enrolment yang menunggu konfirmasi.
+func (s *SQLStore) ConfirmTOTP(ctx context.Context, arg ConfirmTOTPParams) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `UPDATE user_totp SET confirmed_at = now(), last_used_step = $2
+                              WHERE user_id = $1 AND confirmed_at IS NULL`, arg.UserID, arg.Step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, arg.UserID); err != nil {
+		return err
+	}
+	for _, hash := range arg.RecoveryCodeHashes {
+		if _, err := tx.Exec(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)`, arg.UserID, hash); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep mencatat periode kode TOTP yang baru dipakai. ErrRecordNotFound dikembalikan jika 2FA tidak
+// aktif atau periode tersebut tidak lebih baru dari yang terakhir dipakai (kode dipakai ulang).
+func (s *SQLStore) UseTOTPStep(ctx context.Context, userID int64, step int64) error {
+	tag, err := s.conn.Exec(ctx, `UPDATE user_totp SET last_used_step = $2
+                                  WHERE user_id = $1 AND confirmed_at IS NOT NULL AND last_used_step < $2`, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// UseRecoveryCode menandai recovery code sebagai terpakai. ErrRecordNotFound dikembalikan jika kode tidak
+// dikenal atau sudah pernah dipakai.
+func (s *SQLStore) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	tag, err := s.conn.Exec(ctx, `UPDATE recovery_codes SET used_at = now()
+                                  WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteTOTP menonaktifkan 2FA user beserta semua recovery code-nya.
+func (s *SQLStore) DeleteTOTP(ctx context.Context, userID int64) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRecordNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+// --- SQLite ---
+
+func scanSQLiteUserTOTP(row sqliteScanner) (UserTOTP, error) {
+	var t UserTOTP
+	var createdAt string
+	var confirmedAt sql.NullString
+	if err := row.Scan(&t.UserID, &t.Secret, &createdAt, &confirmedAt, &t.LastUsedStep); err != nil {
+		return UserTOTP{}, err
+	}
+	var err error
+	if t.CreatedAt, err = parseSQLiteTime(createdAt); err != nil {
+		return UserTOTP{}, err
+	}
+	t.ConfirmedAt, err = parseNullSQLiteTime(confirmedAt)
+	return t, err
+}
+
+func (s *SQLiteStore) CreateTOTPEnrollment(ctx context.Context, userID int64, secret string) (UserTOTP, error) {
+	query := `INSERT INTO user_totp (user_id, secret, created_at) VALUES (?, ?, ?)
+              ON CONFLICT (user_id) DO UPDATE SET secret = excluded.secret, created_at = excluded.created_at, last_used_step = 0
+              WHERE user_totp.confirmed_at IS NULL
+              RETURNING ` + userTOTPColumns
+
+	t, err := scanSQLiteUserTOTP(s.conn.QueryRowContext(ctx, query, userID, secret, sqliteTime(time.Now())))
+	return t, sqliteError(err)
+}
+
+func (s *SQLiteStore) GetTOTP(ctx context.Context, userID int64) (UserTOTP, error) {
+	t, err := scanSQLiteUserTOTP(s.conn.QueryRowContext(ctx, `SELECT `+userTOTPColumns+` FROM user_totp WHERE user_id = ?`, userID))
+	return t, sqliteError(err)
+}
+
+func (s *SQLiteStore) ConfirmTOTP(ctx context.Context, arg ConfirmTOTPParams) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE user_totp SET confirmed_at = ?, last_used_step = ?
+                                     WHERE user_id = ? AND confirmed_at IS NULL`, sqliteTime(time.Now()), arg.Step, arg.UserID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrRecordNotFound
+		}
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, arg.UserID); err != nil {
+		return err
+	}
+	for _, hash := range arg.RecoveryCodeHashes {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO recovery_codes (user_id, code_hash) VALUES (?, ?)`, arg.UserID, hash); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) UseTOTPStep(ctx context.Context, userID int64, step int64) error {
+	res, err := s.conn.ExecContext(ctx, `UPDATE user_totp SET last_used_step = ?
+                                         WHERE user_id = ? AND confirmed_at IS NOT NULL AND last_used_step < ?`, step, userID, step)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrRecordNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteStore) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	res, err := s.conn.ExecContext(ctx, `UPDATE recovery_codes SET used_at = ?
+                                         WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`, sqliteTime(time.Now()), userID, codeHash)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrRecordNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLiteStore) DeleteTOTP(ctx context.Context, userID int64) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		if err == nil {
+			err = ErrRecordNotFound
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// --- Memory ---
+
+// memoryRecoveryCode adalah satu recovery code di MemoryStore.
+type memoryRecoveryCode struct {
+	userID   int64
+	codeHash string
+	used     bool
+}
+
+func (s *MemoryStore) CreateTOTPEnrollment(ctx context.Context, userID int64, secret string) (UserTOTP, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return UserTOTP{}, ErrRecordNotFound
+	}
+	if t, ok := s.totps[userID]; ok && t.Enabled() {
+		return UserTOTP{}, ErrRecordNotFound
+	}
+	t := UserTOTP{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+	setEntry(s, s.totps, userID, t)
+	return t, nil
+}
+
+func (s *MemoryStore) GetTOTP(ctx context.Context, userID int64) (UserTOTP, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t, ok := s.totps[userID]
+	if !ok {
+		return UserTOTP{}, ErrRecordNotFound
+	}
+	return t, nil
+}
+
+func (s *MemoryStore) ConfirmTOTP(ctx context.Context, arg ConfirmTOTPParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.totps[arg.UserID]
+	if !ok || t.Enabled() {
+		return ErrRecordNotFound
+	}
+	now := time.Now()
+	t.ConfirmedAt = &now
+	t.LastUsedStep = arg.Step
+	setEntry(s, s.totps, arg.UserID, t)
+
+	s.deleteRecoveryCodesLocked(arg.UserID)
+	for _, hash := range arg.RecoveryCodeHashes {
+		s.nextRecoveryCodeID++
+		setEntry(s, s.recoveryCodes, s.nextRecoveryCodeID, memoryRecoveryCode{userID: arg.UserID, codeHash: hash})
+	}
+	return nil
+}
+
+func (s *MemoryStore) UseTOTPStep(ctx context.Context, userID int64, step int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.totps[userID]
+	if !ok || !t.Enabled() || t.LastUsedStep >= step {
+		return ErrRecordNotFound
+	}
+	t.LastUsedStep = step
+	setEntry(s, s.totps, userID, t)
+	return nil
+}
+
+func (s *MemoryStore) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, code := range s.recoveryCodes {
+		if code.userID == userID && code.codeHash == codeHash && !code.used {
+			code.used = true
+			setEntry(s, s.recoveryCodes, id, code)
+			return nil
+		}
+	}
+	return ErrRecordNotFound
+}
+
+func (s *MemoryStore) DeleteTOTP(ctx context.Context, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.totps[userID]; !ok {
+		return ErrRecordNotFound
+	}
+	deleteEntry(s, s.totps, userID)
+	s.deleteRecoveryCodesLocked(userID)
+	return nil
+}
+
+// deleteRecoveryCodesLocked menghapus semua recovery code milik user; s.mu harus sudah dikunci.
+func (s *MemoryStore) deleteRecoveryCodesLocked(userID int64) {
+	for id, code := range s.recoveryCodes {
+		if code.userID == userID {
+			deleteEntry(s, s.recoveryCodes, id)
+		}
+	}
+}
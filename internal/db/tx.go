@@ -0,0 +1,283 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DBTX adalah operasi yang dipakai SQLStore; dipenuhi oleh *pgxpool.Pool maupun pgx.Tx
+// sehingga semua method store dapat dijalankan di dalam transaksi.
+type DBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// pgConn membungkus DBTX sehingga pelanggaran constraint dari setiap query diterjemahkan oleh pgError,
+// termasuk query di dalam transaksi yang dimulai lewat Begin.
+type pgConn struct {
+	DBTX
+}
+
+func (c pgConn) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tag, err := c.DBTX.Exec(ctx, sql, args...)
+	return tag, pgError(err)
+}
+
+func (c pgConn) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := c.DBTX.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return pgRows{rows}, nil
+}
+
+func (c pgConn) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return pgRow{c.DBTX.QueryRow(ctx, sql, args...)}
+}
+
+func (c pgConn) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	n, err := c.DBTX.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	return n, pgError(err)
+}
+
+func (c pgConn) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := c.DBTX.Begin(ctx)
+	if err != nil {
+		return nil, pgError(err)
+	}
+	return pgTx{Tx: tx, conn: pgConn{tx}}, nil
+}
+
+// pgTx adalah pgx.Tx yang query-nya melewati pgConn. Commit juga diterjemahkan karena constraint
+// yang ditunda (DEFERRABLE) baru diperiksa saat commit.
+type pgTx struct {
+	pgx.Tx
+	conn pgConn
+}
+
+func (t pgTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.conn.Exec(ctx, sql, args...)
+}
+
+func (t pgTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.conn.Query(ctx, sql, args...)
+}
+
+func (t pgTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.conn.QueryRow(ctx, sql, args...)
+}
+
+func (t pgTx) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	return t.conn.CopyFrom(ctx, tableName, columnNames, rowSrc)
+}
+
+func (t pgTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	return t.conn.Begin(ctx)
+}
+
+func (t pgTx) Commit(ctx context.Context) error {
+	return pgError(t.Tx.Commit(ctx))
+}
+
+type pgRow struct {
+	pgx.Row
+}
+
+func (r pgRow) Scan(dest ...any) error {
+	return pgError(r.Row.Scan(dest...))
+}
+
+// pgRows menerjemahkan error yang baru muncul saat membaca hasil, misalnya dari INSERT ... RETURNING.
+type pgRows struct {
+	pgx.Rows
+}
+
+func (r pgRows) Err() error {
+	return pgError(r.Rows.Err())
+}
+
+// ExecTx menjalankan fn dengan Store yang terikat pada satu transaksi. Transaksi di-commit jika fn
+// mengembalikan nil dan di-rollback jika tidak. Transaksi di dalam fn menjadi savepoint.
+func (s *SQLStore) ExecTx(ctx context.Context, fn func(Store) error) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&SQLStore{conn: tx, pool: s.pool}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// sqliteDBTX adalah operasi yang dipakai SQLiteStore; dipenuhi oleh *sql.DB maupun *sql.Tx.
+type sqliteDBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// sqliteTx adalah transaksi yang dipakai method SQLiteStore: *sql.Tx atau savepoint di dalamnya.
+type sqliteTx interface {
+	sqliteDBTX
+	Commit() error
+	Rollback() error
+}
+
+// Penghitung nama savepoint agar transaksi bersarang tidak saling bertabrakan
+var sqliteSavepointSeq atomic.Int64
+
+// sqliteSavepoint adalah transaksi bersarang di dalam *sql.Tx yang sudah berjalan.
+type sqliteSavepoint struct {
+	*sql.Tx
+	name string
+	done bool
+}
+
+func (sp *sqliteSavepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.ExecContext(context.Background(), `RELEASE `+sp.name)
+	return err
+}
+
+func (sp *sqliteSavepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.ExecContext(context.Background(), `ROLLBACK TO `+sp.name+`; RELEASE `+sp.name)
+	return err
+}
+
+// begin memulai transaksi baru, atau savepoint jika store sudah berada di dalam transaksi.
+func (s *SQLiteStore) begin(ctx context.Context) (sqliteTx, error) {
+	if s.tx == nil {
+		return s.db.BeginTx(ctx, nil)
+	}
+	name := fmt.Sprintf("sp_%d", sqliteSavepointSeq.Add(1))
+	if _, err := s.tx.ExecContext(ctx, `SAVEPOINT `+name); err != nil {
+		return nil, err
+	}
+	return &sqliteSavepoint{Tx: s.tx, name: name}, nil
+}
+
+// ExecTx adalah padanan SQLStore.ExecTx untuk SQLite.
+func (s *SQLiteStore) ExecTx(ctx context.Context, fn func(Store) error) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txStore := &SQLiteStore{db: s.db, conn: tx, tx: s.tx}
+	if txStore.tx == nil {
+		txStore.tx = tx.(*sql.Tx)
+	}
+	if err := fn(txStore); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ExecTx menjalankan fn sambil memegang lock store, sehingga transaksi terisolasi dari penulisan lain. Jika fn
+// gagal, hanya penulisan yang dilakukan fn yang dibatalkan. Seperti sequence di PostgreSQL, ID yang sudah
+// dipakai tidak dikembalikan.
+func (s *MemoryStore) ExecTx(ctx context.Context, fn func(Store) error) error {
+	// Store di dalam transaksi tidak mengunci lagi; ExecTx bersarang berlaku seperti savepoint
+	if s.undo == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+	}
+
+	undo := &memoryUndoLog{}
+	if err := fn(&MemoryStore{mu: memoryNoLock{}, memoryData: s.memoryData, undo: undo}); err != nil {
+		undo.rollback()
+		return err
+	}
+	if s.undo != nil {
+		s.undo.steps = append(s.undo.steps, undo.steps...)
+	}
+	return nil
+}
+
+// memoryLocker adalah lock MemoryStore: *sync.RWMutex, atau memoryNoLock di dalam ExecTx.
+type memoryLocker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+}
+
+type memoryNoLock struct{}
+
+func (memoryNoLock) Lock()    {}
+func (memoryNoLock) Unlock()  {}
+func (memoryNoLock) RLock()   {}
+func (memoryNoLock) RUnlock() {}
+
+// memoryUndoLog menyimpan langkah pembatalan penulisan dalam satu transaksi, berurutan sesuai penulisannya.
+type memoryUndoLog struct {
+	steps []func()
+}
+
+func (l *memoryUndoLog) rollback() {
+	for i := len(l.steps) - 1; i >= 0; i-- {
+		l.steps[i]()
+	}
+	l.steps = nil
+}
+
+// setEntry menulis m[k] = v dan, di dalam transaksi, mencatat nilai sebelumnya.
+func setEntry[K comparable, V any](s *MemoryStore, m map[K]V, k K, v V) {
+	if s.undo != nil {
+		old, existed := m[k]
+		s.undo.steps = append(s.undo.steps, func() {
+			if existed {
+				m[k] = old
+			} else {
+				delete(m, k)
+			}
+		})
+	}
+	m[k] = v
+}
+
+// deleteEntry menghapus m[k] dan, di dalam transaksi, mencatat nilai yang dihapus.
+func deleteEntry[K comparable, V any](s *MemoryStore, m map[K]V, k K) {
+	if old, existed := m[k]; existed && s.undo != nil {
+		s.undo.steps = append(s.undo.steps, func() { m[k] = old })
+	}
+	delete(m, k)
+}
+
+// appendEntry menambahkan v ke *list dan, di dalam transaksi, mencatat panjang sebelumnya.
+func appendEntry[T any](s *MemoryStore, list *[]T, v T) {
+	if s.undo != nil {
+		n := len(*list)
+		s.undo.steps = append(s.undo.steps, func() { *list = (*list)[:n] })
+	}
+	*list = append(*list, v)
+}
+
+// replaceEntries mengganti *list dengan entries, yang harus berupa slice baru, dan di dalam transaksi mencatat
+// slice sebelumnya.
+func replaceEntries[T any](s *MemoryStore, list *[]T, entries []T) {
+	if s.undo != nil {
+		old := *list
+		s.undo.steps = append(s.undo.steps, func() { *list = old })
+	}
+	*list = entries
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errTestRollback = errors.New("rollback")
+
+func TestMemoryExecTxRollsBackOwnWrites(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	existing, err := store.CreateUser(ctx, CreateUserParams{Username: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.ExecTx(ctx, func(tx Store) error {
+		if _, err := tx.CreateUser(ctx, CreateUserParams{Username: "bob", Email: "bob@example.com"}); err != nil {
+			return err
+		}
+		if _, err := tx.CreateOrganization(ctx, "alice", existing.ID); err != nil {
+			return err
+		}
+		if _, err := tx.VerifyUserEmail(ctx, existing.ID, existing.Email); err != nil {
+			return err
+		}
+		return errTestRollback
+	})
+	if !errors.Is(err, errTestRollback) {
+		t.Fatalf("ExecTx error = %v, want %v", err, errTestRollback)
+	}
+
+	if _, err := store.GetUserByEmail(ctx, "bob@example.com"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("user created in rolled back transaction still exists: %v", err)
+	}
+	if orgs, _ := store.ListUserOrganizations(ctx, existing.ID); len(orgs) != 0 {
+		t.Errorf("organization created in rolled back transaction still exists: %v", orgs)
+	}
+	if user, _ := store.GetUser(ctx, existing.ID); user.Verified() {
+		t.Error("update in rolled back transaction was kept")
+	}
+}
+
+func TestMemoryExecTxKeepsConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	var wg sync.WaitGroup
+	err := store.ExecTx(ctx, func(tx Store) error {
+		if _, err := tx.CreateUser(ctx, CreateUserParams{Username: "bob", Email: "bob@example.com"}); err != nil {
+			return err
+		}
+		// Penulisan di luar transaksi menunggu sampai transaksi selesai dan tidak ikut dibatalkan
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := store.CreateUser(ctx, CreateUserParams{Username: "carol", Email: "carol@example.com"}); err != nil {
+				t.Error(err)
+			}
+		}()
+		time.Sleep(10 * time.Millisecond)
+		return errTestRollback
+	})
+	if !errors.Is(err, errTestRollback) {
+		t.Fatalf("ExecTx error = %v, want %v", err, errTestRollback)
+	}
+	wg.Wait()
+
+	if _, err := store.GetUserByEmail(ctx, "carol@example.com"); err != nil {
+		t.Errorf("write made outside the transaction was lost: %v", err)
+	}
+	if _, err := store.GetUserByEmail(ctx, "bob@example.com"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("user created in rolled back transaction still exists: %v", err)
+	}
+}
+
+func TestMemoryNestedExecTxRollsBackLikeSavepoint(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	err := store.ExecTx(ctx, func(tx Store) error {
+		if _, err := tx.CreateUser(ctx, CreateUserParams{Username: "bob", Email: "bob@example.com"}); err != nil {
+			return err
+		}
+		err := tx.ExecTx(ctx, func(inner Store) error {
+			if _, err := inner.CreateUser(ctx, CreateUserParams{Username: "carol", Email: "carol@example.com"}); err != nil {
+				return err
+			}
+			return errTestRollback
+		})
+		if !errors.Is(err, errTestRollback) {
+			t.Errorf("nested ExecTx error = %v, want %v", err, errTestRollback)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.GetUserByEmail(ctx, "bob@example.com"); err != nil {
+		t.Errorf("outer transaction write was lost: %v", err)
+	}
+	if _, err := store.GetUserByEmail(ctx, "carol@example.com"); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("user created in rolled back savepoint still exists: %v", err)
+	}
+}
+
+func TestMemoryExecTxRollsBackAppendsAndDeletes(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	user, _ := store.CreateUser(ctx, CreateUserParams{Username: "alice", Email: "alice@example.com"})
+	org, _ := store.CreateOrganization(ctx, "alice", user.ID)
+	empty, _ := store.CreateOrganization(ctx, "empty", user.ID)
+	site, err := store.CreateSite(ctx, CreateSiteParams{OrganizationID: org.ID, UserID: user.ID, URL: "https://example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = store.ExecTx(ctx, func(tx Store) error {
+		if _, err := tx.CreateHealthChecks(ctx, []HealthCheck{{SiteID: site.ID, IsUp: true, CheckedAt: time.Now()}}); err != nil {
+			return err
+		}
+		if err := tx.DeleteOrganization(ctx, empty.ID); err != nil {
+			return err
+		}
+		return errTestRollback
+	})
+	if !errors.Is(err, errTestRollback) {
+		t.Fatalf("ExecTx error = %v, want %v", err, errTestRollback)
+	}
+
+	if latest, _ := store.GetLatestHealthChecks(ctx, []int64{site.ID}); len(latest) != 0 {
+		t.Errorf("health check inserted in rolled back transaction still exists: %v", latest)
+	}
+	if _, err := store.GetOrganization(ctx, empty.ID); err != nil {
+		t.Errorf("organization deleted in rolled back transaction is gone: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// UserIdentity menautkan akun di identity provider OIDC (issuer + subject) ke user Go-Pulse.
+type UserIdentity struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateUserIdentityParams struct {
+	UserID  int64
+	Issuer  string
+	Subject string
+	Email   string
+}
+
+// GetUserByIdentity mengembalikan user yang ditautkan ke akun identity provider tersebut.
+func (s *SQLStore) GetUserByIdentity(ctx context.Context, issuer, subject string) (User, error) {
+	query := `SELECT ` + userColumns + ` FROM users
+              WHERE id = (SELECT user_id FROM user_identities WHERE issuer = $1 AND subject = $2)`
+
+	return scanUser(s.conn.QueryRow(ctx, query, issuer, subject))
+}
+
+func (s *SQLStore) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	query := `INSERT INTO user_identities (user_id, issuer, subject, email) VALUES ($1, $2, $3, $4)
+              RETURNING id, user_id, issuer, subject, email, created_at`
+
+	var i UserIdentity
+	err := s.conn.QueryRow(ctx, query, arg.UserID, arg.Issuer, arg.Subject, arg.Email).
+		Scan(&i.ID, &i.UserID, &i.Issuer, &i.Subject, &i.Email, &i.CreatedAt)
+	return i, err
+}
+
+// --- SQLite ---
+
+func (s *SQLiteStore) GetUserByIdentity(ctx context.Context, issuer, subject string) (User, error) {
+	query := `SELECT ` + userColumns + ` FROM users
+              WHERE id = (SELECT user_id FROM user_identities WHERE issuer = ? AND subject = ?)`
+
+	u, err := scanSQLiteUser(s.conn.QueryRowContext(ctx, query, issuer, subject))
+	return u, sqliteError(err)
+}
+
+func (s *SQLiteStore) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	i := UserIdentity{
+		UserID:    arg.UserID,
+		Issuer:    arg.Issuer,
+		Subject:   arg.Subject,
+		Email:     arg.Email,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	query := `INSERT INTO user_identities (user_id, issuer, subject, email, created_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := s.conn.ExecContext(ctx, query, i.UserID, i.Issuer, i.Subject, i.Email, sqliteTime(i.CreatedAt))
+	if err != nil {
+		return UserIdentity{}, sqliteError(err)
+	}
+	i.ID, err = res.LastInsertId()
+	return i, err
+}
+
+// --- Memory ---
+
+func (s *MemoryStore) GetUserByIdentity(ctx context.Context, issuer, subject string) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, i := range s.userIdentities {
+		if i.Issuer == issuer && i.Subject == subject {
+			if u, ok := s.users[i.UserID]; ok {
+				return u, nil
+			}
+		}
+	}
+	return User{}, ErrRecordNotFound
+}
+
+func (s *MemoryStore) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[arg.UserID]; !ok {
+		return UserIdentity{}, ErrRecordNotFound
+	}
+	for _, i := range s.userIdentities {
+		if i.Issuer == arg.Issuer && i.Subject == arg.Subject {
+			return UserIdentity{}, uniqueViolation("user_identities")
+		}
+	}
+
+	s.nextUserIdentityID++
+	i := UserIdentity{
+		ID:        s.nextUserIdentityID,
+		UserID:    arg.UserID,
+		Issuer:    arg.Issuer,
+		Subject:   arg.Subject,
+		Email:     arg.Email,
+		CreatedAt: time.Now(),
+	}
+	setEntry(s, s.userIdentities, i.ID, i)
+	return i, nil
+}
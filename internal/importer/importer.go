@@ -0,0 +1,283 @@
+// Package importer membaca daftar site dari file CSV atau sitemap.xml untuk diimpor sekaligus.
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Format sumber impor yang didukung
+const (
+	FormatCSV     = "csv"
+	FormatSitemap = "sitemap"
+)
+
+// Batas jumlah baris dan ukuran sumber dalam satu impor
+const (
+	MaxRows  = 1000
+	MaxBytes = 5 << 20
+)
+
+// Row adalah satu site dari sumber impor. Line adalah nomor baris CSV (dimulai dari 1) atau urutan <url> di sitemap.
+// Jika Err terisi, baris tersebut tidak dapat dibaca dan field lainnya mungkin kosong.
+type Row struct {
+	Line                 int
+	URL                  string
+	Name                 string
+	Tags                 []string
+	CheckIntervalSeconds int
+	Err                  error
+}
+
+// DetectFormat menebak format dari isi sumber: dokumen XML dianggap sitemap, selain itu CSV.
+func DetectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return FormatSitemap
+	}
+	return FormatCSV
+}
+
+// Parse membaca semua baris dari data sesuai format.
+func Parse(data []byte, format string) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return ParseCSV(data)
+	case FormatSitemap:
+		return ParseSitemap(data)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// Kolom CSV yang dikenali; urutan ini dipakai jika file tidak memiliki baris header
+var csvColumns = []string{"url", "name", "tags", "interval"}
+
+// ParseCSV membaca CSV dengan kolom url, name, tags dan interval (detik). Baris header bersifat opsional;
+// jika ada, urutan kolom boleh berbeda. Beberapa tag dalam satu kolom dipisahkan dengan titik koma.
+func ParseCSV(data []byte) ([]Row, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	columns := map[string]int{}
+	for i, name := range csvColumns {
+		columns[name] = i
+	}
+
+	rows := []Row{}
+	first := true
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := r.FieldPos(0)
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, err
+			}
+			rows = append(rows, Row{Line: parseErr.Line, Err: parseErr.Err})
+			continue
+		}
+		if first {
+			first = false
+			isHeader := slices.ContainsFunc(record, func(field string) bool {
+				return strings.EqualFold(strings.TrimSpace(field), "url")
+			})
+			if isHeader {
+				columns, err = csvHeader(record)
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(rows) >= MaxRows {
+			return nil, fmt.Errorf("too many rows, at most %d sites can be imported at once", MaxRows)
+		}
+		rows = append(rows, csvRow(line, record, columns))
+	}
+	return rows, nil
+}
+
+// csvHeader memetakan nama kolom ke indeksnya; kolom yang tidak dikenal ditolak agar salah ketik terlihat.
+func csvHeader(record []string) (map[string]int, error) {
+	columns := map[string]int{}
+	for i, field := range record {
+		name := strings.ToLower(strings.TrimSpace(field))
+		if name == "interval_seconds" || name == "check_interval_seconds" {
+			name = "interval"
+		}
+		if !slices.Contains(csvColumns, name) {
+			return nil, fmt.Errorf("unknown CSV column %q, expected %s", field, strings.Join(csvColumns, ", "))
+		}
+		columns[name] = i
+	}
+	return columns, nil
+}
+
+func csvRow(line int, record []string, columns map[string]int) Row {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	row := Row{Line: line, URL: field("url"), Name: field("name")}
+	if tags := field("tags"); tags != "" {
+		row.Tags = strings.Split(tags, ";")
+	}
+	if interval := field("interval"); interval != "" {
+		n, err := strconv.Atoi(interval)
+		if err != nil {
+			row.Err = errors.New("interval must be a number of seconds")
+			return row
+		}
+		row.CheckIntervalSeconds = n
+	}
+	return row
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	URLs    []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+}
+
+// ParseSitemap membaca setiap <loc> dari sitemap <urlset>. Sitemap index tidak didukung
+// karena setiap sitemap di dalamnya perlu diambil secara terpisah.
+func ParseSitemap(data []byte) ([]Row, error) {
+	var set sitemapURLSet
+	if err := xml.Unmarshal(data, &set); err != nil {
+		if strings.Contains(err.Error(), "sitemapindex") {
+			return nil, errors.New("sitemap index files are not supported, import each sitemap separately")
+		}
+		return nil, fmt.Errorf("invalid sitemap: %w", err)
+	}
+	if len(set.URLs) > MaxRows {
+		return nil, fmt.Errorf("too many rows, at most %d sites can be imported at once", MaxRows)
+	}
+
+	rows := make([]Row, 0, len(set.URLs))
+	for i, u := range set.URLs {
+		rows = append(rows, Row{Line: i + 1, URL: strings.TrimSpace(u.Loc)})
+	}
+	return rows, nil
+}
+
+// errBlockedAddress dikembalikan jika sumber impor (atau tujuan redirect-nya) mengarah ke alamat internal
+var errBlockedAddress = errors.New("source_url must point to a public address")
+
+// fetchClient hanya terhubung ke alamat publik. Pemeriksaan dilakukan di Dialer.Control, yaitu setelah DNS
+// di-resolve dan untuk setiap koneksi termasuk redirect, sehingga nama host yang mengarah ke jaringan
+// internal juga ditolak.
+var fetchClient = newFetchClient(func(addr netip.AddrPort) bool { return isPublicAddr(addr.Addr()) })
+
+func newFetchClient(allow func(netip.AddrPort) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allow(netip.AddrPortFrom(addrPort.Addr().Unmap(), addrPort.Port())) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		// Proxy sengaja tidak dipakai agar alamat tujuan sebenarnya yang diperiksa
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("stopped after 5 redirects")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// isPublicAddr melaporkan apakah ip boleh dihubungi: bukan loopback, jaringan privat, link-local,
+// multicast, maupun alamat kosong.
+func isPublicAddr(ip netip.Addr) bool {
+	return ip.IsValid() &&
+		!ip.IsUnspecified() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace (RFC 6598) dipakai jaringan carrier dan beberapa penyedia cloud untuk alamat internal
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Fetch mengunduh sumber impor dari sourceURL dengan batas waktu dan ukuran. Hanya http dan https ke
+// alamat publik yang diizinkan.
+func Fetch(ctx context.Context, sourceURL string) ([]byte, error) {
+	return fetch(ctx, fetchClient, sourceURL)
+}
+
+func fetch(ctx context.Context, client *http.Client, sourceURL string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, errors.New("source_url must use http or https")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) {
+			return nil, errBlockedAddress
+		}
+		return nil, fmt.Errorf("fetching source_url: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching source_url: unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxBytes {
+		return nil, fmt.Errorf("fetching source_url: response is larger than %d bytes", MaxBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,88 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"0.0.0.0":         false,
+		"::":              false,
+		"100.64.0.1":      false,
+		"224.0.0.1":       false,
+	}
+	for addr, want := range tests {
+		if got := isPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("isPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestFetchRejectsInternalAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("url\nhttps://example.com\n"))
+	}))
+	defer srv.Close()
+
+	if _, err := Fetch(context.Background(), srv.URL); !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("Fetch(%s) error = %v, want %v", srv.URL, err, errBlockedAddress)
+	}
+}
+
+func TestFetchRejectsRedirectToInternalAddress(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the internal server")
+	}))
+	defer internal.Close()
+	public := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusFound))
+	defer public.Close()
+
+	// Hanya server "publik" yang boleh dihubungi; redirect-nya harus ditolak saat dial
+	allowed := netip.MustParseAddrPort(public.Listener.Addr().String())
+	client := newFetchClient(func(addr netip.AddrPort) bool { return addr == allowed })
+
+	if _, err := fetch(context.Background(), client, public.URL); !errors.Is(err, errBlockedAddress) {
+		t.Fatalf("fetch error = %v, want %v", err, errBlockedAddress)
+	}
+}
+
+func TestFetchRejectsUnsupportedScheme(t *testing.T) {
+	if _, err := Fetch(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Fatal("Fetch accepted a file:// URL")
+	}
+}
+
+func TestFetchReadsAllowedSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("url\nhttps://example.com\n"))
+	}))
+	defer srv.Close()
+
+	client := newFetchClient(func(netip.AddrPort) bool { return true })
+	data, err := fetch(context.Background(), client, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := Parse(data, DetectFormat(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].URL != "https://example.com" {
+		t.Errorf("rows = %+v, want one row for https://example.com", rows)
+	}
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gopulse"
+
+// Registry menampung semua metrik Go-Pulse. Sengaja tidak memakai registry
+// default agar isi /metrics hanya berisi metrik yang didaftarkan di sini.
+var Registry = prometheus.NewRegistry()
+
+var siteLabels = []string{"site_id", "url"}
+
+// --- Metrik per site ---
+
+var (
+	SiteUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_up",
+		Help:      "Whether the last check of the site succeeded (1) or failed (0).",
+	}, siteLabels)
+
+	SiteStatusCode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "site_status_code",
+		Help:      "HTTP status code returned by the last check of the site (0 if the request failed).",
+	}, siteLabels)
+
+	SiteResponseTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "site_response_time_seconds",
+		Help:      "Response time of site checks.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	}, siteLabels)
+
+	SiteChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "site_checks_total",
+		Help:      "Total number of checks performed for the site.",
+	}, siteLabels)
+
+	SiteFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "site_check_failures_total",
+		Help:      "Total number of failed checks for the site.",
+	}, siteLabels)
+)
+
+// --- Metrik internal ---
+
+var (
+	CheckCycleDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "check_cycle_duration_seconds",
+		Help:      "Duration of a full runChecks cycle.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 10),
+	})
+
+	WorkerQueueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "worker_queue_depth",
+		Help:      "Number of sites waiting to be picked up by the worker pool.",
+	})
+
+	DBWriteErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_write_errors_total",
+		Help:      "Total number of health check results that could not be written to the database.",
+	})
+
+	SinkWriteErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sink_write_errors_total",
+		Help:      "Total number of failed batch writes to an external result sink.",
+	}, []string{"sink"})
+
+	SinkDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "sink_dropped_results_total",
+		Help:      "Total number of health check results dropped because a result sink buffer was full.",
+	}, []string{"sink"})
+)
+
+func init() {
+	Registry.MustRegister(
+		SiteUp,
+		SiteStatusCode,
+		SiteResponseTime,
+		SiteChecks,
+		SiteFailures,
+		CheckCycleDuration,
+		WorkerQueueDepth,
+		DBWriteErrors,
+		SinkWriteErrors,
+		SinkDropped,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveCheck mencatat hasil satu pengecekan site.
+func ObserveCheck(siteID int64, url string, isUp bool, statusCode int, responseTime time.Duration) {
+	labels := prometheus.Labels{"site_id": strconv.FormatInt(siteID, 10), "url": url}
+
+	up := 0.0
+	if isUp {
+		up = 1
+	}
+	SiteUp.With(labels).Set(up)
+	SiteStatusCode.With(labels).Set(float64(statusCode))
+	SiteResponseTime.With(labels).Observe(responseTime.Seconds())
+	SiteChecks.With(labels).Inc()
+	if !isUp {
+		SiteFailures.With(labels).Inc()
+	}
+}
+
+// ForgetSite menghapus semua seri metrik milik site, misalnya setelah site dihapus.
+func ForgetSite(siteID int64, url string) {
+	labels := prometheus.Labels{"site_id": strconv.FormatInt(siteID, 10), "url": url}
+
+	SiteUp.Delete(labels)
+	SiteStatusCode.Delete(labels)
+	SiteResponseTime.Delete(labels)
+	SiteChecks.Delete(labels)
+	SiteFailures.Delete(labels)
+}
+
+// RegisterConnectedClients mendaftarkan gauge jumlah client WebSocket yang terhubung.
+func RegisterConnectedClients(count func() int) {
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "ws_connected_clients",
+		Help:      "Number of WebSocket clients currently connected to the hub.",
+	}, func() float64 { return float64(count()) }))
+}
+
+// Handler mengembalikan http.Handler untuk endpoint /metrics.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,321 @@
+// Package oidc adalah client OpenID Connect minimal untuk login authorization code dengan PKCE.
+// Konfigurasi provider diambil dari discovery document issuer, sehingga issuer lokal (mis. mock issuer
+// untuk testing) dapat dipakai selama menyediakan /.well-known/openid-configuration dan JWKS.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Jarak minimum antara dua pengambilan JWKS
+const jwksRefreshInterval = 10 * time.Second
+
+// Algoritma tanda tangan ID token yang diterima
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "ES256", "ES384", "ES512"}
+
+// Config adalah konfigurasi client di identity provider.
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL harus sama dengan redirect URI yang didaftarkan di identity provider
+	RedirectURL string
+	// Scopes tambahan selain openid
+	Scopes []string
+}
+
+// Claims adalah claim ID token yang dipakai untuk mencari atau membuat user.
+type Claims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider adalah client untuk satu issuer. Discovery document dan JWKS diambil saat pertama kali dibutuhkan,
+// sehingga server tetap bisa berjalan walaupun identity provider sedang tidak dapat dihubungi.
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      map[string]any
+	keysAt    time.Time
+}
+
+// New membuat Provider untuk konfigurasi tersebut.
+func New(cfg Config) *Provider {
+	cfg.IssuerURL = strings.TrimRight(cfg.IssuerURL, "/")
+	return &Provider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// FromEnv membuat Provider dari OIDC_ISSUER, OIDC_CLIENT_ID, OIDC_CLIENT_SECRET, OIDC_REDIRECT_URL dan
+// OIDC_SCOPES (dipisah spasi, default "email profile"). Jika OIDC_ISSUER kosong, SSO tidak diaktifkan dan nil dikembalikan.
+func FromEnv() *Provider {
+	issuer := os.Getenv("OIDC_ISSUER")
+	if issuer == "" {
+		return nil
+	}
+	redirectURL := os.Getenv("OIDC_REDIRECT_URL")
+	if redirectURL == "" {
+		redirectURL = "http://localhost:8080/api/auth/oidc/callback"
+	}
+	scopes := strings.Fields(os.Getenv("OIDC_SCOPES"))
+	if len(scopes) == 0 {
+		scopes = []string{"email", "profile"}
+	}
+	return New(Config{
+		IssuerURL:    issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+	})
+}
+
+// Issuer mengembalikan URL issuer yang dikonfigurasi.
+func (p *Provider) Issuer() string {
+	return p.cfg.IssuerURL
+}
+
+// NewPKCE membuat code verifier acak beserta code challenge S256-nya (RFC 7636).
+func NewPKCE() (verifier, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// AuthCodeURL mengembalikan URL halaman login identity provider.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", p.cfg.ClientID)
+	v.Set("redirect_uri", p.cfg.RedirectURL)
+	v.Set("scope", strings.Join(append([]string{"openid"}, p.cfg.Scopes...), " "))
+	v.Set("state", state)
+	v.Set("nonce", nonce)
+	v.Set("code_challenge", codeChallenge)
+	v.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return d.AuthorizationEndpoint + sep + v.Encode(), nil
+}
+
+// Exchange menukar authorization code dengan token, lalu memverifikasi ID token-nya: tanda tangan, issuer,
+// audience, masa berlaku dan nonce.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (Claims, error) {
+	d, err := p.getDiscovery(ctx)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Claims{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// client_secret_basic (RFC 6749 bagian 2.3.1): client ID dan secret di-URL-encode lebih dulu
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	var token struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return Claims{}, fmt.Errorf("token request failed: %w", err)
+	}
+	if token.Error != "" {
+		return Claims{}, fmt.Errorf("token request failed: %s %s", token.Error, token.ErrorDescription)
+	}
+	if token.IDToken == "" {
+		return Claims{}, errors.New("token response does not contain an id_token")
+	}
+	return p.verifyIDToken(ctx, token.IDToken, nonce)
+}
+
+func (p *Provider) verifyIDToken(ctx context.Context, rawIDToken, nonce string) (Claims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	},
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.cfg.IssuerURL),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return Claims{}, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if got, _ := claims["nonce"].(string); got == "" || got != nonce {
+		return Claims{}, errors.New("invalid id_token: nonce does not match")
+	}
+
+	c := Claims{Issuer: p.cfg.IssuerURL}
+	c.Subject, _ = claims["sub"].(string)
+	c.Email, _ = claims["email"].(string)
+	c.Name, _ = claims["name"].(string)
+	c.PreferredUsername, _ = claims["preferred_username"].(string)
+	// Beberapa identity provider mengirim email_verified sebagai string
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		c.EmailVerified = v
+	case string:
+		c.EmailVerified = v == "true"
+	}
+	if c.Subject == "" {
+		return Claims{}, errors.New("invalid id_token: missing sub claim")
+	}
+	return c, nil
+}
+
+// key mengembalikan public key dengan kid tersebut. JWKS diambil ulang jika kid belum dikenal (mis. setelah
+// rotasi key), paling sering sekali per jwksRefreshInterval agar token palsu tidak membanjiri identity provider.
+func (p *Provider) key(ctx context.Context, kid string) (any, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKeyLocked(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if err := p.fetchKeysLocked(ctx); err != nil {
+		return nil, err
+	}
+	if key, ok := p.lookupKeyLocked(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *Provider) lookupKeyLocked(kid string) (any, bool) {
+	if kid != "" {
+		key, ok := p.keys[kid]
+		return key, ok
+	}
+	// Tanpa kid, key hanya dapat dipilih jika JWKS berisi tepat satu key
+	if len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Provider) fetchKeysLocked(ctx context.Context) error {
+	d, err := p.getDiscoveryLocked(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.JWKSURI, nil)
+	if err != nil {
+		return err
+	}
+	var set jwkSet
+	if err := p.doJSON(req, &set); err != nil {
+		return fmt.Errorf("fetching JWKS failed: %w", err)
+	}
+	keys, err := set.publicKeys()
+	if err != nil {
+		return err
+	}
+	p.keys, p.keysAt = keys, time.Now()
+	return nil
+}
+
+func (p *Provider) getDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.getDiscoveryLocked(ctx)
+}
+
+func (p *Provider) getDiscoveryLocked(ctx context.Context) (*discoveryDocument, error) {
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.IssuerURL+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	var d discoveryDocument
+	if err := p.doJSON(req, &d); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimRight(d.Issuer, "/") != p.cfg.IssuerURL {
+		return nil, fmt.Errorf("OIDC discovery failed: issuer %q does not match %q", d.Issuer, p.cfg.IssuerURL)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery failed: document is missing required endpoints")
+	}
+	p.discovery = &d
+	return p.discovery, nil
+}
+
+// doJSON menjalankan request dan men-decode body JSON. Respons 4xx dari token endpoint tetap di-decode
+// karena berisi field error.
+func (p *Provider) doJSON(req *http.Request, v any) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 500 || (resp.StatusCode >= 300 && !slices.Contains([]int{400, 401}, resp.StatusCode)) {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("invalid JSON response: %w", err)
+	}
+	return nil
+}
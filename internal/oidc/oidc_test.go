@@ -0,0 +1,176 @@
+package oidc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tajri15/go-pulse-monitoring/internal/oidc/oidctest"
+)
+
+const testRedirectURL = "http://localhost:8080/api/auth/oidc/callback"
+
+func newTestProvider(t *testing.T) (*oidctest.Issuer, *Provider) {
+	t.Helper()
+	issuer := oidctest.NewIssuer(t, "go-pulse", "s3cret&")
+	issuer.SetClaims(jwt.MapClaims{"sub": "user-1", "email": "alice@example.com", "email_verified": true, "preferred_username": "alice"})
+	provider := New(Config{
+		IssuerURL:    issuer.URL,
+		ClientID:     issuer.ClientID,
+		ClientSecret: issuer.ClientSecret,
+		RedirectURL:  testRedirectURL,
+		Scopes:       []string{"email"},
+	})
+	return issuer, provider
+}
+
+// authorize menjalankan login di issuer dan mengembalikan authorization code, state, nonce serta PKCE verifier.
+func authorize(t *testing.T, issuer *oidctest.Issuer, provider *Provider) (code, state, nonce, verifier string) {
+	t.Helper()
+	state, nonce = "state-123", "nonce-456"
+	verifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	if !strings.HasPrefix(authURL, issuer.URL+"/authorize?") || !strings.Contains(authURL, "scope=openid+email") {
+		t.Errorf("AuthCodeURL = %s", authURL)
+	}
+	redirect, err := issuer.Authorize(authURL)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if redirect.Query().Get("state") != state {
+		t.Errorf("issuer returned state %q, want %q", redirect.Query().Get("state"), state)
+	}
+	return redirect.Query().Get("code"), state, nonce, verifier
+}
+
+func TestExchangeReturnsVerifiedClaims(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	code, _, nonce, verifier := authorize(t, issuer, provider)
+
+	claims, err := provider.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	want := Claims{Issuer: issuer.URL, Subject: "user-1", Email: "alice@example.com", EmailVerified: true, PreferredUsername: "alice"}
+	if claims != want {
+		t.Errorf("claims = %+v, want %+v", claims, want)
+	}
+}
+
+func TestExchangeRejectsInvalidResponses(t *testing.T) {
+	tests := map[string]struct {
+		setup func(t *testing.T, issuer *oidctest.Issuer)
+		// exchange dapat mengganti code, verifier atau nonce yang dikirim client
+		exchange func(code, verifier, nonce string) (string, string, string)
+		wantErr  string
+	}{
+		"nonce mismatch": {
+			exchange: func(code, verifier, nonce string) (string, string, string) { return code, verifier, "other-nonce" },
+			wantErr:  "nonce does not match",
+		},
+		"wrong PKCE verifier": {
+			exchange: func(code, verifier, nonce string) (string, string, string) { return code, "wrong-verifier", nonce },
+			wantErr:  "PKCE verification failed",
+		},
+		"unknown code": {
+			exchange: func(code, verifier, nonce string) (string, string, string) { return "forged", verifier, nonce },
+			wantErr:  "invalid_grant",
+		},
+		"untrusted signing key": {
+			setup:   func(t *testing.T, issuer *oidctest.Issuer) { issuer.UseUntrustedKey(t) },
+			wantErr: "invalid id_token",
+		},
+		"wrong audience": {
+			setup: func(t *testing.T, issuer *oidctest.Issuer) {
+				issuer.SetClaims(jwt.MapClaims{"sub": "user-1", "aud": "another-client"})
+			},
+			wantErr: "invalid id_token",
+		},
+		"wrong issuer": {
+			setup: func(t *testing.T, issuer *oidctest.Issuer) {
+				issuer.SetClaims(jwt.MapClaims{"sub": "user-1", "iss": "https://evil.example.com"})
+			},
+			wantErr: "invalid id_token",
+		},
+		"expired": {
+			setup: func(t *testing.T, issuer *oidctest.Issuer) {
+				issuer.SetClaims(jwt.MapClaims{"sub": "user-1", "exp": time.Now().Add(-time.Hour).Unix()})
+			},
+			wantErr: "invalid id_token",
+		},
+		"missing subject": {
+			setup: func(t *testing.T, issuer *oidctest.Issuer) {
+				issuer.SetClaims(jwt.MapClaims{"email": "alice@example.com"})
+			},
+			wantErr: "missing sub claim",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			issuer, provider := newTestProvider(t)
+			if tt.setup != nil {
+				tt.setup(t, issuer)
+			}
+			code, _, nonce, verifier := authorize(t, issuer, provider)
+			if tt.exchange != nil {
+				code, verifier, nonce = tt.exchange(code, verifier, nonce)
+			}
+
+			_, err := provider.Exchange(context.Background(), code, verifier, nonce)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Exchange error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsReusedCode(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	code, _, nonce, verifier := authorize(t, issuer, provider)
+
+	if _, err := provider.Exchange(context.Background(), code, verifier, nonce); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if _, err := provider.Exchange(context.Background(), code, verifier, nonce); err == nil {
+		t.Fatal("authorization code was accepted twice")
+	}
+}
+
+func TestDiscoveryRejectsIssuerMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"issuer":"https://evil.example.com","authorization_endpoint":"https://evil.example.com/authorize",` +
+			`"token_endpoint":"https://evil.example.com/token","jwks_uri":"https://evil.example.com/jwks"}`))
+	}))
+	defer srv.Close()
+
+	provider := New(Config{IssuerURL: srv.URL, ClientID: "go-pulse", RedirectURL: testRedirectURL})
+	_, err := provider.AuthCodeURL(context.Background(), "state", "nonce", "challenge")
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("AuthCodeURL error = %v, want issuer mismatch", err)
+	}
+}
+
+func TestEmailVerifiedAsString(t *testing.T) {
+	issuer, provider := newTestProvider(t)
+	issuer.SetClaims(jwt.MapClaims{"sub": "user-1", "email": "alice@example.com", "email_verified": "true"})
+	code, _, nonce, verifier := authorize(t, issuer, provider)
+
+	claims, err := provider.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if !claims.EmailVerified {
+		t.Error("email_verified \"true\" was not accepted")
+	}
+}
@@ -0,0 +1,167 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName adalah nama instrumentation scope untuk semua tracer dan meter Go-Pulse.
+const ScopeName = "github.com/tajri15/go-pulse-monitoring"
+
+// Config berisi pengaturan ekspor OTLP/HTTP.
+type Config struct {
+	// Endpoint adalah URL collector OTLP/HTTP, misalnya http://localhost:4318.
+	// Jika kosong, telemetry tidak diaktifkan.
+	Endpoint string
+	// InstanceID mengidentifikasi instance checker ini pada resource attributes.
+	InstanceID string
+	// ExportInterval adalah interval push metrik ke collector.
+	ExportInterval time.Duration
+}
+
+// ConfigFromEnv membaca konfigurasi dari OTEL_EXPORTER_OTLP_ENDPOINT dan CHECKER_INSTANCE_ID.
+func ConfigFromEnv() Config {
+	instanceID := os.Getenv("CHECKER_INSTANCE_ID")
+	if instanceID == "" {
+		instanceID, _ = os.Hostname()
+	}
+	return Config{
+		Endpoint:       os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"),
+		InstanceID:     instanceID,
+		ExportInterval: 30 * time.Second,
+	}
+}
+
+// Setup memasang meter dan tracer provider global yang mengirim data ke collector OTLP/HTTP.
+// Fungsi shutdown yang dikembalikan harus dipanggil saat aplikasi berhenti agar data terakhir terkirim.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+		resource.WithAttributes(
+			semconv.ServiceName("go-pulse"),
+			semconv.ServiceInstanceID(cfg.InstanceID),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Seperti OTEL_EXPORTER_OTLP_ENDPOINT, Endpoint adalah URL dasar; path tiap sinyal ditambahkan di sini
+	metricsURL, err := url.JoinPath(cfg.Endpoint, "v1/metrics")
+	if err != nil {
+		return nil, err
+	}
+	tracesURL, err := url.JoinPath(cfg.Endpoint, "v1/traces")
+	if err != nil {
+		return nil, err
+	}
+
+	metricExporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(metricsURL))
+	if err != nil {
+		return nil, err
+	}
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(res),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(cfg.ExportInterval))),
+	)
+
+	traceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(tracesURL))
+	if err != nil {
+		return nil, errors.Join(err, meterProvider.Shutdown(ctx))
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithResource(res),
+		sdktrace.WithBatcher(traceExporter),
+	)
+
+	// Instrumen dan tracer yang dibuat sebelumnya dari provider global akan ikut diarahkan ke provider ini
+	otel.SetMeterProvider(meterProvider)
+	otel.SetTracerProvider(tracerProvider)
+
+	log.Printf("Exporting OTLP telemetry to %s as instance %s", cfg.Endpoint, cfg.InstanceID)
+	return func(ctx context.Context) error {
+		return errors.Join(meterProvider.Shutdown(ctx), tracerProvider.Shutdown(ctx))
+	}, nil
+}
+
+// --- Instrumen metrik ---
+
+var (
+	checksCounter   metric.Int64Counter
+	failuresCounter metric.Int64Counter
+	responseTime    metric.Float64Histogram
+)
+
+func init() {
+	meter := otel.Meter(ScopeName)
+
+	// Error hanya mungkin terjadi jika nama instrumen tidak valid
+	var err error
+	checksCounter, err = meter.Int64Counter("gopulse.site.checks",
+		metric.WithDescription("Total number of checks performed for a site."))
+	if err != nil {
+		panic(err)
+	}
+	failuresCounter, err = meter.Int64Counter("gopulse.site.check_failures",
+		metric.WithDescription("Total number of failed checks for a site."))
+	if err != nil {
+		panic(err)
+	}
+	responseTime, err = meter.Float64Histogram("gopulse.site.response_time",
+		metric.WithDescription("Response time of site checks."),
+		metric.WithUnit("s"))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Tracer mengembalikan tracer Go-Pulse dari provider global.
+func Tracer() trace.Tracer {
+	return otel.Tracer(ScopeName)
+}
+
+// SiteAttributes mengembalikan atribut standar untuk sebuah site. ownerID adalah user yang membuat site dan
+// orgID organisasi pemiliknya.
+func SiteAttributes(siteID int64, url string, ownerID, orgID int64) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("site.id", siteID),
+		semconv.URLFull(url),
+		attribute.Int64("site.owner_id", ownerID),
+		attribute.Int64("site.organization_id", orgID),
+	}
+}
+
+// RecordCheck mencatat hasil satu pengecekan site ke instrumen OTLP.
+func RecordCheck(ctx context.Context, attrs []attribute.KeyValue, isUp bool, statusCode int, duration time.Duration) {
+	opt := metric.WithAttributes(append([]attribute.KeyValue{
+		attribute.Bool("site.up", isUp),
+		attribute.Int("http.response.status_code", statusCode),
+	}, attrs...)...)
+
+	checksCounter.Add(ctx, 1, opt)
+	if !isUp {
+		failuresCounter.Add(ctx, 1, opt)
+	}
+	responseTime.Record(ctx, duration.Seconds(), opt)
+}
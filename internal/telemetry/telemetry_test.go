@@ -0,0 +1,66 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// TestSetupExportsToSignalPaths memastikan metrik dan trace dikirim ke path OTLP/HTTP standar di bawah
+// endpoint dasar, seperti yang diharapkan collector.
+func TestSetupExportsToSignalPaths(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[string]int)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.Method+" "+r.URL.Path]++
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer collector.Close()
+
+	ctx := context.Background()
+	shutdown, err := Setup(ctx, Config{
+		Endpoint:       collector.URL + "/",
+		InstanceID:     "test",
+		ExportInterval: time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	attrs := SiteAttributes(1, "https://example.com", 2, 3)
+	_, span := Tracer().Start(ctx, "check")
+	RecordCheck(ctx, attrs, true, http.StatusOK, 150*time.Millisecond)
+	span.SetAttributes(attribute.Bool("site.up", true))
+	span.End()
+
+	// Shutdown mengirim data yang masih tertahan
+	if err := shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"POST /v1/metrics", "POST /v1/traces"} {
+		if received[path] == 0 {
+			t.Errorf("collector did not receive %s; got %v", path, received)
+		}
+	}
+}
+
+func TestSetupWithoutEndpointIsNoop(t *testing.T) {
+	shutdown, err := Setup(context.Background(), Config{})
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+}
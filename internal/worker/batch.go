@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/metrics"
+)
+
+// checkResult adalah hasil satu pengecekan beserta site yang diperiksa.
+type checkResult struct {
+	Site  db.Site
+	Check db.HealthCheck
+}
+
+// resultBatcher menampung hasil pengecekan lalu menyimpannya ke database
+// secara bertahap, baik ketika buffer penuh maupun ketika interval flush tercapai.
+type resultBatcher struct {
+	store         db.Store
+	maxSize       int
+	flushInterval time.Duration
+	// onFlush dipanggil dengan hasil yang berhasil disimpan
+	onFlush func([]checkResult)
+
+	in chan checkResult
+}
+
+func newResultBatcher(store db.Store, maxSize int, flushInterval time.Duration, onFlush func([]checkResult)) *resultBatcher {
+	return &resultBatcher{
+		store:         store,
+		maxSize:       maxSize,
+		flushInterval: flushInterval,
+		onFlush:       onFlush,
+		in:            make(chan checkResult, maxSize),
+	}
+}
+
+// Add memasukkan satu hasil ke buffer. Akan menunggu jika buffer masuk sedang penuh.
+func (b *resultBatcher) Add(result checkResult) {
+	b.in <- result
+}
+
+// Run memproses hasil yang masuk sampai channel ditutup.
+func (b *resultBatcher) Run() {
+	ticker := time.NewTicker(b.flushInterval)
+	defer ticker.Stop()
+
+	buffer := make([]checkResult, 0, b.maxSize)
+	for {
+		select {
+		case result, ok := <-b.in:
+			if !ok {
+				b.flush(buffer)
+				return
+			}
+			buffer = append(buffer, result)
+			if len(buffer) >= b.maxSize {
+				b.flush(buffer)
+				buffer = make([]checkResult, 0, b.maxSize)
+			}
+		case <-ticker.C:
+			if len(buffer) > 0 {
+				b.flush(buffer)
+				buffer = make([]checkResult, 0, b.maxSize)
+			}
+		}
+	}
+}
+
+func (b *resultBatcher) flush(buffer []checkResult) {
+	if len(buffer) == 0 {
+		return
+	}
+
+	checks := make([]db.HealthCheck, len(buffer))
+	for i, result := range buffer {
+		checks[i] = result.Check
+	}
+
+	count, err := b.store.CreateHealthChecks(context.Background(), checks)
+	if err != nil {
+		// Satu baris yang gagal (misalnya site yang baru dihapus) menggagalkan seluruh COPY, jadi
+		// simpan ulang satu per satu agar hasil lainnya tidak ikut hilang
+		log.Printf("Error saving batch of %d health check results, retrying one by one: %v", len(checks), err)
+		buffer = b.saveEach(buffer)
+		count = int64(len(buffer))
+	}
+	log.Printf("Successfully saved %d health check results", count)
+
+	if b.onFlush != nil && len(buffer) > 0 {
+		b.onFlush(buffer)
+	}
+}
+
+// saveEach menyimpan hasil satu per satu dan mengembalikan hasil yang berhasil disimpan.
+func (b *resultBatcher) saveEach(buffer []checkResult) []checkResult {
+	saved := buffer[:0:0]
+	for _, result := range buffer {
+		if _, err := b.store.CreateHealthChecks(context.Background(), []db.HealthCheck{result.Check}); err != nil {
+			log.Printf("Error saving health check result for site %d: %v", result.Check.SiteID, err)
+			metrics.DBWriteErrors.Inc()
+			continue
+		}
+		saved = append(saved, result)
+	}
+	return saved
+}
@@ -0,0 +1,170 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// createTestSite membuat user, organisasi, dan satu site untuk menampung health check.
+func createTestSite(tb testing.TB, store db.Store) db.Site {
+	tb.Helper()
+	return createTestSiteWithURL(tb, store, "https://example.com")
+}
+
+// createTestSiteWithURL seperti createTestSite, tetapi dengan URL site yang ditentukan.
+func createTestSiteWithURL(tb testing.TB, store db.Store, url string) db.Site {
+	tb.Helper()
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username:     fmt.Sprintf("batch%d", suffix),
+		Email:        fmt.Sprintf("batch%d@example.com", suffix),
+		PasswordHash: "x",
+	})
+	if err != nil {
+		tb.Fatalf("CreateUser: %v", err)
+	}
+	org, err := store.CreateOrganization(ctx, "Batch", user.ID)
+	if err != nil {
+		tb.Fatalf("CreateOrganization: %v", err)
+	}
+	site, err := store.CreateSite(ctx, db.CreateSiteParams{
+		OrganizationID: org.ID,
+		UserID:         user.ID,
+		URL:            url,
+		Name:           "example",
+	})
+	if err != nil {
+		tb.Fatalf("CreateSite: %v", err)
+	}
+	return site
+}
+
+func testResult(site db.Site) checkResult {
+	return checkResult{
+		Site:  site,
+		Check: db.HealthCheck{SiteID: site.ID, StatusCode: 200, ResponseTimeMs: 42, IsUp: true, CheckedAt: time.Now()},
+	}
+}
+
+func TestResultBatcherFlushFallsBackToSingleRows(t *testing.T) {
+	store := db.NewMemoryStore()
+	site := createTestSite(t, store)
+
+	var flushed []checkResult
+	batcher := newResultBatcher(store, 10, time.Minute, func(results []checkResult) {
+		flushed = append(flushed, results...)
+	})
+
+	// Site 0 tidak ada sehingga penyimpanan batch gagal; dua hasil lainnya tetap harus tersimpan
+	missing := testResult(db.Site{ID: 0})
+	batcher.flush([]checkResult{testResult(site), missing, testResult(site)})
+
+	if len(flushed) != 2 {
+		t.Fatalf("onFlush got %d results, want 2", len(flushed))
+	}
+	for _, result := range flushed {
+		if result.Check.SiteID != site.ID {
+			t.Errorf("onFlush got result for site %d, want %d", result.Check.SiteID, site.ID)
+		}
+	}
+
+	uptime, err := store.GetSiteUptime(context.Background(), site.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetSiteUptime: %v", err)
+	}
+	if uptime.TotalChecks != 2 {
+		t.Errorf("stored %d health checks, want 2", uptime.TotalChecks)
+	}
+}
+
+func TestResultBatcherFlushSkipsOnFlushWhenNothingSaved(t *testing.T) {
+	store := db.NewMemoryStore()
+	called := false
+	batcher := newResultBatcher(store, 10, time.Minute, func([]checkResult) { called = true })
+
+	batcher.flush([]checkResult{testResult(db.Site{ID: 0})})
+
+	if called {
+		t.Error("onFlush called although no result was saved")
+	}
+}
+
+// benchmarkStores mengembalikan store untuk benchmark penyimpanan: SQLite di direktori sementara, dan
+// PostgreSQL jika TEST_POSTGRES_SOURCE diisi (skemanya harus sudah dimigrasi).
+func benchmarkStores(b *testing.B) map[string]db.Store {
+	b.Helper()
+	ctx := context.Background()
+	stores := make(map[string]db.Store)
+
+	sqliteStore, err := db.OpenSQLite(ctx, filepath.Join(b.TempDir(), "bench.db"))
+	if err != nil {
+		b.Fatalf("OpenSQLite: %v", err)
+	}
+	b.Cleanup(func() { sqliteStore.Close() })
+	migrator, err := sqliteStore.Migrator()
+	if err != nil {
+		b.Fatalf("Migrator: %v", err)
+	}
+	if _, err := migrator.Up(ctx); err != nil {
+		b.Fatalf("migrate: %v", err)
+	}
+	stores["sqlite"] = sqliteStore
+
+	if source := os.Getenv("TEST_POSTGRES_SOURCE"); source != "" {
+		pool, err := pgxpool.New(ctx, source)
+		if err != nil {
+			b.Fatalf("connect to postgres: %v", err)
+		}
+		b.Cleanup(pool.Close)
+		stores["postgres"] = db.NewStore(pool)
+	}
+	return stores
+}
+
+// BenchmarkSaveHealthChecks membandingkan penyimpanan satu batch hasil sekaligus (COPY di PostgreSQL)
+// dengan penyimpanan satu per satu seperti sebelum batching.
+func BenchmarkSaveHealthChecks(b *testing.B) {
+	const batchSize = 500
+
+	for name, store := range benchmarkStores(b) {
+		site := createTestSite(b, store)
+		checks := make([]db.HealthCheck, batchSize)
+		for i := range checks {
+			checks[i] = testResult(site).Check
+		}
+
+		b.Run(name+"/single", func(b *testing.B) {
+			for b.Loop() {
+				for _, hc := range checks {
+					_, err := store.CreateHealthCheck(context.Background(), db.CreateHealthCheckParams{
+						SiteID:         hc.SiteID,
+						StatusCode:     hc.StatusCode,
+						ResponseTimeMs: hc.ResponseTimeMs,
+						IsUp:           hc.IsUp,
+					})
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "rows/s")
+		})
+		b.Run(name+"/batch", func(b *testing.B) {
+			for b.Loop() {
+				if _, err := store.CreateHealthChecks(context.Background(), checks); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(b.N*batchSize)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
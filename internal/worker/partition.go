@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// PartitionMaintainer menjaga partisi bulanan health_checks: membuat partisi
+// untuk bulan-bulan ke depan dan menghapus partisi yang sudah melewati masa retensi.
+type PartitionMaintainer struct {
+	store db.PartitionStore
+	// Jumlah bulan ke depan yang partisinya dibuat lebih awal
+	monthsAhead int
+	// Jumlah bulan data yang disimpan; 0 berarti data tidak pernah dihapus
+	retentionMonths int
+}
+
+// NewPartitionMaintainer membuat instance PartitionMaintainer baru.
+func NewPartitionMaintainer(store db.PartitionStore, monthsAhead, retentionMonths int) *PartitionMaintainer {
+	return &PartitionMaintainer{
+		store:           store,
+		monthsAhead:     monthsAhead,
+		retentionMonths: retentionMonths,
+	}
+}
+
+// Start menjalankan maintenance sekali saat startup lalu setiap 24 jam.
+func (m *PartitionMaintainer) Start() {
+	log.Println("Starting partition maintenance worker...")
+	m.runMaintenance()
+
+	ticker := time.NewTicker(24 * time.Hour)
+	for range ticker.C {
+		m.runMaintenance()
+	}
+}
+
+func (m *PartitionMaintainer) runMaintenance() {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	// Dihitung dari awal bulan: tanggal 31 ditambah satu bulan bisa melompati bulan berikutnya
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// 1. Pastikan partisi untuk bulan ini dan beberapa bulan ke depan sudah ada
+	for i := 0; i <= m.monthsAhead; i++ {
+		month := thisMonth.AddDate(0, i, 0)
+		if err := m.store.CreateHealthCheckPartition(ctx, month); err != nil {
+			log.Printf("Error creating partition %s: %v", db.HealthCheckPartitionName(month), err)
+		}
+	}
+
+	if m.retentionMonths <= 0 {
+		return
+	}
+
+	// 2. Hapus partisi yang seluruh isinya lebih tua dari masa retensi
+	partitions, err := m.store.ListHealthCheckPartitions(ctx)
+	if err != nil {
+		log.Printf("Error listing partitions: %v", err)
+		return
+	}
+
+	cutoff := thisMonth.AddDate(0, -m.retentionMonths, 0)
+	for _, month := range partitions {
+		if !month.Before(cutoff) {
+			continue
+		}
+		if err := m.store.DropHealthCheckPartition(ctx, month); err != nil {
+			log.Printf("Error dropping partition %s: %v", db.HealthCheckPartitionName(month), err)
+			continue
+		}
+		log.Printf("Dropped expired partition %s", db.HealthCheckPartitionName(month))
+	}
+}
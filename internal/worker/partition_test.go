@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakePartitionStore menyimpan awal bulan dari partisi yang ada.
+type fakePartitionStore struct {
+	months []time.Time
+}
+
+func (s *fakePartitionStore) CreateHealthCheckPartition(ctx context.Context, t time.Time) error {
+	if !slices.ContainsFunc(s.months, t.Equal) {
+		s.months = append(s.months, t)
+	}
+	return nil
+}
+
+func (s *fakePartitionStore) ListHealthCheckPartitions(ctx context.Context) ([]time.Time, error) {
+	return slices.Clone(s.months), nil
+}
+
+func (s *fakePartitionStore) DropHealthCheckPartition(ctx context.Context, t time.Time) error {
+	s.months = slices.DeleteFunc(s.months, t.Equal)
+	return nil
+}
+
+func TestPartitionMaintenance(t *testing.T) {
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	month := func(offset int) time.Time { return thisMonth.AddDate(0, offset, 0) }
+
+	tests := []struct {
+		name            string
+		retentionMonths int
+		want            []time.Time
+	}{
+		{"keep forever", 0, []time.Time{month(-14), month(-13), month(-12), month(-1), month(0), month(1), month(2)}},
+		{"keep 12 months", 12, []time.Time{month(-12), month(-1), month(0), month(1), month(2)}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fakePartitionStore{months: []time.Time{month(-14), month(-13), month(-12), month(-1), month(0)}}
+			m := NewPartitionMaintainer(store, 2, tc.retentionMonths)
+
+			// Dijalankan dua kali: maintenance yang berulang tidak membuat partisi ganda
+			m.runMaintenance()
+			m.runMaintenance()
+
+			slices.SortFunc(store.months, time.Time.Compare)
+			if !slices.EqualFunc(store.months, tc.want, time.Time.Equal) {
+				t.Errorf("partitions = %v, want %v", store.months, tc.want)
+			}
+		})
+	}
+}
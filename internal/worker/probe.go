@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// probeSite melakukan request HTTP ke site dan mengembalikan hasilnya.
+// Setiap probe dicatat sebagai span "probe" dengan child span dns, connect, tls dan request.
+func probeSite(ctx context.Context, client *http.Client, site db.Site) (db.HealthCheck, error) {
+	timeout := time.Duration(site.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = db.DefaultTimeoutSeconds * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	siteAttrs := telemetry.SiteAttributes(site.ID, site.URL, site.UserID, site.OrganizationID)
+	ctx, span := telemetry.Tracer().Start(ctx, "probe", trace.WithAttributes(siteAttrs...))
+	defer span.End()
+
+	startTime := time.Now()
+	result := db.HealthCheck{
+		SiteID:    site.ID,
+		CheckedAt: startTime.UTC(),
+	}
+
+	pt := newProbeTrace(ctx)
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, pt.clientTrace()), http.MethodGet, site.URL, nil)
+	if err == nil {
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err == nil {
+			result.IsUp = resp.StatusCode >= 200 && resp.StatusCode < 300
+			result.StatusCode = resp.StatusCode
+			resp.Body.Close()
+		}
+	}
+	pt.end(err)
+
+	duration := time.Since(startTime)
+	result.ResponseTimeMs = int(duration.Milliseconds())
+
+	span.SetAttributes(attribute.Bool("site.up", result.IsUp), attribute.Int("http.response.status_code", result.StatusCode))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	telemetry.RecordCheck(ctx, siteAttrs, result.IsUp, result.StatusCode, duration)
+
+	return result, err
+}
+
+// probeTrace membuat child span dari event httptrace selama satu request.
+type probeTrace struct {
+	ctx    context.Context
+	tracer trace.Tracer
+
+	mu sync.Mutex
+	// Koneksi bisa dicoba ke beberapa alamat sekaligus, jadi span connect disimpan per alamat
+	connectSpans map[string]trace.Span
+	dnsSpan      trace.Span
+	tlsSpan      trace.Span
+	requestSpan  trace.Span
+}
+
+func newProbeTrace(ctx context.Context) *probeTrace {
+	return &probeTrace{
+		ctx:          ctx,
+		tracer:       telemetry.Tracer(),
+		connectSpans: make(map[string]trace.Span),
+	}
+}
+
+func (p *probeTrace) start(name string, attrs ...attribute.KeyValue) trace.Span {
+	_, span := p.tracer.Start(p.ctx, name, trace.WithAttributes(attrs...))
+	return span
+}
+
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (p *probeTrace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.dnsSpan = p.start("dns", attribute.String("dns.host", info.Host))
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			endSpan(p.dnsSpan, info.Err)
+			p.dnsSpan = nil
+		},
+		ConnectStart: func(network, addr string) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.connectSpans[addr] = p.start("connect", attribute.String("network.transport", network), attribute.String("network.peer.address", addr))
+		},
+		ConnectDone: func(network, addr string, err error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			endSpan(p.connectSpans[addr], err)
+			delete(p.connectSpans, addr)
+		},
+		TLSHandshakeStart: func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.tlsSpan = p.start("tls")
+		},
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			endSpan(p.tlsSpan, err)
+			p.tlsSpan = nil
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			p.requestSpan = p.start("request", attribute.Bool("http.connection.reused", info.Reused))
+		},
+	}
+}
+
+// end menutup semua span yang masih terbuka setelah request selesai.
+func (p *probeTrace) end(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	endSpan(p.dnsSpan, err)
+	endSpan(p.tlsSpan, err)
+	for addr, span := range p.connectSpans {
+		endSpan(span, err)
+		delete(p.connectSpans, addr)
+	}
+	endSpan(p.requestSpan, err)
+	p.dnsSpan, p.tlsSpan, p.requestSpan = nil, nil, nil
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// ArchivePurger menghapus permanen site yang sudah diarsipkan lebih lama dari masa retensi,
+// beserta seluruh riwayat health check-nya.
+type ArchivePurger struct {
+	store     db.Store
+	retention time.Duration
+}
+
+// ArchiveRetentionFromEnv membaca masa retensi site yang diarsipkan dari ARCHIVE_RETENTION_DAYS (default 30 hari).
+func ArchiveRetentionFromEnv() (time.Duration, error) {
+	days := 30
+	if v := os.Getenv("ARCHIVE_RETENTION_DAYS"); v != "" {
+		var err error
+		days, err = strconv.Atoi(v)
+		// Nilai 0 atau negatif akan menghapus site begitu diarsipkan
+		if err != nil || days < 1 {
+			return 0, fmt.Errorf("invalid ARCHIVE_RETENTION_DAYS %q: must be a positive number of days", v)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// NewArchivePurger membuat instance ArchivePurger baru.
+func NewArchivePurger(store db.Store, retention time.Duration) *ArchivePurger {
+	return &ArchivePurger{
+		store:     store,
+		retention: retention,
+	}
+}
+
+// Start menjalankan purge sekali saat startup lalu setiap jam.
+func (p *ArchivePurger) Start() {
+	log.Println("Starting archived site purge worker...")
+	p.runPurge()
+
+	ticker := time.NewTicker(1 * time.Hour)
+	for range ticker.C {
+		p.runPurge()
+	}
+}
+
+func (p *ArchivePurger) runPurge() {
+	purged, err := p.store.PurgeArchivedSites(context.Background(), time.Now().Add(-p.retention))
+	if err != nil {
+		log.Printf("Error purging archived sites: %v", err)
+		return
+	}
+	if purged > 0 {
+		log.Printf("Purged %d archived site(s)", purged)
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+// recordingPurgeStore mencatat batas waktu yang diteruskan ke PurgeArchivedSites.
+type recordingPurgeStore struct {
+	db.Store
+	archivedBefore []time.Time
+}
+
+func (s *recordingPurgeStore) PurgeArchivedSites(ctx context.Context, archivedBefore time.Time) (int64, error) {
+	s.archivedBefore = append(s.archivedBefore, archivedBefore)
+	return 0, nil
+}
+
+func TestArchiveRetentionFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 30 * 24 * time.Hour, false},
+		{"7", 7 * 24 * time.Hour, false},
+		{"0", 0, true},
+		{"-1", 0, true},
+		{"seven", 0, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.value, func(t *testing.T) {
+			t.Setenv("ARCHIVE_RETENTION_DAYS", tc.value)
+			got, err := ArchiveRetentionFromEnv()
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("retention = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestArchivePurgerCutoff(t *testing.T) {
+	store := &recordingPurgeStore{}
+	p := NewArchivePurger(store, 7*24*time.Hour)
+
+	before := time.Now()
+	p.runPurge()
+	after := time.Now()
+
+	if len(store.archivedBefore) != 1 {
+		t.Fatalf("PurgeArchivedSites called %d times, want 1", len(store.archivedBefore))
+	}
+	// Batas waktu harus tepat masa retensi sebelum saat purge dijalankan
+	cutoff := store.archivedBefore[0]
+	if cutoff.Before(before.Add(-7*24*time.Hour)) || cutoff.After(after.Add(-7*24*time.Hour)) {
+		t.Errorf("cutoff = %v, want 7 days before %v", cutoff, before)
+	}
+}
@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/metrics"
+)
+
+// SinkRecord adalah satu hasil pengecekan yang sudah tersimpan beserta site-nya.
+type SinkRecord struct {
+	Site  db.Site
+	Check db.HealthCheck
+}
+
+// ResultSink meneruskan hasil pengecekan ke sistem eksternal (mis. InfluxDB atau Graphite).
+// Write dipanggil dengan satu batch; jika mengembalikan error, batch yang sama akan dikirim ulang.
+type ResultSink interface {
+	Name() string
+	Write(ctx context.Context, records []SinkRecord) error
+}
+
+// SinkOptions mengatur batching dan buffer sebuah sink.
+type SinkOptions struct {
+	BatchSize     int
+	FlushInterval time.Duration
+	// MaxBuffered adalah jumlah maksimum hasil yang ditahan selama sink gagal; hasil terlama dibuang jika penuh
+	MaxBuffered int
+	// MaxBackoff adalah jeda terlama di antara percobaan ulang
+	MaxBackoff time.Duration
+}
+
+// DefaultSinkOptions dipakai oleh Checker.AddSink.
+var DefaultSinkOptions = SinkOptions{
+	BatchSize:     500,
+	FlushInterval: 5 * time.Second,
+	MaxBuffered:   10000,
+	MaxBackoff:    time.Minute,
+}
+
+// sinkForwarder menampung hasil untuk satu sink dalam buffer terbatas dan mengirimkannya per batch
+// dari goroutine sendiri, sehingga sink yang lambat atau mati tidak pernah menahan checker.
+type sinkForwarder struct {
+	sink ResultSink
+	opts SinkOptions
+
+	mu     sync.Mutex
+	buffer []SinkRecord
+	// ready diberi sinyal jika buffer sudah berisi setidaknya satu batch penuh
+	ready chan struct{}
+}
+
+func newSinkForwarder(sink ResultSink, opts SinkOptions) *sinkForwarder {
+	return &sinkForwarder{
+		sink:  sink,
+		opts:  opts,
+		ready: make(chan struct{}, 1),
+	}
+}
+
+// Enqueue menambahkan hasil ke buffer tanpa pernah menunggu.
+func (f *sinkForwarder) Enqueue(records []SinkRecord) {
+	f.mu.Lock()
+	f.buffer = append(f.buffer, records...)
+	f.trimLocked()
+	full := len(f.buffer) >= f.opts.BatchSize
+	f.mu.Unlock()
+
+	if full {
+		select {
+		case f.ready <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// trimLocked membuang hasil terlama jika buffer melebihi MaxBuffered.
+func (f *sinkForwarder) trimLocked() {
+	if over := len(f.buffer) - f.opts.MaxBuffered; over > 0 {
+		f.buffer = slices.Delete(f.buffer, 0, over)
+		metrics.SinkDropped.WithLabelValues(f.sink.Name()).Add(float64(over))
+	}
+}
+
+// next mengambil paling banyak satu batch dari depan buffer.
+func (f *sinkForwarder) next() []SinkRecord {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := min(len(f.buffer), f.opts.BatchSize)
+	batch := slices.Clone(f.buffer[:n])
+	f.buffer = slices.Delete(f.buffer, 0, n)
+	return batch
+}
+
+// requeue mengembalikan batch yang gagal ke depan buffer agar urutannya tetap.
+func (f *sinkForwarder) requeue(batch []SinkRecord) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.buffer = append(batch, f.buffer...)
+	f.trimLocked()
+}
+
+// Run mengirim batch setiap FlushInterval atau segera setelah satu batch penuh terkumpul.
+// Setelah gagal, batch yang sama dikirim ulang setelah jeda yang berlipat hingga MaxBackoff.
+func (f *sinkForwarder) Run() {
+	ticker := time.NewTicker(f.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var backoff time.Duration
+	for {
+		select {
+		case <-ticker.C:
+		case <-f.ready:
+		}
+
+		for {
+			batch := f.next()
+			if len(batch) == 0 {
+				break
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err := f.sink.Write(ctx, batch)
+			cancel()
+			if err != nil {
+				f.requeue(batch)
+				metrics.SinkWriteErrors.WithLabelValues(f.sink.Name()).Inc()
+				backoff = min(max(2*backoff, time.Second), f.opts.MaxBackoff)
+				log.Printf("Error writing %d results to %s sink, retrying in %s: %v", len(batch), f.sink.Name(), backoff, err)
+				time.Sleep(backoff)
+				continue
+			}
+			backoff = 0
+		}
+	}
+}
+
+// SinksFromEnv membuat sink yang dikonfigurasi lewat environment variable:
+// INFLUXDB_WRITE_URL (dan INFLUXDB_TOKEN opsional) untuk InfluxDB, GRAPHITE_ADDR (dan GRAPHITE_PREFIX opsional) untuk Graphite.
+func SinksFromEnv() []ResultSink {
+	sinks := []ResultSink{}
+	if writeURL := os.Getenv("INFLUXDB_WRITE_URL"); writeURL != "" {
+		sinks = append(sinks, NewInfluxSink(writeURL, os.Getenv("INFLUXDB_TOKEN")))
+	}
+	if addr := os.Getenv("GRAPHITE_ADDR"); addr != "" {
+		sinks = append(sinks, NewGraphiteSink(addr, os.Getenv("GRAPHITE_PREFIX")))
+	}
+	return sinks
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sync"
+)
+
+// GraphiteSink mengirim hasil pengecekan dalam format plaintext Graphite lewat TCP.
+// Setiap hasil menjadi tiga metrik: <prefix>.site_<id>.up, .status_code dan .response_time_ms.
+type GraphiteSink struct {
+	addr   string
+	prefix string
+
+	// conn dipakai ulang di antara batch dan dibuka kembali setelah terjadi error
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewGraphiteSink membuat GraphiteSink baru untuk addr (host:port); prefix default "gopulse".
+func NewGraphiteSink(addr, prefix string) *GraphiteSink {
+	if prefix == "" {
+		prefix = "gopulse"
+	}
+	return &GraphiteSink{addr: addr, prefix: prefix}
+}
+
+func (s *GraphiteSink) Name() string { return "graphite" }
+
+// Write menulis satu batch ke koneksi TCP. Timestamp ditulis dalam detik Unix.
+func (s *GraphiteSink) Write(ctx context.Context, records []SinkRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", s.addr)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+	}
+
+	w := bufio.NewWriter(s.conn)
+	for _, r := range records {
+		check := r.Check
+		up := 0
+		if check.IsUp {
+			up = 1
+		}
+		ts := check.CheckedAt.Unix()
+		path := fmt.Sprintf("%s.site_%d", s.prefix, check.SiteID)
+		fmt.Fprintf(w, "%s.up %d %d\n", path, up, ts)
+		fmt.Fprintf(w, "%s.status_code %d %d\n", path, check.StatusCode, ts)
+		fmt.Fprintf(w, "%s.response_time_ms %d %d\n", path, check.ResponseTimeMs, ts)
+	}
+	if err := w.Flush(); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Nama measurement InfluxDB untuk hasil pengecekan
+const influxMeasurement = "gopulse_check"
+
+// InfluxSink mengirim hasil pengecekan sebagai InfluxDB line protocol lewat HTTP.
+type InfluxSink struct {
+	// writeURL adalah endpoint write lengkap, mis. http://localhost:8086/api/v2/write?org=o&bucket=b&precision=ns
+	// (InfluxDB 2.x) atau http://localhost:8086/write?db=gopulse (InfluxDB 1.x)
+	writeURL string
+	token    string
+	client   *http.Client
+}
+
+// NewInfluxSink membuat InfluxSink baru; token boleh kosong jika server tidak memakai autentikasi.
+func NewInfluxSink(writeURL, token string) *InfluxSink {
+	return &InfluxSink{
+		writeURL: writeURL,
+		token:    token,
+		client:   &http.Client{},
+	}
+}
+
+func (s *InfluxSink) Name() string { return "influxdb" }
+
+// Write mengirim satu batch dalam satu request. Timestamp ditulis dalam nanodetik.
+func (s *InfluxSink) Write(ctx context.Context, records []SinkRecord) error {
+	var body bytes.Buffer
+	for _, r := range records {
+		writeInfluxLine(&body, r)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.writeURL, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Token "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// Karakter yang harus di-escape pada nilai tag line protocol
+var influxTagEscaper = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+
+func writeInfluxLine(w *bytes.Buffer, r SinkRecord) {
+	check := r.Check
+	up := 0
+	if check.IsUp {
+		up = 1
+	}
+
+	w.WriteString(influxMeasurement)
+	w.WriteString(",site_id=")
+	w.WriteString(strconv.FormatInt(check.SiteID, 10))
+	w.WriteString(",url=")
+	w.WriteString(influxTagEscaper.Replace(r.Site.URL))
+	fmt.Fprintf(w, " up=%di,status_code=%di,response_time_ms=%di %d\n",
+		up, check.StatusCode, check.ResponseTimeMs, check.CheckedAt.UnixNano())
+}
@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+)
+
+func testSinkRecords() []SinkRecord {
+	checkedAt := time.Unix(1700000000, 500)
+	return []SinkRecord{
+		{
+			Site:  db.Site{ID: 1, URL: "https://example.com/a b,c=d"},
+			Check: db.HealthCheck{SiteID: 1, StatusCode: 200, ResponseTimeMs: 42, IsUp: true, CheckedAt: checkedAt},
+		},
+		{
+			Site:  db.Site{ID: 2, URL: "https://down.example.com"},
+			Check: db.HealthCheck{SiteID: 2, StatusCode: 503, ResponseTimeMs: 7, IsUp: false, CheckedAt: checkedAt},
+		},
+	}
+}
+
+func TestInfluxSinkWritesLineProtocol(t *testing.T) {
+	var (
+		gotBody string
+		gotAuth string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	sink := NewInfluxSink(srv.URL+"/api/v2/write?org=o&bucket=b", "secret")
+	if err := sink.Write(context.Background(), testSinkRecords()); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := `gopulse_check,site_id=1,url=https://example.com/a\ b\,c\=d up=1i,status_code=200i,response_time_ms=42i 1700000000000000500` + "\n" +
+		`gopulse_check,site_id=2,url=https://down.example.com up=0i,status_code=503i,response_time_ms=7i 1700000000000000500` + "\n"
+	if gotBody != want {
+		t.Errorf("body =\n%s\nwant\n%s", gotBody, want)
+	}
+	if gotAuth != "Token secret" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Token secret")
+	}
+}
+
+func TestInfluxSinkReturnsErrorOnRejectedWrite(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bucket not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := NewInfluxSink(srv.URL, "").Write(context.Background(), testSinkRecords())
+	if err == nil || !strings.Contains(err.Error(), "bucket not found") {
+		t.Fatalf("Write error = %v, want error with server message", err)
+	}
+}
+
+// graphiteListener menerima satu koneksi TCP dan meneruskan setiap baris yang diterima ke channel.
+func graphiteListener(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	lines := make(chan string, 100)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return ln.Addr().String(), lines
+}
+
+func readLines(t *testing.T, lines <-chan string, n int) []string {
+	t.Helper()
+	got := make([]string, 0, n)
+	for range n {
+		select {
+		case line := <-lines:
+			got = append(got, line)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d lines, want %d: %v", len(got), n, got)
+		}
+	}
+	return got
+}
+
+func TestGraphiteSinkWritesPlaintextOverOneConnection(t *testing.T) {
+	addr, lines := graphiteListener(t)
+	sink := NewGraphiteSink(addr, "")
+
+	records := testSinkRecords()
+	if err := sink.Write(context.Background(), records[:1]); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	// Batch kedua harus memakai koneksi yang sama; listener hanya menerima satu koneksi
+	if err := sink.Write(context.Background(), records[1:]); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	want := []string{
+		"gopulse.site_1.up 1 1700000000",
+		"gopulse.site_1.status_code 200 1700000000",
+		"gopulse.site_1.response_time_ms 42 1700000000",
+		"gopulse.site_2.up 0 1700000000",
+		"gopulse.site_2.status_code 503 1700000000",
+		"gopulse.site_2.response_time_ms 7 1700000000",
+	}
+	got := readLines(t, lines, len(want))
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGraphiteSinkReturnsErrorWhenUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if err := NewGraphiteSink(addr, "custom").Write(context.Background(), testSinkRecords()); err == nil {
+		t.Fatal("Write succeeded without a listener")
+	}
+}
+
+// flakySink gagal sebanyak failures kali pertama, lalu mencatat setiap batch yang diterima.
+type flakySink struct {
+	mu       sync.Mutex
+	failures int
+	written  []SinkRecord
+	done     chan struct{}
+	want     int
+}
+
+func (s *flakySink) Name() string { return "flaky" }
+
+func (s *flakySink) Write(ctx context.Context, records []SinkRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.written = append(s.written, records...)
+	if len(s.written) >= s.want {
+		close(s.done)
+	}
+	return nil
+}
+
+func TestSinkForwarderRetriesFailedBatchInOrder(t *testing.T) {
+	sink := &flakySink{failures: 1, done: make(chan struct{}), want: 2}
+	forwarder := newSinkForwarder(sink, SinkOptions{BatchSize: 2, FlushInterval: time.Hour, MaxBuffered: 10, MaxBackoff: time.Second})
+	go forwarder.Run()
+
+	records := testSinkRecords()
+	forwarder.Enqueue(records)
+
+	select {
+	case <-sink.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch was not retried after the sink recovered")
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.written) != 2 || sink.written[0].Check.SiteID != 1 || sink.written[1].Check.SiteID != 2 {
+		t.Errorf("written = %+v, want both records in order", sink.written)
+	}
+}
+
+func TestSinkForwarderDropsOldestWhenBufferIsFull(t *testing.T) {
+	forwarder := newSinkForwarder(&flakySink{}, SinkOptions{BatchSize: 10, FlushInterval: time.Hour, MaxBuffered: 3, MaxBackoff: time.Second})
+
+	for id := range int64(5) {
+		forwarder.Enqueue([]SinkRecord{{Check: db.HealthCheck{SiteID: id}}})
+	}
+
+	batch := forwarder.next()
+	if len(batch) != 3 || batch[0].Check.SiteID != 2 || batch[2].Check.SiteID != 4 {
+		t.Errorf("buffered = %+v, want the 3 newest records", batch)
+	}
+}
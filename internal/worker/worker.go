@@ -8,29 +8,53 @@ import (
 	"time"
 
 	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/metrics"
 	"github.com/tajri15/go-pulse-monitoring/internal/ws"
 )
 
 // Checker sekarang juga memegang referensi ke Hub
 type Checker struct {
-	store *db.Store
-	hub   *ws.Hub
+	store   db.Store
+	hub     *ws.Hub
+	batcher *resultBatcher
+	// URL dari site yang pernah diperiksa, untuk membersihkan metrik site yang sudah dihapus
+	knownSites map[int64]string
+	// Waktu pengecekan terakhir per site, untuk menghormati check_interval_seconds
+	lastChecked map[int64]time.Time
+	// Sink eksternal yang menerima setiap hasil yang sudah tersimpan
+	sinks []*sinkForwarder
 }
 
 // NewChecker diubah untuk menerima Hub
-func NewChecker(store *db.Store, hub *ws.Hub) *Checker {
-	return &Checker{
-		store: store,
-		hub:   hub,
+func NewChecker(store db.Store, hub *ws.Hub) *Checker {
+	c := &Checker{
+		store:       store,
+		hub:         hub,
+		knownSites:  make(map[int64]string),
+		lastChecked: make(map[int64]time.Time),
 	}
+	// Hasil pengecekan disimpan per batch (maks. 500 baris atau setiap 2 detik)
+	c.batcher = newResultBatcher(store, 500, 2*time.Second, c.publishResults)
+	return c
 }
 
-// Start tetap sama
+// AddSink mendaftarkan sink eksternal dan mulai mengirim hasil ke sink tersebut di background.
+// Harus dipanggil sebelum Start.
+func (c *Checker) AddSink(sink ResultSink, opts SinkOptions) {
+	f := newSinkForwarder(sink, opts)
+	c.sinks = append(c.sinks, f)
+	go f.Run()
+}
+
+// Interval siklus checker; site hanya diperiksa jika check_interval_seconds miliknya sudah lewat
+const checkTick = 10 * time.Second
+
+// Start menjalankan batcher hasil lalu siklus pengecekan setiap checkTick
 func (c *Checker) Start() {
 	log.Println("Starting health check worker...")
-	ticker := time.NewTicker(1 * time.Minute)
+	go c.batcher.Run()
+	ticker := time.NewTicker(checkTick)
 	for range ticker.C {
-		log.Println("Running health check cycle...")
 		c.runChecks()
 	}
 }
@@ -42,27 +66,40 @@ type WsUpdateMessage struct {
 	ResponseTimeMs int    `json:"response_time_ms"`
 	StatusCode     int    `json:"status_code"`
 	CheckedAt      time.Time `json:"checked_at"`
+	// Tags milik site agar dashboard dapat mengelompokkan pembaruan secara langsung
+	Tags []string `json:"tags"`
 }
 
 func (c *Checker) runChecks() {
+	startTime := time.Now()
+	defer func() { metrics.CheckCycleDuration.Observe(time.Since(startTime).Seconds()) }()
+
 	ctx := context.Background()
 	sites, err := c.store.GetAllSites(ctx)
 	if err != nil {
 		log.Printf("Error fetching sites: %v", err)
 		return
 	}
+	// Site yang dijeda tidak diperiksa dan metriknya dihapus
+	active := sites[:0]
+	for _, site := range sites {
+		if site.PausedAt == nil {
+			active = append(active, site)
+		}
+	}
+	c.forgetRemovedSites(active)
 
+	sites = c.dueSites(active, startTime)
 	if len(sites) == 0 {
-		log.Println("No sites to check.")
 		return
 	}
+	log.Printf("Running health check cycle for %d sites...", len(sites))
 
 	jobs := make(chan db.Site, len(sites))
-	results := make(chan db.HealthCheck, len(sites)) // Hasilnya adalah HealthCheck
+	results := make(chan checkResult, len(sites))
 
 	numWorkers := 5
 	for w := 1; w <= numWorkers; w++ {
-		// worker sekarang menghasilkan db.HealthCheck
 		go worker(w, jobs, results)
 	}
 
@@ -70,75 +107,105 @@ func (c *Checker) runChecks() {
 		jobs <- site
 	}
 	close(jobs)
+	metrics.WorkerQueueDepth.Set(float64(len(jobs)))
 
+	// Hasil diteruskan ke batcher; penyimpanan dan pembaruan WebSocket dilakukan saat flush
 	for a := 1; a <= len(sites); a++ {
-		result := <-results
-		
-		// 1. Simpan hasil ke database
-		savedCheck, err := c.store.CreateHealthCheck(ctx, db.CreateHealthCheckParams{
-			SiteID:         result.SiteID,
-			StatusCode:     result.StatusCode,
-			ResponseTimeMs: result.ResponseTimeMs,
-			IsUp:           result.IsUp,
-		})
-		if err != nil {
-			log.Printf("Error saving health check result for site ID %d: %v", result.SiteID, err)
+		c.batcher.Add(<-results)
+	}
+}
+
+// dueSites memilih site yang interval pengecekannya sudah lewat dan mencatat waktu pengecekannya
+func (c *Checker) dueSites(sites []db.Site, now time.Time) []db.Site {
+	due := []db.Site{}
+	for _, site := range sites {
+		interval := time.Duration(site.CheckIntervalSeconds) * time.Second
+		if last, ok := c.lastChecked[site.ID]; ok && now.Sub(last) < interval {
 			continue
 		}
-		
-		log.Printf("Successfully saved health check for site ID %d. Status UP: %t", result.SiteID, result.IsUp)
-
-		// 2. Kirim pembaruan melalui WebSocket
-		// Cari userID dari site yang sesuai
-		var targetUserID int64
-		for _, site := range sites {
-			if site.ID == result.SiteID {
-				targetUserID = site.UserID
-				break
-			}
+		c.lastChecked[site.ID] = now
+		due = append(due, site)
+	}
+	return due
+}
+
+// forgetRemovedSites menghapus metrik milik site yang tidak lagi diperiksa
+func (c *Checker) forgetRemovedSites(sites []db.Site) {
+	current := make(map[int64]string, len(sites))
+	for _, site := range sites {
+		current[site.ID] = site.URL
+	}
+	for id, url := range c.knownSites {
+		if currentURL, ok := current[id]; !ok || currentURL != url {
+			metrics.ForgetSite(id, url)
 		}
+		if _, ok := current[id]; !ok {
+			delete(c.lastChecked, id)
+		}
+	}
+	c.knownSites = current
+}
 
-		if targetUserID != 0 {
-			// Buat pesan JSON
-			updateMsg := WsUpdateMessage{
-				SiteID:         savedCheck.SiteID,
-				IsUp:           savedCheck.IsUp,
-				ResponseTimeMs: savedCheck.ResponseTimeMs,
-				StatusCode:     savedCheck.StatusCode,
-				CheckedAt:      savedCheck.CheckedAt,
+// publishResults mengirim pembaruan WebSocket untuk hasil yang sudah tersimpan ke semua anggota organisasi pemilik site
+func (c *Checker) publishResults(results []checkResult) {
+	// Anggota organisasi di-cache per batch agar tidak di-query ulang untuk setiap hasil
+	members := map[int64][]int64{}
+	for _, result := range results {
+		check := result.Check
+		updateMsg := WsUpdateMessage{
+			SiteID:         check.SiteID,
+			IsUp:           check.IsUp,
+			ResponseTimeMs: check.ResponseTimeMs,
+			StatusCode:     check.StatusCode,
+			CheckedAt:      check.CheckedAt,
+			Tags:           result.Site.Tags,
+		}
+		jsonMsg, _ := json.Marshal(updateMsg)
+
+		orgID := result.Site.OrganizationID
+		userIDs, ok := members[orgID]
+		if !ok {
+			list, err := c.store.ListOrganizationMembers(context.Background(), orgID)
+			if err != nil {
+				log.Printf("Failed to list members of organization %d: %v", orgID, err)
 			}
-			jsonMsg, _ := json.Marshal(updateMsg)
+			for _, m := range list {
+				userIDs = append(userIDs, m.UserID)
+			}
+			members[orgID] = userIDs
+		}
 
-			// Kirim ke Hub
-			c.hub.Send(targetUserID, jsonMsg)
+		// Kirim ke Hub
+		for _, userID := range userIDs {
+			c.hub.Send(userID, jsonMsg)
+		}
+	}
+
+	if len(c.sinks) > 0 {
+		records := make([]SinkRecord, len(results))
+		for i, result := range results {
+			records[i] = SinkRecord{Site: result.Site, Check: result.Check}
+		}
+		for _, f := range c.sinks {
+			f.Enqueue(records)
 		}
 	}
 }
 
-// worker diubah untuk mengembalikan struct db.HealthCheck
-func worker(id int, jobs <-chan db.Site, results chan<- db.HealthCheck) {
+// worker memeriksa setiap site dan mengirimkan hasilnya beserta waktu pengecekan
+func worker(id int, jobs <-chan db.Site, results chan<- checkResult) {
+	// Timeout diatur per site lewat context di probeSite
+	client := &http.Client{}
 	for site := range jobs {
+		metrics.WorkerQueueDepth.Set(float64(len(jobs)))
 		log.Printf("Worker %d started job for site %s", id, site.URL)
-		
-		startTime := time.Now()
-		client := http.Client{Timeout: 10 * time.Second}
-		resp, err := client.Get(site.URL)
-		duration := time.Since(startTime).Milliseconds()
-
-		result := db.HealthCheck{
-			SiteID:         site.ID,
-			ResponseTimeMs: int(duration),
-		}
 
+		result, err := probeSite(context.Background(), client, site)
 		if err != nil {
 			log.Printf("Worker %d failed to check site %s: %v", id, site.URL, err)
-			result.IsUp = false
-			result.StatusCode = 0
-		} else {
-			result.IsUp = resp.StatusCode >= 200 && resp.StatusCode < 300
-			result.StatusCode = resp.StatusCode
-			resp.Body.Close()
 		}
-		results <- result
+
+		metrics.ObserveCheck(site.ID, site.URL, result.IsUp, result.StatusCode, time.Duration(result.ResponseTimeMs)*time.Millisecond)
+		results <- checkResult{Site: site, Check: result}
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,131 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tajri15/go-pulse-monitoring/internal/db"
+	"github.com/tajri15/go-pulse-monitoring/internal/ws"
+)
+
+// runCycle menjalankan satu siklus pengecekan lalu menunggu semua hasilnya disimpan.
+func runCycle(c *Checker) {
+	c.runChecks()
+	close(c.batcher.in)
+	c.batcher.Run()
+	c.batcher.in = make(chan checkResult, c.batcher.maxSize)
+}
+
+// connectTestClient mendaftarkan client WebSocket palsu untuk user ke hub.
+func connectTestClient(t *testing.T, hub *ws.Hub, userID int64) *ws.Client {
+	t.Helper()
+	client := &ws.Client{Hub: hub, UserID: userID, Send: make(chan []byte, 16)}
+	hub.Register <- client
+	for hub.ClientCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	return client
+}
+
+func TestRunChecksSavesResultsAndNotifiesMembers(t *testing.T) {
+	upServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer upServer.Close()
+	downServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer downServer.Close()
+
+	store := db.NewMemoryStore()
+	up := createTestSiteWithURL(t, store, upServer.URL)
+	down := createTestSiteWithURL(t, store, downServer.URL)
+
+	hub := ws.NewHub()
+	go hub.Run()
+	client := connectTestClient(t, hub, up.UserID)
+
+	runCycle(NewChecker(store, hub))
+
+	latest, err := store.GetLatestHealthChecks(context.Background(), []int64{up.ID, down.ID})
+	if err != nil {
+		t.Fatalf("GetLatestHealthChecks: %v", err)
+	}
+	if hc := latest[up.ID]; !hc.IsUp || hc.StatusCode != http.StatusOK {
+		t.Errorf("up site check = %+v, want up with status 200", hc)
+	}
+	if hc := latest[down.ID]; hc.IsUp || hc.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("down site check = %+v, want down with status 503", hc)
+	}
+
+	// Hanya anggota organisasi pemilik site yang menerima pembaruan
+	select {
+	case msg := <-client.Send:
+		var update WsUpdateMessage
+		if err := json.Unmarshal(msg, &update); err != nil {
+			t.Fatalf("decode update: %v", err)
+		}
+		if update.SiteID != up.ID || !update.IsUp {
+			t.Errorf("update = %+v, want site %d up", update, up.ID)
+		}
+	default:
+		t.Fatal("member did not receive a WebSocket update")
+	}
+	if len(client.Send) != 0 {
+		t.Errorf("member received %d updates for sites of other organizations", len(client.Send))
+	}
+}
+
+func TestRunChecksRespectsIntervalAndPause(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	store := db.NewMemoryStore()
+	site := createTestSiteWithURL(t, store, server.URL)
+	paused := createTestSiteWithURL(t, store, server.URL+"/paused")
+	if _, err := store.PauseSite(context.Background(), paused.ID, paused.OrganizationID); err != nil {
+		t.Fatalf("PauseSite: %v", err)
+	}
+
+	hub := ws.NewHub()
+	go hub.Run()
+	checker := NewChecker(store, hub)
+
+	runCycle(checker)
+	if requests != 1 {
+		t.Fatalf("first cycle made %d requests, want 1 (paused site skipped)", requests)
+	}
+
+	// Site belum jatuh tempo sampai check_interval_seconds-nya lewat
+	runCycle(checker)
+	if requests != 1 {
+		t.Errorf("second cycle made %d requests, want none before the interval", requests-1)
+	}
+
+	checker.lastChecked[site.ID] = time.Now().Add(-time.Duration(site.CheckIntervalSeconds) * time.Second)
+	runCycle(checker)
+	if requests != 2 {
+		t.Errorf("cycle after the interval made %d requests, want 1", requests-1)
+	}
+}
+
+func TestProbeSiteTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	site := db.Site{ID: 1, URL: server.URL, TimeoutSeconds: 1}
+	result, err := probeSite(context.Background(), &http.Client{}, site)
+	if err == nil {
+		t.Fatal("probeSite returned no error for a site that never responds")
+	}
+	if result.IsUp || result.SiteID != site.ID {
+		t.Errorf("result = %+v, want site %d down", result, site.ID)
+	}
+}
@@ -1,14 +1,11 @@
 package ws
 
 import (
-	"errors"
 	"log"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
 )
 
@@ -23,8 +20,10 @@ var upgrader = websocket.Upgrader{
 type Client struct {
 	Hub    *Hub
 	UserID int64
-	Conn   *websocket.Conn
-	Send   chan []byte
+	// SessionID adalah session dari access token yang membuka koneksi
+	SessionID int64
+	Conn      *websocket.Conn
+	Send      chan []byte
 }
 
 // readPump membaca pesan dari koneksi WebSocket (untuk mendeteksi disconnect)
@@ -74,46 +73,20 @@ func (c *Client) writePump() {
 	}
 }
 
-
-// ServeWs menangani request WebSocket dari client.
-func ServeWs(hub *Hub, c *gin.Context) {
-	// 1. Ambil token dari query parameter
-	tokenString := c.Query("token")
-	if tokenString == "" {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "token is required"})
-		return
-	}
-	
-	// 2. Validasi token JWT
-	secretKey := os.Getenv("JWT_SECRET")
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secretKey), nil
-	})
-	
-	var userID int64
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userIDFloat := claims["sub"].(float64)
-		userID = int64(userIDFloat)
-	} else {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-		return
-	}
-
-	// 3. Upgrade koneksi ke WebSocket
+// ServeWs meng-upgrade request menjadi koneksi WebSocket untuk user dan session yang sudah diautentikasi
+// oleh pemanggil. Koneksi ditutup oleh Hub jika session tersebut dicabut.
+func ServeWs(hub *Hub, c *gin.Context, userID, sessionID int64) {
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
 
-	// 4. Buat client baru dan daftarkan ke Hub
-	client := &Client{Hub: hub, UserID: userID, Conn: conn, Send: make(chan []byte, 256)}
+	// Buat client baru dan daftarkan ke Hub
+	client := &Client{Hub: hub, UserID: userID, SessionID: sessionID, Conn: conn, Send: make(chan []byte, 256)}
 	client.Hub.Register <- client
 
 	// Jalankan read dan write pump sebagai goroutine
 	go client.writePump()
 	go client.readPump()
-}
\ No newline at end of file
+}
@@ -1,8 +1,13 @@
 package ws
 
-import "log"
+import (
+	"log"
+	"sync"
+)
 
 type Hub struct {
+	// mu melindungi clients karena Send dipanggil dari goroutine lain (worker)
+	mu         sync.RWMutex
 	clients    map[int64]*Client
 	Register   chan *Client
 	Unregister chan *Client
@@ -18,6 +23,9 @@ func NewHub() *Hub {
 
 // Send mengirimkan pesan ke user ID tertentu jika terhubung
 func (h *Hub) Send(userID int64, message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	// Cek apakah client untuk userID ini ada dan terhubung
 	if client, ok := h.clients[userID]; ok {
 		// Kirim pesan ke channel Send milik client tersebut
@@ -37,19 +45,56 @@ func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.Register:
+			h.mu.Lock()
 			if oldClient, ok := h.clients[client.UserID]; ok {
 				close(oldClient.Send)
 				delete(h.clients, client.UserID)
 			}
 			h.clients[client.UserID] = client
+			h.mu.Unlock()
 			log.Printf("Client registered for user ID: %d", client.UserID)
 
 		case client := <-h.Unregister:
-			if _, ok := h.clients[client.UserID]; ok {
+			h.mu.Lock()
+			// Pastikan client yang terdaftar adalah client yang sama (bukan koneksi pengganti)
+			if current, ok := h.clients[client.UserID]; ok && current == client {
 				delete(h.clients, client.UserID)
 				close(client.Send)
 				log.Printf("Client unregistered for user ID: %d", client.UserID)
 			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+// DisconnectSession menutup koneksi milik session yang sudah dicabut.
+func (h *Hub) DisconnectSession(sessionID int64) {
+	h.disconnect(func(c *Client) bool { return c.SessionID == sessionID })
+}
+
+// DisconnectUserSessions menutup koneksi semua session milik user kecuali exceptSessionID (0 berarti semua),
+// sama seperti db.Store.RevokeUserSessions.
+func (h *Hub) DisconnectUserSessions(userID int64, exceptSessionID int64) {
+	h.disconnect(func(c *Client) bool { return c.UserID == userID && c.SessionID != exceptSessionID })
+}
+
+// disconnect menutup channel Send client yang cocok; writePump lalu mengirim close frame dan menutup koneksinya.
+func (h *Hub) disconnect(match func(*Client) bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for userID, client := range h.clients {
+		if match(client) {
+			close(client.Send)
+			delete(h.clients, userID)
+			log.Printf("Client disconnected for user ID: %d, session revoked", userID)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// ClientCount mengembalikan jumlah client yang sedang terhubung
+func (h *Hub) ClientCount() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
@@ -0,0 +1,62 @@
+const API_BASE = 'http://localhost:8080/api';
+
+// Simpan token dari respons login atau refresh
+export const saveTokens = (data) => {
+  localStorage.setItem('jwt_token', data.token);
+  localStorage.setItem('refresh_token', data.refresh_token);
+};
+
+const clearTokens = () => {
+  localStorage.removeItem('jwt_token');
+  localStorage.removeItem('refresh_token');
+};
+
+// Refresh dijalankan sekali saja walaupun beberapa request gagal bersamaan,
+// karena refresh token lama tidak boleh dipakai dua kali
+let refreshing = null;
+
+const refreshTokens = () => {
+  if (!refreshing) {
+    refreshing = (async () => {
+      const refreshToken = localStorage.getItem('refresh_token');
+      if (!refreshToken) return false;
+      const response = await fetch(`${API_BASE}/auth/refresh`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      });
+      if (!response.ok) {
+        clearTokens();
+        return false;
+      }
+      saveTokens(await response.json());
+      return true;
+    })().finally(() => { refreshing = null; });
+  }
+  return refreshing;
+};
+
+// authFetch menambahkan access token dan memperbaruinya sekali jika sudah kedaluwarsa (401)
+export const authFetch = async (url, options = {}) => {
+  const send = () => fetch(url, {
+    ...options,
+    headers: { ...options.headers, 'Authorization': `Bearer ${localStorage.getItem('jwt_token')}` },
+  });
+  let response = await send();
+  if (response.status === 401 && await refreshTokens()) {
+    response = await send();
+  }
+  return response;
+};
+
+export const logout = async () => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  clearTokens();
+  if (refreshToken) {
+    await fetch(`${API_BASE}/auth/logout`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    }).catch(() => {});
+  }
+};